@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	vehiclerepositories "github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+	vehicleservices "github.com/hsrvms/autoparts/internal/modules/vehicles/services"
 	"github.com/hsrvms/autoparts/internal/server"
 	"github.com/hsrvms/autoparts/pkg/config"
 	"github.com/hsrvms/autoparts/pkg/db"
@@ -44,6 +51,170 @@ func main() {
 	}
 	defer database.Close()
 
+	// "server migrate ..." manages the schema instead of serving requests
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(database, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// "server seed-vehicles [file]" loads the vehicle catalog
+	if len(os.Args) > 1 && os.Args[1] == "seed-vehicles" {
+		if err := runSeedVehicles(database, os.Args[2:]); err != nil {
+			log.Fatalf("Seeding vehicles failed: %v", err)
+		}
+		return
+	}
+
+	// "server stage-submodels FILE..." queues scraped listings for review
+	if len(os.Args) > 1 && os.Args[1] == "stage-submodels" {
+		if err := runStageSubmodels(database, os.Args[2:]); err != nil {
+			log.Fatalf("Staging submodels failed: %v", err)
+		}
+		return
+	}
+
+	if cfg.Database.AutoMigrate {
+		if _, err := database.MigrateUp(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
 	srv := server.New(cfg, database)
 	srv.Start()
 }
+
+// runMigrate runs a migrate subcommand:
+//
+//	migrate up                 apply all pending migrations
+//	migrate down [steps]       revert the last migration, or the last steps
+//	migrate status             list migrations and when they were applied
+//	migrate baseline VERSION   mark migrations up to VERSION as applied
+func runMigrate(database *db.Database, args []string) error {
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := database.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Println("Database is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := database.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			log.Println("No migrations to revert")
+		}
+		return nil
+
+	case "status":
+		statuses, err := database.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", status.Migration.Version, status.Migration.Name, applied)
+		}
+		return nil
+
+	case "baseline":
+		if len(args) < 2 {
+			return fmt.Errorf("baseline needs the version the database is already at")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return database.BaselineMigrations(ctx, version)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q (use up, down, status or baseline)", command)
+	}
+}
+
+// runSeedVehicles loads makes, models and body styles from the vehicle
+// catalog, Vehicle.csv in the working directory unless another file is given
+func runSeedVehicles(database *db.Database, args []string) error {
+	path := "Vehicle.csv"
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	service := vehicleservices.NewVehicleService(vehiclerepositories.NewPostgresVehicleRepository(database))
+	result, err := service.SeedVehicles(context.Background(), file)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		if row.Status == vehiclemodels.SeedStatusConflicting {
+			fmt.Printf("row %d  %s %s %s: %s\n", row.Row, row.Make, row.Model, row.BodyStyle, row.Reason)
+		}
+	}
+	log.Printf("Vehicle catalog: %d inserted, %d skipped, %d conflicting (%d makes, %d models, %d body styles created)",
+		result.Inserted, result.Skipped, result.Conflicting,
+		result.MakesCreated, result.ModelsCreated, result.BodyStylesCreated)
+	return nil
+}
+
+// runStageSubmodels queues the engine variants of scraped listing fragments,
+// such as the files in helper/, for review in the app
+func runStageSubmodels(database *db.Database, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("stage-submodels needs the listing files to read")
+	}
+
+	var files []io.Reader
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	service := vehicleservices.NewVehicleService(vehiclerepositories.NewPostgresVehicleRepository(database))
+	result, err := service.StageSubmodelListings(context.Background(), files)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range result.Unresolved {
+		fmt.Printf("no make or model found for %s\n", link)
+	}
+	log.Printf("Submodel listings: %d staged, %d already staged, %d unresolved",
+		result.Staged, result.Updated, len(result.Unresolved))
+	return nil
+}
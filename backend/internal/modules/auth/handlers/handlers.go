@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AuthHandler struct {
+	service services.AuthService
+}
+
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		service: service,
+	}
+}
+
+// Login handles exchanging a username and password for a token pair
+func (h *AuthHandler) Login(c echo.Context) error {
+	req := new(authmodels.LoginRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	tokens, err := h.service.Login(ctx, req.Username, req.Password)
+	if err != nil {
+		switch err {
+		case services.ErrMissingCredentials:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrInvalidCredentials:
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh handles exchanging a refresh token for a new token pair
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	req := new(authmodels.RefreshRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	tokens, err := h.service.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidToken, services.ErrTokenExpired:
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles revoking a refresh token
+func (h *AuthHandler) Logout(c echo.Context) error {
+	req := new(authmodels.RefreshRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	err := h.service.Logout(ctx, req.RefreshToken)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidToken:
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Me handles retrieval of the authenticated user
+func (h *AuthHandler) Me(c echo.Context) error {
+	claims := authmiddleware.CurrentUser(c)
+	if claims == nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "not authenticated")
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.service.GetUser(ctx, claims.UserID)
+	if err != nil {
+		switch err {
+		case services.ErrUserNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
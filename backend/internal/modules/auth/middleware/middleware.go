@@ -0,0 +1,71 @@
+package authmiddleware
+
+import (
+	"net/http"
+	"strings"
+
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/internal/modules/auth/services"
+	"github.com/labstack/echo/v4"
+)
+
+// contextKey is where the claims of the authenticated user are stored
+const contextKey = "auth.claims"
+
+// RequireAuth rejects requests without a valid bearer access token and makes
+// the caller's claims available through CurrentUser
+func RequireAuth(service services.AuthService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+
+			claims, err := service.ParseAccessToken(token)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+
+			c.Set(contextKey, claims)
+			return next(c)
+		}
+	}
+}
+
+// CurrentUser returns the claims of the authenticated user, or nil when the
+// request did not pass through RequireAuth
+func CurrentUser(c echo.Context) *authmodels.Claims {
+	claims, _ := c.Get(contextKey).(*authmodels.Claims)
+	return claims
+}
+
+// RequirePermission rejects requests from users whose role does not grant
+// every one of the given permissions. It must run after RequireAuth.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, permission := range permissions {
+				if !HasPermission(c, permission) {
+					return Forbidden(permission)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// HasPermission reports whether the authenticated user holds a permission
+func HasPermission(c echo.Context, permission string) bool {
+	claims := CurrentUser(c)
+	return claims != nil && authmodels.HasPermission(claims.Role, permission)
+}
+
+// Forbidden returns the error sent when a permission is missing
+func Forbidden(permission string) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusForbidden, map[string]string{
+		"message":    "missing permission " + permission,
+		"permission": permission,
+	})
+}
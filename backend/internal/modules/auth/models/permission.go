@@ -0,0 +1,89 @@
+package authmodels
+
+// Permissions checked by the API. Each is granted through the role of the
+// signed-in user.
+const (
+	PermDashboardRead = "dashboard:read"
+
+	PermInventoryRead   = "inventory:read"
+	PermInventoryWrite  = "inventory:write"
+	PermInventoryDelete = "inventory:delete"
+	// PermInventoryCosts allows seeing and changing buy prices
+	PermInventoryCosts = "inventory:costs"
+
+	PermCatalogRead  = "catalog:read"
+	PermCatalogWrite = "catalog:write"
+	// PermCatalogSeed allows loading the vehicle catalog. No role grants
+	// it, so only admins have it.
+	PermCatalogSeed = "catalog:seed"
+
+	PermSuppliersRead  = "suppliers:read"
+	PermSuppliersWrite = "suppliers:write"
+
+	PermPurchasesRead    = "purchases:read"
+	PermPurchasesWrite   = "purchases:write"
+	PermPurchasesReceive = "purchases:receive"
+
+	PermSalesRead  = "sales:read"
+	PermSalesWrite = "sales:write"
+	PermSalesVoid  = "sales:void"
+
+	PermStockRead   = "stock:read"
+	PermStockAdjust = "stock:adjust"
+
+	// PermWarehousesWrite allows adding and changing shops and warehouses
+	PermWarehousesWrite = "warehouses:write"
+	// PermTransfersWrite allows moving stock between warehouses
+	PermTransfersWrite = "transfers:write"
+
+	// PermReportsRead allows reports on employee performance
+	PermReportsRead = "reports:read"
+)
+
+const (
+	RoleManager = "manager"
+	RoleCounter = "counter"
+)
+
+// RolePermissions lists what every role may do. Admins may do everything.
+var RolePermissions = map[string][]string{
+	RoleManager: {
+		PermDashboardRead,
+		PermInventoryRead, PermInventoryWrite, PermInventoryDelete, PermInventoryCosts,
+		PermCatalogRead, PermCatalogWrite,
+		PermSuppliersRead, PermSuppliersWrite,
+		PermPurchasesRead, PermPurchasesWrite, PermPurchasesReceive,
+		PermSalesRead, PermSalesWrite, PermSalesVoid,
+		PermStockRead, PermStockAdjust,
+		PermWarehousesWrite, PermTransfersWrite,
+		PermReportsRead,
+	},
+	RoleCounter: {
+		PermDashboardRead,
+		PermInventoryRead, PermInventoryWrite,
+		PermCatalogRead,
+		PermSuppliersRead,
+		PermSalesRead, PermSalesWrite,
+		PermStockRead, PermTransfersWrite,
+	},
+	RoleUser: {
+		PermDashboardRead,
+		PermInventoryRead,
+		PermCatalogRead,
+		PermSalesRead,
+		PermStockRead,
+	},
+}
+
+// HasPermission reports whether a role grants a permission
+func HasPermission(role, permission string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	for _, granted := range RolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresAuthRepository struct {
+	db *db.Database
+}
+
+func NewPostgresAuthRepository(database *db.Database) AuthRepository {
+	return &PostgresAuthRepository{
+		db: database,
+	}
+}
+
+const userSelectQuery = `
+        SELECT
+            user_id, username, email, password_hash,
+            full_name, COALESCE(role, 'user'), created_at, updated_at
+        FROM arac.users
+`
+
+func (r *PostgresAuthRepository) GetUserByID(ctx context.Context, id int) (*authmodels.User, error) {
+	return r.getUser(ctx, userSelectQuery+" WHERE user_id = $1", id)
+}
+
+// GetUserByLogin finds a user by username or, failing that, by email
+func (r *PostgresAuthRepository) GetUserByLogin(ctx context.Context, login string) (*authmodels.User, error) {
+	return r.getUser(ctx, userSelectQuery+`
+        WHERE username = $1 OR LOWER(email) = LOWER($1)
+        ORDER BY (username = $1) DESC
+        LIMIT 1
+    `, login)
+}
+
+func (r *PostgresAuthRepository) CreateUser(ctx context.Context, user *authmodels.User) (int, error) {
+	query := `
+        INSERT INTO arac.users (username, email, password_hash, full_name, role)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING user_id
+    `
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		user.Username,
+		user.Email,
+		user.PasswordHash,
+		user.FullName,
+		user.Role,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresAuthRepository) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM arac.users`).Scan(&count)
+	return count, err
+}
+
+func (r *PostgresAuthRepository) CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, `
+        INSERT INTO arac.refresh_tokens (user_id, token_hash, expires_at)
+        VALUES ($1, $2, $3)
+    `, userID, tokenHash, expiresAt)
+	return err
+}
+
+func (r *PostgresAuthRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*authmodels.RefreshToken, error) {
+	query := `
+        SELECT token_id, user_id, token_hash, expires_at, revoked_at, created_at
+        FROM arac.refresh_tokens
+        WHERE token_hash = $1
+    `
+
+	token := &authmodels.RefreshToken{}
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.TokenID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshToken marks a token as used. It reports false when the token
+// had already been revoked, so a token can only ever be exchanged once.
+func (r *PostgresAuthRepository) RevokeRefreshToken(ctx context.Context, tokenID int) (bool, error) {
+	result, err := r.db.Pool.Exec(ctx, `
+        UPDATE arac.refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+        WHERE token_id = $1 AND revoked_at IS NULL
+    `, tokenID)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *PostgresAuthRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	_, err := r.db.Pool.Exec(ctx, `
+        UPDATE arac.refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND revoked_at IS NULL
+    `, userID)
+	return err
+}
+
+func (r *PostgresAuthRepository) getUser(ctx context.Context, query string, args ...interface{}) (*authmodels.User, error) {
+	user := &authmodels.User{}
+	err := r.db.Pool.QueryRow(ctx, query, args...).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FullName,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
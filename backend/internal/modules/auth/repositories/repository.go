@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+)
+
+type AuthRepository interface {
+	// User operations
+	GetUserByID(ctx context.Context, id int) (*authmodels.User, error)
+	GetUserByLogin(ctx context.Context, login string) (*authmodels.User, error)
+	CreateUser(ctx context.Context, user *authmodels.User) (int, error)
+	CountUsers(ctx context.Context) (int, error)
+
+	// Refresh token operations
+	CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*authmodels.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenID int) (bool, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"log"
+
+	"github.com/hsrvms/autoparts/internal/modules/auth/handlers"
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	"github.com/hsrvms/autoparts/internal/modules/auth/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/auth/services"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRoutes registers the public auth routes and returns the middleware
+// that protects the rest of the API
+func RegisterRoutes(api *echo.Group, database *db.Database, cfg config.AuthConfig) echo.MiddlewareFunc {
+	// Initialize repository
+	repo := repositories.NewPostgresAuthRepository(database)
+
+	// Initialize service
+	service := services.NewAuthService(repo, cfg)
+	if err := service.EnsureAdmin(context.Background()); err != nil {
+		log.Printf("Warning: failed to create admin user: %v", err)
+	}
+
+	// Initialize handler
+	handler := handlers.NewAuthHandler(service)
+
+	requireAuth := authmiddleware.RequireAuth(service)
+
+	// Register routes
+	auth := api.Group("/auth")
+	auth.POST("/login", handler.Login)
+	auth.POST("/refresh", handler.Refresh)
+	auth.POST("/logout", handler.Logout)
+	auth.GET("/me", handler.Me, requireAuth)
+
+	return requireAuth
+}
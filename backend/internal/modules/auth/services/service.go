@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"time"
+
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/internal/modules/auth/repositories"
+	"github.com/hsrvms/autoparts/pkg/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenExpired       = errors.New("token has expired")
+	ErrMissingCredentials = errors.New("username and password are required")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrAdminNotConfigured = errors.New("AUTH_ADMIN_USERNAME and AUTH_ADMIN_PASSWORD must be set to create the first user")
+)
+
+type AuthService interface {
+	Login(ctx context.Context, username, password string) (*authmodels.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*authmodels.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	GetUser(ctx context.Context, id int) (*authmodels.User, error)
+
+	// ParseAccessToken validates an access token and returns its claims
+	ParseAccessToken(token string) (*authmodels.Claims, error)
+
+	// EnsureAdmin creates the configured admin account when no user exists yet
+	EnsureAdmin(ctx context.Context) error
+}
+
+type authService struct {
+	repo   repositories.AuthRepository
+	config config.AuthConfig
+	secret []byte
+}
+
+func NewAuthService(repo repositories.AuthRepository, cfg config.AuthConfig) AuthService {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		// Without a configured secret tokens only live as long as the process
+		log.Println("Warning: AUTH_JWT_SECRET is not set, using a random secret")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate JWT secret: %v", err)
+		}
+	}
+
+	return &authService{
+		repo:   repo,
+		config: cfg,
+		secret: secret,
+	}
+}
+
+func (s *authService) Login(ctx context.Context, username, password string) (*authmodels.TokenPair, error) {
+	if username == "" || password == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	user, err := s.repo.GetUserByLogin(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		// Compare against a dummy hash so unknown users take as long as wrong passwords
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// can be used once; presenting one that was already used revokes every
+// session of the user, as the token has most likely been stolen.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*authmodels.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidToken
+	}
+
+	stored, err := s.repo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidToken
+	}
+	if stored.RevokedAt != nil {
+		if err := s.repo.RevokeUserRefreshTokens(ctx, stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrTokenExpired
+	}
+
+	revoked, err := s.repo.RevokeRefreshToken(ctx, stored.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return ErrInvalidToken
+	}
+
+	stored, err := s.repo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return ErrInvalidToken
+	}
+
+	_, err = s.repo.RevokeRefreshToken(ctx, stored.TokenID)
+	return err
+}
+
+func (s *authService) GetUser(ctx context.Context, id int) (*authmodels.User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (s *authService) ParseAccessToken(token string) (*authmodels.Claims, error) {
+	return parseAccessToken(token, s.secret, time.Now())
+}
+
+func (s *authService) EnsureAdmin(ctx context.Context) error {
+	count, err := s.repo.CountUsers(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// There is no default password; nobody can sign in until one is set
+	if s.config.AdminUsername == "" || s.config.AdminPassword == "" {
+		return ErrAdminNotConfigured
+	}
+
+	hash, err := HashPassword(s.config.AdminPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.repo.CreateUser(ctx, &authmodels.User{
+		Username:     s.config.AdminUsername,
+		Email:        s.config.AdminUsername + "@localhost",
+		PasswordHash: hash,
+		Role:         authmodels.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created admin user %q", s.config.AdminUsername)
+	return nil
+}
+
+// HashPassword returns the bcrypt hash of a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Helper functions
+
+// dummyHash is compared against when a login names an unknown user
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+func (s *authService) issueTokens(ctx context.Context, user *authmodels.User) (*authmodels.TokenPair, error) {
+	now := time.Now()
+	claims := &authmodels.Claims{
+		UserID:    user.UserID,
+		Username:  user.Username,
+		Role:      user.Role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.config.AccessTokenTTL).Unix(),
+	}
+
+	accessToken, err := signAccessToken(claims, s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRefreshToken(ctx, user.UserID, refreshHash, now.Add(s.config.RefreshTokenTTL)); err != nil {
+		return nil, err
+	}
+
+	return &authmodels.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.config.AccessTokenTTL.Seconds()),
+		User:         user,
+	}, nil
+}
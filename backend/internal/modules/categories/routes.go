@@ -1,6 +1,8 @@
 package categories
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/categories/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/categories/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/categories/services"
@@ -13,12 +15,16 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	service := services.NewCategoryService(repo)
 	handler := handlers.NewCategoryHandler(service)
 
+	// Permissions
+	read := authmiddleware.RequirePermission(authmodels.PermCatalogRead)
+	write := authmiddleware.RequirePermission(authmodels.PermCatalogWrite)
+
 	categories := api.Group("/categories")
-	categories.GET("", handler.GetAllCategories)
-	categories.GET("/:id", handler.GetCategoryByID)
-	categories.GET("/:id/subcategories", handler.GetSubcategories)
-	categories.POST("", handler.CreateCategory)
-	categories.PUT("/:id", handler.UpdateCategory)
-	categories.DELETE("/:id", handler.DeleteCategory)
-	categories.GET("/tree", handler.GetCategoryTree)
+	categories.GET("", handler.GetAllCategories, read)
+	categories.GET("/:id", handler.GetCategoryByID, read)
+	categories.GET("/:id/subcategories", handler.GetSubcategories, read)
+	categories.POST("", handler.CreateCategory, write)
+	categories.PUT("/:id", handler.UpdateCategory, write)
+	categories.DELETE("/:id", handler.DeleteCategory, write)
+	categories.GET("/tree", handler.GetCategoryTree, read)
 }
@@ -21,11 +21,13 @@ func NewPostgresDashboardRepository(database *db.Database) DashboardRepository {
 func (r *PostgresDashboardRepository) GetStats(ctx context.Context) (*dashboardmodels.Stats, error) {
 	stats := &dashboardmodels.Stats{}
 
-	// Get low stock count
+	// Get low stock count. Stock left under superseded part numbers counts
+	// towards the part that replaced them.
 	err := r.db.Pool.QueryRow(ctx, `
         SELECT COUNT(*)
-        FROM items
-        WHERE current_stock <= minimum_stock AND is_active = true
+        FROM items i
+        JOIN rolled_up_stock rs ON rs.item_id = i.item_id
+        WHERE rs.stock <= i.minimum_stock AND i.is_active = true
     `).Scan(&stats.LowStockCount)
 	if err != nil {
 		return nil, err
@@ -76,10 +78,11 @@ func (r *PostgresDashboardRepository) GetRecentActivities(ctx context.Context, l
         UNION ALL
         (SELECT
             'purchase' as type,
-            CONCAT('Purchase: ', i.part_number, ' (', p.quantity, ' units)') as message,
-            p.created_at as timestamp
-        FROM purchases p
-        JOIN items i ON p.item_id = i.item_id)
+            CONCAT('Purchase: ', i.part_number, ' (', pl.quantity_received, ' units)') as message,
+            pl.updated_at as timestamp
+        FROM purchase_lines pl
+        JOIN items i ON pl.item_id = i.item_id
+        WHERE pl.quantity_received > 0)
         ORDER BY timestamp DESC
         LIMIT $1
     `
@@ -107,20 +110,23 @@ func (r *PostgresDashboardRepository) GetRecentActivities(ctx context.Context, l
 	return activities, rows.Err()
 }
 
+// GetLowStockItems lists the current parts lowest on stock, counting the
+// stock left under the part numbers they superseded
 func (r *PostgresDashboardRepository) GetLowStockItems(ctx context.Context, limit int) ([]*dashboardmodels.LowStockItem, error) {
 	query := `
         SELECT
             i.item_id,
             i.part_number,
             i.description,
-            i.current_stock,
+            rs.stock,
             i.minimum_stock,
             c.name as category
         FROM items i
+        JOIN rolled_up_stock rs ON rs.item_id = i.item_id
         LEFT JOIN categories c ON i.category_id = c.category_id
-        WHERE i.current_stock <= i.minimum_stock
+        WHERE rs.stock <= i.minimum_stock
             AND i.is_active = true
-        ORDER BY (i.current_stock::float / i.minimum_stock::float)
+        ORDER BY (rs.stock::float / i.minimum_stock::float)
         LIMIT $1
     `
 
@@ -1,6 +1,8 @@
 package dashboard
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/dashboard/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/dashboard/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/dashboard/services"
@@ -21,12 +23,15 @@ func RegisterRoutes(e *echo.Echo, api *echo.Group, database *db.Database) {
     // Main dashboard page route
     e.GET("/", handler.RenderDashboard)
 
+    // Permissions
+    read := authmiddleware.RequirePermission(authmodels.PermDashboardRead)
+
     // API routes for HTMX requests
-    api.GET("/stats", handler.GetStats)
-    api.GET("/stats/low-stock-count", handler.GetStats)
-    api.GET("/stats/today-sales", handler.GetStats)
-    api.GET("/stats/active-items", handler.GetStats)
-    api.GET("/stats/supplier-count", handler.GetStats)
-    api.GET("/activities/recent", handler.GetRecentActivities)
-    api.GET("/inventory/low-stock", handler.GetLowStockItems)
+    api.GET("/stats", handler.GetStats, read)
+    api.GET("/stats/low-stock-count", handler.GetStats, read)
+    api.GET("/stats/today-sales", handler.GetStats, read)
+    api.GET("/stats/active-items", handler.GetStats, read)
+    api.GET("/stats/supplier-count", handler.GetStats, read)
+    api.GET("/activities/recent", handler.GetRecentActivities, read)
+    api.GET("/inventory/low-stock", handler.GetLowStockItems, read)
 }
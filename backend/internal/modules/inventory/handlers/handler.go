@@ -1,12 +1,22 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	pkgbarcode "github.com/hsrvms/autoparts/pkg/barcode"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/labstack/echo/v4"
 )
 
@@ -15,70 +25,158 @@ type InventoryHandler struct {
 	barcodeService services.BarcodeService
 }
 
-func NewInventoryHandler(service services.InventoryService) *InventoryHandler {
+func NewInventoryHandler(service services.InventoryService, barcodeService services.BarcodeService) *InventoryHandler {
 	return &InventoryHandler{
 		service:        service,
-		barcodeService: services.NewBarcodeService(),
+		barcodeService: barcodeService,
 	}
 }
 
-// GetItems handles the retrieval of items with optional filtering
+// GetItems handles the retrieval of a page of items with optional filtering
 func (h *InventoryHandler) GetItems(c echo.Context) error {
-	filter := &inventorymodels.ItemFilter{}
+	page, err := pagination.FromRequest(c, services.ItemSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
-	// Parse query parameters
-	if categoryID := c.QueryParam("category_id"); categoryID != "" {
-		id, err := strconv.Atoi(categoryID)
-		if err == nil {
-			filter.CategoryID = &id
-		}
+	filter := parseItemFilter(c)
+
+	ctx := c.Request().Context()
+	items, err := h.service.GetItems(ctx, filter, page)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
-		id, err := strconv.Atoi(supplierID)
-		if err == nil {
-			filter.SupplierID = &id
-		}
+	hideCosts(c, items...)
+	return pagination.Respond(c, page, items)
+}
+
+// SearchItems handles ranked item search over part numbers, OEM codes,
+// descriptions, categories and vehicle names. The filters of GetItems
+// narrow the results.
+func (h *InventoryHandler) SearchItems(c echo.Context) error {
+	page, err := pagination.FromRequest(c, services.ItemSearchSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	if partNumber := c.QueryParam("part_number"); partNumber != "" {
-		filter.PartNumber = &partNumber
+
+	filter := parseItemFilter(c)
+	filter.SearchTerm = nil
+
+	ctx := c.Request().Context()
+	results, err := h.service.SearchItems(ctx, c.QueryParam("q"), filter, page)
+	if err != nil {
+		switch err {
+		case services.ErrEmptySearch:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
-	if barcode := c.QueryParam("barcode"); barcode != "" {
-		filter.Barcode = &barcode
+	for _, result := range results {
+		hideCosts(c, result.Item)
 	}
+	return pagination.Respond(c, page, results)
+}
 
-	if search := c.QueryParam("search"); search != "" {
-		filter.SearchTerm = &search
+// ImportItems handles creating and updating items in bulk from an uploaded
+// CSV or XLSX file. With dry_run=true the file is only checked.
+func (h *InventoryHandler) ImportItems(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
 	}
 
-	if lowStock := c.QueryParam("low_stock"); lowStock == "true" {
-		isLowStock := true
-		filter.LowStock = &isLowStock
+	options := &inventorymodels.ItemImportOptions{
+		Format:      strings.ToLower(c.FormValue("format")),
+		IgnoreStock: !authmiddleware.HasPermission(c, authmodels.PermStockAdjust),
+	}
+	if options.Format == "" {
+		options.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &options.Mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "mapping must be a JSON object of item fields to column names")
+		}
 	}
+	options.DryRun, _ = strconv.ParseBool(c.FormValue("dry_run"))
 
-	if isActive := c.QueryParam("is_active"); isActive != "" {
-		active := isActive == "true"
-		filter.IsActive = &active
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	defer src.Close()
 
 	ctx := c.Request().Context()
-	items, err := h.service.GetItems(ctx, filter)
+	result, err := h.service.ImportItems(ctx, src, options)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		switch {
+		case errors.Is(err, services.ErrImportFailed):
+			return c.JSON(http.StatusUnprocessableEntity, result)
+		case errors.Is(err, services.ErrInvalidFileFormat), errors.Is(err, services.ErrEmptyImportFile),
+			errors.Is(err, services.ErrUnknownImportField), errors.Is(err, services.ErrMissingColumn),
+			errors.Is(err, services.ErrNoPartNumberColumn):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
-	return c.JSON(http.StatusOK, items)
+	return c.JSON(http.StatusOK, result)
 }
 
-// GetLowStockItems handles the retrieval of items with low stock
+// ExportItems handles downloading the items matching a filter as a CSV or
+// XLSX file
+func (h *InventoryHandler) ExportItems(c echo.Context) error {
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = inventorymodels.FileFormatCSV
+	}
+
+	var contentType string
+	switch format {
+	case inventorymodels.FileFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case inventorymodels.FileFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, services.ErrInvalidFileFormat.Error())
+	}
+
+	filter := parseItemFilter(c)
+	includeCosts := authmiddleware.HasPermission(c, authmodels.PermInventoryCosts)
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, contentType)
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="items.%s"`, format))
+	response.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure here can only cut the file short
+	ctx := c.Request().Context()
+	return h.service.ExportItems(ctx, filter, format, includeCosts, response)
+}
+
+// GetLowStockItems handles the retrieval of items with low stock, over all
+// warehouses or in the one given
 func (h *InventoryHandler) GetLowStockItems(c echo.Context) error {
+	warehouseID, err := optionalInt(c, "warehouse_id")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	ctx := c.Request().Context()
-	items, err := h.service.GetLowStockItems(ctx)
+	items, err := h.service.GetLowStockItems(ctx, warehouseID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		switch err {
+		case services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
 	}
 
+	hideCosts(c, items...)
 	return c.JSON(http.StatusOK, items)
 }
 
@@ -98,6 +196,7 @@ func (h *InventoryHandler) GetItemByID(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	hideCosts(c, item)
 	return c.JSON(http.StatusOK, item)
 }
 
@@ -117,6 +216,28 @@ func (h *InventoryHandler) GetItemByBarcode(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusNotFound, "item not found")
 	}
 
+	hideCosts(c, item)
+	return c.JSON(http.StatusOK, item)
+}
+
+// GetItemByPartNumber handles the retrieval of a single item by part
+// number, following supersessions to the part that replaces it
+func (h *InventoryHandler) GetItemByPartNumber(c echo.Context) error {
+	partNumber := c.Param("partNumber")
+	if partNumber == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "part number is required")
+	}
+
+	ctx := c.Request().Context()
+	item, err := h.service.GetItemByPartNumber(ctx, partNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if item == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}
+
+	hideCosts(c, item)
 	return c.JSON(http.StatusOK, item)
 }
 
@@ -139,6 +260,7 @@ func (h *InventoryHandler) CreateItem(c echo.Context) error {
 	}
 
 	item.ItemID = id
+	hideCosts(c, item)
 	return c.JSON(http.StatusCreated, item)
 }
 
@@ -156,6 +278,22 @@ func (h *InventoryHandler) UpdateItem(c echo.Context) error {
 	item.ItemID = id
 
 	ctx := c.Request().Context()
+
+	// Keep the cost as it is for callers who may not change it. Stock is
+	// never changed here, only through adjustments and cycle counts.
+	if !authmiddleware.HasPermission(c, authmodels.PermInventoryCosts) {
+		existing, err := h.service.GetItemByID(ctx, id)
+		if err != nil {
+			switch err {
+			case services.ErrItemNotFound:
+				return echo.NewHTTPError(http.StatusNotFound, err.Error())
+			default:
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+		}
+		item.BuyPrice = existing.BuyPrice
+	}
+
 	err = h.service.UpdateItem(ctx, item)
 	if err != nil {
 		switch err {
@@ -168,6 +306,7 @@ func (h *InventoryHandler) UpdateItem(c echo.Context) error {
 		}
 	}
 
+	hideCosts(c, item)
 	return c.JSON(http.StatusOK, item)
 }
 
@@ -256,6 +395,75 @@ func (h *InventoryHandler) RemoveCompatibility(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// AssignCompatibilities handles making an item compatible with every
+// submodel of a make or model in a year range or with a fuel or engine
+func (h *InventoryHandler) AssignCompatibilities(c echo.Context) error {
+	return h.bulkCompatibilities(c, h.service.AssignCompatibilities)
+}
+
+// RemoveCompatibilities handles removing an item's compatibility with every
+// submodel of a make or model in a year range or with a fuel or engine
+func (h *InventoryHandler) RemoveCompatibilities(c echo.Context) error {
+	return h.bulkCompatibilities(c, h.service.RemoveCompatibilities)
+}
+
+// CopyCompatibilities handles giving an item the compatibilities of another
+func (h *InventoryHandler) CopyCompatibilities(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	request := new(inventorymodels.CopyCompatibilitiesRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.CopyCompatibilities(ctx, itemID, request)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound, services.ErrSourceItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidItemID, services.ErrCopyFromSelf:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// bulkCompatibilities binds a bulk compatibility request and applies it to
+// the item in the path
+func (h *InventoryHandler) bulkCompatibilities(c echo.Context, apply func(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, error)) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	request := new(inventorymodels.BulkCompatibilityRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	result, err := apply(ctx, itemID, request)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidItemID, services.ErrSelectorRequired, services.ErrInvalidYearRange:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
 // GetCompatibleItems handles retrieving all compatible items for a vehicle submodel
 func (h *InventoryHandler) GetCompatibleItems(c echo.Context) error {
 	submodelID, err := strconv.Atoi(c.Param("submodelId"))
@@ -269,9 +477,252 @@ func (h *InventoryHandler) GetCompatibleItems(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	hideCosts(c, items...)
+	return c.JSON(http.StatusOK, items)
+}
+
+// FindParts handles the part finder: the in-stock parts that fit a vehicle
+// picked by make, model, year and engine, grouped by category. Pass
+// in_stock=false to include parts that are out of stock.
+func (h *InventoryHandler) FindParts(c echo.Context) error {
+	filter := &inventorymodels.PartFinderFilter{InStock: c.QueryParam("in_stock") != "false"}
+
+	var err error
+	if filter.MakeID, err = optionalInt(c, "make_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if filter.ModelID, err = optionalInt(c, "model_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if filter.SubmodelID, err = optionalInt(c, "submodel_id"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if filter.Year, err = optionalInt(c, "year"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if engine := strings.TrimSpace(c.QueryParam("engine")); engine != "" {
+		filter.Engine = &engine
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.FindParts(ctx, filter)
+	if err != nil {
+		switch err {
+		case services.ErrVehicleRequired, services.ErrInvalidYear:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	hidePartFinderCosts(c, result.Categories)
+	return c.JSON(http.StatusOK, result)
+}
+
+// optionalInt reads an integer query parameter, returning nil when it is
+// not given
+func optionalInt(c echo.Context, name string) (*int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &n, nil
+}
+
+// SetSupersession handles marking an item as superseded by another part
+func (h *InventoryHandler) SetSupersession(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	supersession := new(inventorymodels.Supersession)
+	if err := c.Bind(supersession); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	item, err := h.service.SetSupersession(ctx, id, supersession)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound, services.ErrSuccessorNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidItemID, services.ErrSupersededBySelf:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSupersessionCycle:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	hideCosts(c, item)
+	return c.JSON(http.StatusOK, item)
+}
+
+// RemoveSupersession handles undoing the supersession of an item
+func (h *InventoryHandler) RemoveSupersession(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveSupersession(ctx, id); err != nil {
+		switch err {
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetCrossReferences handles the retrieval of the cross-references of an item
+func (h *InventoryHandler) GetCrossReferences(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	references, err := h.service.GetCrossReferences(ctx, itemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, references)
+}
+
+// AddCrossReference handles adding a number an item is also known by
+func (h *InventoryHandler) AddCrossReference(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	reference := new(inventorymodels.CrossReference)
+	if err := c.Bind(reference); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	reference.ItemID = itemID
+
+	ctx := c.Request().Context()
+	id, err := h.service.AddCrossReference(ctx, reference)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrCrossReferenceExists:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case services.ErrBrandRequired, services.ErrInvalidReferenceNumber, services.ErrInvalidReferenceType:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	reference.ReferenceID = id
+	return c.JSON(http.StatusCreated, reference)
+}
+
+// RemoveCrossReference handles removing a cross-reference from an item
+func (h *InventoryHandler) RemoveCrossReference(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	referenceID, err := strconv.Atoi(c.Param("referenceId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid cross-reference ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveCrossReference(ctx, itemID, referenceID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// FindCrossReference handles finding the items that can replace a part from
+// any number read off it. With in_stock=true only items in stock are listed.
+func (h *InventoryHandler) FindCrossReference(c echo.Context) error {
+	inStock, _ := strconv.ParseBool(c.QueryParam("in_stock"))
+
+	ctx := c.Request().Context()
+	items, err := h.service.FindInterchangeableItems(ctx, c.Param("number"), inStock)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidReferenceNumber:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	for _, item := range items {
+		hideCosts(c, item.Item)
+	}
 	return c.JSON(http.StatusOK, items)
 }
 
+// ImportCrossReferences handles adding cross-references in bulk from an
+// uploaded CSV or XLSX list. With dry_run=true the file is only checked.
+func (h *InventoryHandler) ImportCrossReferences(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	options := &inventorymodels.ItemImportOptions{Format: strings.ToLower(c.FormValue("format"))}
+	if options.Format == "" {
+		options.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &options.Mapping); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "mapping must be a JSON object of fields to column names")
+		}
+	}
+	options.DryRun, _ = strconv.ParseBool(c.FormValue("dry_run"))
+
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	defer src.Close()
+
+	ctx := c.Request().Context()
+	result, err := h.service.ImportCrossReferences(ctx, src, options)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImportFailed):
+			return c.JSON(http.StatusUnprocessableEntity, result)
+		case errors.Is(err, services.ErrInvalidFileFormat), errors.Is(err, services.ErrEmptyImportFile),
+			errors.Is(err, services.ErrUnknownImportField), errors.Is(err, services.ErrMissingColumn),
+			errors.Is(err, services.ErrNoPartNumberColumn):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetBarcodeImage draws the barcode of an item as a PNG or SVG image. The
+// query string may set the symbology (code128, ean13, qr, datamatrix), the
+// format (png, svg), width and height in pixels, dpi and text=true to print
+// the barcode under the symbol.
 func (h *InventoryHandler) GetBarcodeImage(c echo.Context) error {
 	barcode := c.Param("barcode")
 	if barcode == "" {
@@ -281,6 +732,11 @@ func (h *InventoryHandler) GetBarcodeImage(c echo.Context) error {
 		})
 	}
 
+	options, err := parseBarcodeImageOptions(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	fmt.Printf("Barkod isteği: %s\n", barcode)
 
 	// Önce barkodun veritabanında olup olmadığını kontrol et
@@ -300,20 +756,70 @@ func (h *InventoryHandler) GetBarcodeImage(c echo.Context) error {
 	}
 
 	fmt.Printf("Barkod bulundu, görüntü oluşturuluyor: %s\n", barcode)
-	imageBytes, err := h.barcodeService.GenerateBarcodeImage(barcode)
+	imageBytes, err := h.barcodeService.GenerateBarcodeImage(barcode, options)
 	if err != nil {
 		fmt.Printf("Barkod görüntüsü oluşturma hatası: %v\n", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("barkod görüntüsü oluşturulamadı: %v", err),
-		})
+		switch {
+		case errors.Is(err, services.ErrUnknownSymbology), errors.Is(err, services.ErrUnknownImageFormat),
+			errors.Is(err, services.ErrInvalidImageSize), errors.Is(err, services.ErrInvalidImageDPI),
+			errors.Is(err, services.ErrImageTooSmall), errors.Is(err, services.ErrCannotEncodeBarcode):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("barkod görüntüsü oluşturulamadı: %v", err),
+			})
+		}
 	}
 
 	fmt.Printf("Barkod görüntüsü başarıyla oluşturuldu: %s\n", barcode)
-	c.Response().Header().Set("Content-Type", "image/png")
 	c.Response().Header().Set("Cache-Control", "public, max-age=31536000")
-	return c.Blob(http.StatusOK, "image/png", imageBytes)
+	return c.Blob(http.StatusOK, options.ContentType(), imageBytes)
 }
 
+// parseBarcodeImageOptions reads how to draw a barcode image from the query
+// string
+func parseBarcodeImageOptions(c echo.Context) (*pkgbarcode.ImageOptions, error) {
+	options := &pkgbarcode.ImageOptions{
+		Symbology: strings.ToLower(c.QueryParam("symbology")),
+		Format:    strings.ToLower(c.QueryParam("format")),
+	}
+
+	width, err := optionalInt(c, "width")
+	if err != nil {
+		return nil, err
+	}
+	if width != nil {
+		options.Width = *width
+	}
+
+	height, err := optionalInt(c, "height")
+	if err != nil {
+		return nil, err
+	}
+	if height != nil {
+		options.Height = *height
+	}
+
+	dpi, err := optionalInt(c, "dpi")
+	if err != nil {
+		return nil, err
+	}
+	if dpi != nil {
+		options.DPI = *dpi
+	}
+
+	if text := c.QueryParam("text"); text != "" {
+		if options.Text, err = strconv.ParseBool(text); err != nil {
+			return nil, errors.New("invalid text")
+		}
+	}
+
+	return options, nil
+}
+
+// GenerateBarcode issues a barcode no item uses yet. The request may ask
+// for the internal or gs1 format; without one, GS1 barcodes are issued when
+// a company prefix is configured.
 func (h *InventoryHandler) GenerateBarcode(c echo.Context) error {
 	var req BarcodeRequest
 	if err := c.Bind(&req); err != nil {
@@ -322,11 +828,17 @@ func (h *InventoryHandler) GenerateBarcode(c echo.Context) error {
 		})
 	}
 
-	barcode, err := h.barcodeService.GenerateBarcode(req.MakeID, req.ModelID, req.SubmodelID, req.CategoryID, req.YearFrom, req.YearTo)
+	ctx := c.Request().Context()
+	barcode, err := h.service.GenerateBarcode(ctx, req.Format)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Barkod oluşturulurken bir hata oluştu",
-		})
+		switch err {
+		case services.ErrUnknownBarcodeFormat, services.ErrNoCompanyPrefix:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Barkod oluşturulurken bir hata oluştu",
+			})
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -335,10 +847,232 @@ func (h *InventoryHandler) GenerateBarcode(c echo.Context) error {
 }
 
 type BarcodeRequest struct {
-	MakeID     int `json:"make_id"`
-	ModelID    int `json:"model_id"`
-	SubmodelID int `json:"submodel_id"`
-	CategoryID int `json:"category_id"`
-	YearFrom   int `json:"year_from"`
-	YearTo     int `json:"year_to"`
+	Format string `json:"format"`
+}
+
+// GetItemBarcodes handles the retrieval of all barcodes an item is scanned by
+func (h *InventoryHandler) GetItemBarcodes(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	barcodes, err := h.service.GetItemBarcodes(ctx, itemID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, barcodes)
+}
+
+// AddItemBarcode handles adding a manufacturer or supplier barcode to an
+// item, with the number of units one scan of it stands for
+func (h *InventoryHandler) AddItemBarcode(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	barcode := new(inventorymodels.ItemBarcode)
+	if err := c.Bind(barcode); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	barcode.ItemID = itemID
+
+	ctx := c.Request().Context()
+	id, err := h.service.AddItemBarcode(ctx, barcode)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrDuplicateBarcode:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		case services.ErrBarcodeRequired, services.ErrInvalidBarcodeType, services.ErrInvalidPackQuantity:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	barcode.BarcodeID = id
+	return c.JSON(http.StatusCreated, barcode)
+}
+
+// UpdateItemBarcode handles changing the type, pack quantity or notes of a
+// barcode of an item
+func (h *InventoryHandler) UpdateItemBarcode(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	barcodeID, err := strconv.Atoi(c.Param("barcodeId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid barcode ID")
+	}
+
+	barcode := new(inventorymodels.ItemBarcode)
+	if err := c.Bind(barcode); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	barcode.ItemID = itemID
+	barcode.BarcodeID = barcodeID
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateItemBarcode(ctx, barcode); err != nil {
+		switch err {
+		case services.ErrItemBarcodeNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidBarcodeType, services.ErrInvalidPackQuantity:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RemoveItemBarcode handles removing a barcode from an item
+func (h *InventoryHandler) RemoveItemBarcode(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	barcodeID, err := strconv.Atoi(c.Param("barcodeId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid barcode ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RemoveItemBarcode(ctx, itemID, barcodeID); err != nil {
+		switch err {
+		case services.ErrItemBarcodeNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrOwnBarcode:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PrintLabels handles rendering shelf labels for items as a PDF of label
+// sheets or as ZPL for a thermal printer
+func (h *InventoryHandler) PrintLabels(c echo.Context) error {
+	request := new(inventorymodels.LabelRequest)
+	if err := c.Bind(request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var output bytes.Buffer
+	ctx := c.Request().Context()
+	if err := h.service.PrintLabels(ctx, request, &output); err != nil {
+		switch {
+		case errors.Is(err, services.ErrItemNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrNoLabelItems), errors.Is(err, services.ErrInvalidLabelQuantity),
+			errors.Is(err, services.ErrTooManyLabels), errors.Is(err, services.ErrUnknownLabelTemplate),
+			errors.Is(err, services.ErrInvalidLabelFormat), errors.Is(err, services.ErrInvalidPrinterDPI),
+			errors.Is(err, services.ErrInvalidItemID), errors.Is(err, services.ErrItemHasNoBarcode),
+			errors.Is(err, services.ErrInvalidWarehouseID):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	contentType := "application/pdf"
+	if request.Format == inventorymodels.LabelFormatZPL {
+		contentType = "text/plain; charset=utf-8"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="labels.%s"`, request.Format))
+	return c.Blob(http.StatusOK, contentType, output.Bytes())
+}
+
+// parseItemFilter reads the item filter from the query string. Invalid
+// values are ignored.
+func parseItemFilter(c echo.Context) *inventorymodels.ItemFilter {
+	filter := &inventorymodels.ItemFilter{}
+
+	if categoryID := c.QueryParam("category_id"); categoryID != "" {
+		id, err := strconv.Atoi(categoryID)
+		if err == nil {
+			filter.CategoryID = &id
+		}
+	}
+
+	if supplierID := c.QueryParam("supplier_id"); supplierID != "" {
+		id, err := strconv.Atoi(supplierID)
+		if err == nil {
+			filter.SupplierID = &id
+		}
+	}
+	if partNumber := c.QueryParam("part_number"); partNumber != "" {
+		filter.PartNumber = &partNumber
+	}
+
+	if barcode := c.QueryParam("barcode"); barcode != "" {
+		filter.Barcode = &barcode
+	}
+
+	if search := c.QueryParam("search"); search != "" {
+		filter.SearchTerm = &search
+	}
+
+	if lowStock := c.QueryParam("low_stock"); lowStock == "true" {
+		isLowStock := true
+		filter.LowStock = &isLowStock
+	}
+
+	if makeID := c.QueryParam("make_id"); makeID != "" {
+		id, err := strconv.Atoi(makeID)
+		if err == nil {
+			filter.MakeID = &id
+		}
+	}
+
+	if modelID := c.QueryParam("model_id"); modelID != "" {
+		id, err := strconv.Atoi(modelID)
+		if err == nil {
+			filter.ModelID = &id
+		}
+	}
+
+	if submodelID := c.QueryParam("submodel_id"); submodelID != "" {
+		id, err := strconv.Atoi(submodelID)
+		if err == nil {
+			filter.SubmodelID = &id
+		}
+	}
+
+	if isActive := c.QueryParam("is_active"); isActive != "" {
+		active := isActive == "true"
+		filter.IsActive = &active
+	}
+
+	return filter
+}
+
+// hideCosts blanks out buy prices for users who may not see them
+func hideCosts(c echo.Context, items ...*inventorymodels.Item) {
+	if authmiddleware.HasPermission(c, authmodels.PermInventoryCosts) {
+		return
+	}
+	for _, item := range items {
+		item.BuyPrice = 0
+	}
+}
+
+// hidePartFinderCosts blanks out the buy prices of every item in a part
+// finder category tree
+func hidePartFinderCosts(c echo.Context, categories []*inventorymodels.PartFinderCategory) {
+	for _, category := range categories {
+		hideCosts(c, category.Items...)
+		hidePartFinderCosts(c, category.Subcategories)
+	}
 }
@@ -0,0 +1,28 @@
+package inventorymodels
+
+import "time"
+
+// Kinds of item barcodes
+const (
+	// BarcodeTypeInternal is a barcode we issued, such as the item's own
+	// label
+	BarcodeTypeInternal = "internal"
+	// BarcodeTypeManufacturer is the EAN a brand prints on its box
+	BarcodeTypeManufacturer = "manufacturer"
+	// BarcodeTypeSupplier is a code a supplier puts on what it delivers
+	BarcodeTypeSupplier = "supplier"
+)
+
+// ItemBarcode is a barcode an item can be scanned by. The item's own
+// barcode is always one of them.
+type ItemBarcode struct {
+	BarcodeID   int    `json:"barcode_id" db:"barcode_id"`
+	ItemID      int    `json:"item_id" db:"item_id"`
+	Barcode     string `json:"barcode" db:"barcode"`
+	BarcodeType string `json:"barcode_type" db:"barcode_type"`
+	// PackQuantity is how many units one scan of the barcode stands for
+	PackQuantity int       `json:"pack_quantity" db:"pack_quantity"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
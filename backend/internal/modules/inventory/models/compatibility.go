@@ -14,3 +14,44 @@ type Compatibility struct {
 	MakeName     string `json:"make_name,omitempty" db:"-"`
 	SubmodelName string `json:"submodel_name,omitempty" db:"-"`
 }
+
+// VehicleSelector picks submodels in bulk: every submodel of a make or
+// model, optionally narrowed to a year range, fuel type or engine
+type VehicleSelector struct {
+	MakeID   *int `json:"make_id,omitempty"`
+	ModelID  *int `json:"model_id,omitempty"`
+	YearFrom *int `json:"year_from,omitempty"`
+	YearTo   *int `json:"year_to,omitempty"`
+	// FuelType and EngineType are compared ignoring case, e.g. "dizel"
+	// matches "Dizel" and "1.6 tdi" matches "1.6 TDI"
+	FuelType   *string `json:"fuel_type,omitempty"`
+	EngineType *string `json:"engine_type,omitempty"`
+}
+
+// BulkCompatibilityRequest assigns or removes an item's compatibility with
+// every submodel a selector picks
+type BulkCompatibilityRequest struct {
+	VehicleSelector
+	// Notes are set on new compatibilities and replace the notes of
+	// existing ones when given
+	Notes  *string `json:"notes,omitempty"`
+	DryRun bool    `json:"dry_run"`
+}
+
+// CopyCompatibilitiesRequest copies the compatibilities of another item
+type CopyCompatibilitiesRequest struct {
+	SourceItemID int `json:"source_item_id"`
+	// Replace removes the compatibilities the source item does not have
+	Replace bool `json:"replace"`
+}
+
+// BulkCompatibilityResult reports what a bulk compatibility change did
+type BulkCompatibilityResult struct {
+	DryRun bool `json:"dry_run"`
+	// Matched counts the submodels the change applied to
+	Matched   int              `json:"matched"`
+	Added     int              `json:"added"`
+	Updated   int              `json:"updated"`
+	Removed   int              `json:"removed"`
+	Submodels []*Compatibility `json:"submodels"`
+}
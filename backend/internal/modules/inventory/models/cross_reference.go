@@ -0,0 +1,70 @@
+package inventorymodels
+
+import "time"
+
+// Kinds of cross-reference numbers
+const (
+	// ReferenceTypeOEM is the vehicle manufacturer's own number
+	ReferenceTypeOEM = "oem"
+	// ReferenceTypeOESupplier is the number of the company that supplies
+	// the vehicle manufacturer, such as Bosch for a VW part
+	ReferenceTypeOESupplier = "oe-supplier"
+	// ReferenceTypeAftermarket is an equivalent part of another brand
+	ReferenceTypeAftermarket = "aftermarket"
+)
+
+// How a cross-reference lookup found an item
+const (
+	// InterchangeMatchPartNumber: the number is the item's part number or OEM code
+	InterchangeMatchPartNumber = "part_number"
+	// InterchangeMatchReference: the number is one of the item's cross-references
+	InterchangeMatchReference = "reference"
+	// InterchangeMatchSharedOEM: the item has the same OEM number as an item
+	// the number was found on
+	InterchangeMatchSharedOEM = "shared_oem"
+)
+
+// CrossReference is another number an item is known by
+type CrossReference struct {
+	ReferenceID     int    `json:"reference_id" db:"reference_id"`
+	ItemID          int    `json:"item_id" db:"item_id"`
+	Brand           string `json:"brand" db:"brand"`
+	ReferenceNumber string `json:"reference_number" db:"reference_number"`
+	// NormalizedNumber is the number without spaces and dashes, in upper case
+	NormalizedNumber string    `json:"normalized_number" db:"normalized_number"`
+	ReferenceType    string    `json:"reference_type" db:"reference_type"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// InterchangeItem is an item that can replace the part a number was read
+// from, with how it was found
+type InterchangeItem struct {
+	*Item
+	MatchType string `json:"match_type"`
+	// Reference is the cross-reference that matched, if any
+	Reference *CrossReference `json:"reference,omitempty"`
+}
+
+// CrossReferenceImportRow is the outcome of importing one row of a
+// cross-reference list
+type CrossReferenceImportRow struct {
+	Row             int      `json:"row"`
+	PartNumber      string   `json:"part_number"`
+	Brand           string   `json:"brand"`
+	ReferenceNumber string   `json:"reference_number"`
+	Action          string   `json:"action"`
+	ItemID          int      `json:"item_id,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// CrossReferenceImportResult summarises a cross-reference import. Nothing is
+// written unless every row is valid.
+type CrossReferenceImportResult struct {
+	DryRun  bool                       `json:"dry_run"`
+	Created int                        `json:"created"`
+	Updated int                        `json:"updated"`
+	Failed  int                        `json:"failed"`
+	Rows    []*CrossReferenceImportRow `json:"rows"`
+}
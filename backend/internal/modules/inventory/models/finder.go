@@ -0,0 +1,32 @@
+package inventorymodels
+
+// PartFinderFilter picks a vehicle the way counter staff narrow it down:
+// make, model, year and engine
+type PartFinderFilter struct {
+	MakeID     *int
+	ModelID    *int
+	SubmodelID *int
+	Year       *int
+	// Engine is the engine type of a submodel, such as "1.4 TFSI"
+	Engine  *string
+	InStock bool
+}
+
+// PartFinderCategory is a category of the part finder's results with the
+// items directly in it and the subcategories that have any
+type PartFinderCategory struct {
+	CategoryID       int    `json:"category_id"`
+	Name             string `json:"name"`
+	ParentCategoryID *int   `json:"parent_category_id,omitempty"`
+	// ItemCount counts the items in the category and all categories below it
+	ItemCount     int                   `json:"item_count"`
+	Items         []*Item               `json:"items,omitempty"`
+	Subcategories []*PartFinderCategory `json:"subcategories,omitempty"`
+}
+
+// PartFinderResult is the parts that fit a vehicle grouped by the category
+// tree
+type PartFinderResult struct {
+	Total      int                   `json:"total"`
+	Categories []*PartFinderCategory `json:"categories"`
+}
@@ -0,0 +1,46 @@
+package inventorymodels
+
+// File formats items can be imported from and exported to
+const (
+	FileFormatCSV  = "csv"
+	FileFormatXLSX = "xlsx"
+)
+
+// What an import does with a row
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ItemImportOptions controls how an uploaded file is read
+type ItemImportOptions struct {
+	Format string
+	// Mapping maps item fields such as sell_price to the column headers
+	// they are read from. Fields that are not mapped are read from a
+	// column named after the field, if there is one.
+	Mapping map[string]string
+	DryRun  bool
+	// IgnoreStock skips the current_stock column for users who may not
+	// adjust stock
+	IgnoreStock bool
+}
+
+// ItemImportRow is the outcome of importing one row of the file
+type ItemImportRow struct {
+	Row        int      `json:"row"`
+	PartNumber string   `json:"part_number"`
+	Action     string   `json:"action"`
+	ItemID     int      `json:"item_id,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// ItemImportResult summarises an import. Nothing is written unless every
+// row is valid.
+type ItemImportResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Rows    []*ItemImportRow `json:"rows"`
+}
@@ -2,6 +2,10 @@ package inventorymodels
 
 import "time"
 
+// Item is an auto part. Its Location fields are deprecated: where an item
+// is kept is its bin, or the location of its stock, in each warehouse. They
+// follow the item's bin in the default warehouse and are only kept for older
+// clients.
 type Item struct {
 	ItemID           int       `json:"item_id" db:"item_id"`
 	PartNumber       string    `json:"part_number" db:"part_number"`
@@ -13,7 +17,7 @@ type Item struct {
 	YearFrom         *int      `json:"year_from,omitempty" db:"year_from"`
 	YearTo           *int      `json:"year_to,omitempty" db:"year_to"`
 	OEMCode          *string   `json:"oem_code,omitempty" db:"oem_code"`
-	BuyPrice         float64   `json:"buy_price" db:"buy_price"`
+	BuyPrice         float64   `json:"buy_price,omitempty" db:"buy_price"`
 	SellPrice        float64   `json:"sell_price" db:"sell_price"`
 	CurrentStock     int       `json:"current_stock" db:"current_stock"`
 	MinimumStock     int       `json:"minimum_stock" db:"minimum_stock"`
@@ -33,12 +37,44 @@ type Item struct {
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 
+	// SupersededBy is the item that replaces this one from SupersededOn
+	SupersededBy *int       `json:"superseded_by,omitempty" db:"superseded_by"`
+	SupersededOn *time.Time `json:"superseded_on,omitempty" db:"superseded_on"`
+
 	// Additional fields for API responses
 	CategoryName *string `json:"category_name,omitempty" db:"category_name"`
 	SupplierName *string `json:"supplier_name,omitempty" db:"supplier_name"`
 	MakeName     *string `json:"make_name,omitempty" db:"make_name"`
 	ModelName    *string `json:"model_name,omitempty" db:"model_name"`
 	SubmodelName *string `json:"submodel_name,omitempty" db:"submodel_name"`
+	// ResolvedFrom is the part number that was looked up when it led to
+	// this item through supersessions
+	ResolvedFrom *string `json:"resolved_from,omitempty" db:"-"`
+	// SupersededStock is the stock still held under the part numbers this
+	// item superseded
+	SupersededStock int `json:"superseded_stock,omitempty" db:"-"`
+	// ScannedBarcode is the barcode the item was looked up by, with the
+	// number of units it stands for
+	ScannedBarcode *ItemBarcode `json:"scanned_barcode,omitempty" db:"-"`
+	// Warehouse is the stock of the item in the warehouse it was listed for
+	Warehouse *WarehouseStockLevel `json:"warehouse,omitempty" db:"-"`
+}
+
+// WarehouseStockLevel is the stock of an item in one warehouse and the
+// minimum it is checked against there
+type WarehouseStockLevel struct {
+	WarehouseID   int    `json:"warehouse_id" db:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code" db:"warehouse_code"`
+	WarehouseName string `json:"warehouse_name" db:"warehouse_name"`
+	Quantity      int    `json:"quantity" db:"quantity"`
+	MinimumStock  int    `json:"minimum_stock" db:"minimum_stock"`
+}
+
+// Supersession marks an item as replaced by another from a date
+type Supersession struct {
+	SupersededBy int `json:"superseded_by"`
+	// SupersededOn defaults to today
+	SupersededOn *time.Time `json:"superseded_on,omitempty"`
 }
 
 type ItemFilter struct {
@@ -53,3 +89,10 @@ type ItemFilter struct {
 	SubmodelID *int    `query:"submodel_id"`
 	IsActive   *bool   `query:"is_active"`
 }
+
+// ItemSearchResult is an item found by a search with how well it matched.
+// Higher scores are better matches.
+type ItemSearchResult struct {
+	*Item
+	Score float64 `json:"score"`
+}
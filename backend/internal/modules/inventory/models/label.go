@@ -0,0 +1,27 @@
+package inventorymodels
+
+// Output formats of shelf labels
+const (
+	LabelFormatPDF = "pdf"
+	LabelFormatZPL = "zpl"
+)
+
+// LabelRequest asks for shelf labels for items
+type LabelRequest struct {
+	Items []*LabelItem `json:"items"`
+	// WarehouseID is the warehouse whose locations are printed, and
+	// defaults to the default warehouse
+	WarehouseID *int `json:"warehouse_id,omitempty"`
+	// Template is a4-3x8 or roll-58mm, and defaults to a4-3x8
+	Template string `json:"template"`
+	// Format is pdf or zpl, and defaults to pdf
+	Format string `json:"format"`
+	// DPI is the resolution of the printer ZPL is sent to, 203 by default
+	DPI int `json:"dpi,omitempty"`
+}
+
+// LabelItem is an item to print labels for and how many
+type LabelItem struct {
+	ItemID   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+}
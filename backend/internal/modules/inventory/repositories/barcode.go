@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// itemBarcodeColumns are the columns of arac.item_barcodes as b, in the
+// order itemBarcodeScanTargets expects
+const itemBarcodeColumns = `
+	b.barcode_id, b.item_id, b.barcode, b.barcode_type, b.pack_quantity,
+	b.notes, b.created_at, b.updated_at
+`
+
+// NextBarcodeNumber takes the next item number for a barcode from the
+// sequence the insert trigger also uses. A number is never returned twice,
+// even to concurrent requests.
+func (r *PostgresInventoryRepository) NextBarcodeNumber(ctx context.Context) (int64, error) {
+	var number int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT nextval('arac.item_barcode_seq')`).Scan(&number); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+func (r *PostgresInventoryRepository) GetItemBarcodes(ctx context.Context, itemID int) ([]*inventorymodels.ItemBarcode, error) {
+	query := `SELECT ` + itemBarcodeColumns + `
+		FROM arac.item_barcodes b
+		WHERE b.item_id = $1
+		ORDER BY b.barcode_type = 'internal' DESC, b.pack_quantity, b.barcode
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var barcodes []*inventorymodels.ItemBarcode
+	for rows.Next() {
+		barcode := &inventorymodels.ItemBarcode{}
+		if err := rows.Scan(itemBarcodeScanTargets(barcode)...); err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, barcode)
+	}
+
+	return barcodes, rows.Err()
+}
+
+func (r *PostgresInventoryRepository) GetItemBarcode(ctx context.Context, itemID, barcodeID int) (*inventorymodels.ItemBarcode, error) {
+	query := `SELECT ` + itemBarcodeColumns + `
+		FROM arac.item_barcodes b
+		WHERE b.item_id = $1 AND b.barcode_id = $2
+	`
+
+	barcode := &inventorymodels.ItemBarcode{}
+	if err := r.db.Pool.QueryRow(ctx, query, itemID, barcodeID).Scan(itemBarcodeScanTargets(barcode)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return barcode, nil
+}
+
+func (r *PostgresInventoryRepository) AddItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) (int, error) {
+	query := `
+		INSERT INTO arac.item_barcodes (item_id, barcode, barcode_type, pack_quantity, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING barcode_id
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		barcode.ItemID,
+		barcode.Barcode,
+		barcode.BarcodeType,
+		barcode.PackQuantity,
+		barcode.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateItemBarcode changes the type, pack quantity and notes of a barcode.
+// The barcode itself is not changed; a wrong one is removed and added again.
+func (r *PostgresInventoryRepository) UpdateItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) error {
+	query := `
+		UPDATE arac.item_barcodes
+		SET barcode_type = $3, pack_quantity = $4, notes = $5
+		WHERE item_id = $1 AND barcode_id = $2
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx, query,
+		barcode.ItemID,
+		barcode.BarcodeID,
+		barcode.BarcodeType,
+		barcode.PackQuantity,
+		barcode.Notes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("barcode not found")
+	}
+
+	return nil
+}
+
+func (r *PostgresInventoryRepository) RemoveItemBarcode(ctx context.Context, itemID, barcodeID int) error {
+	query := `DELETE FROM arac.item_barcodes WHERE item_id = $1 AND barcode_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, itemID, barcodeID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("barcode not found")
+	}
+
+	return nil
+}
+
+// itemBarcodeScanTargets returns the fields of barcode in the order of
+// itemBarcodeColumns
+func itemBarcodeScanTargets(barcode *inventorymodels.ItemBarcode) []interface{} {
+	return []interface{}{
+		&barcode.BarcodeID,
+		&barcode.ItemID,
+		&barcode.Barcode,
+		&barcode.BarcodeType,
+		&barcode.PackQuantity,
+		&barcode.Notes,
+		&barcode.CreatedAt,
+		&barcode.UpdatedAt,
+	}
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/search"
+)
+
+// FindSubmodels lists the submodels a selector picks, as compatibilities of
+// the item. Submodels whose years are not known (a year_from of 0) never
+// match a year range.
+func (r *PostgresInventoryRepository) FindSubmodels(ctx context.Context, itemID int, selector *inventorymodels.VehicleSelector) ([]*inventorymodels.Compatibility, error) {
+	query := `
+        SELECT s.submodel_id, m.model_name, mk.make_name, s.submodel_name
+        FROM submodels s
+        JOIN models m ON s.model_id = m.model_id
+        JOIN makes mk ON m.make_id = mk.make_id
+        WHERE 1=1
+    `
+	args := []interface{}{}
+	argPosition := 1
+
+	if selector.MakeID != nil {
+		query += fmt.Sprintf(" AND m.make_id = $%d", argPosition)
+		args = append(args, *selector.MakeID)
+		argPosition++
+	}
+
+	if selector.ModelID != nil {
+		query += fmt.Sprintf(" AND s.model_id = $%d", argPosition)
+		args = append(args, *selector.ModelID)
+		argPosition++
+	}
+
+	if selector.YearFrom != nil || selector.YearTo != nil {
+		query += " AND s.year_from > 0"
+	}
+
+	if selector.YearFrom != nil {
+		query += fmt.Sprintf(" AND (s.year_to IS NULL OR s.year_to >= $%d)", argPosition)
+		args = append(args, *selector.YearFrom)
+		argPosition++
+	}
+
+	if selector.YearTo != nil {
+		query += fmt.Sprintf(" AND s.year_from <= $%d", argPosition)
+		args = append(args, *selector.YearTo)
+		argPosition++
+	}
+
+	if selector.FuelType != nil {
+		query += fmt.Sprintf(" AND arac.search_fold(s.fuel_type) = $%d", argPosition)
+		args = append(args, search.Fold(*selector.FuelType))
+		argPosition++
+	}
+
+	if selector.EngineType != nil {
+		query += fmt.Sprintf(" AND arac.search_fold(s.engine_type) = $%d", argPosition)
+		args = append(args, search.Fold(*selector.EngineType))
+		argPosition++
+	}
+
+	query += " ORDER BY mk.make_name, m.model_name, s.submodel_name"
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submodels []*inventorymodels.Compatibility
+	for rows.Next() {
+		compatibility := &inventorymodels.Compatibility{ItemID: itemID}
+		err := rows.Scan(
+			&compatibility.SubmodelID,
+			&compatibility.ModelName,
+			&compatibility.MakeName,
+			&compatibility.SubmodelName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submodels = append(submodels, compatibility)
+	}
+
+	return submodels, rows.Err()
+}
+
+// UpsertCompatibilities makes an item compatible with the submodels in one
+// statement. Existing compatibilities are kept, with their notes replaced
+// when notes are given. It returns how many were added and updated.
+func (r *PostgresInventoryRepository) UpsertCompatibilities(ctx context.Context, itemID int, submodelIDs []int, notes *string) (added, updated int, err error) {
+	query := `
+        INSERT INTO compatibility (item_id, submodel_id, notes)
+        SELECT $1, submodel_id, $3 FROM unnest($2::integer[]) AS submodel_id
+        ON CONFLICT (item_id, submodel_id) DO UPDATE
+        SET notes = COALESCE(EXCLUDED.notes, compatibility.notes)
+        RETURNING xmax = 0
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID, submodelIDs, notes)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return 0, 0, err
+		}
+		if inserted {
+			added++
+		} else {
+			updated++
+		}
+	}
+
+	return added, updated, rows.Err()
+}
+
+// RemoveCompatibilities removes an item's compatibility with the submodels
+// and returns how many there were
+func (r *PostgresInventoryRepository) RemoveCompatibilities(ctx context.Context, itemID int, submodelIDs []int) (int, error) {
+	query := `DELETE FROM compatibility WHERE item_id = $1 AND submodel_id = ANY($2)`
+
+	result, err := r.db.Pool.Exec(ctx, query, itemID, submodelIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// CopyCompatibilities gives an item the compatibilities of another, with
+// their notes, in one transaction. With replace the item loses the
+// compatibilities the source does not have.
+func (r *PostgresInventoryRepository) CopyCompatibilities(ctx context.Context, sourceID, itemID int, replace bool) (*inventorymodels.BulkCompatibilityResult, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result := &inventorymodels.BulkCompatibilityResult{}
+
+	if replace {
+		removed, err := tx.Exec(ctx, `
+            DELETE FROM compatibility
+            WHERE item_id = $2
+                AND submodel_id NOT IN (SELECT submodel_id FROM compatibility WHERE item_id = $1)
+        `, sourceID, itemID)
+		if err != nil {
+			return nil, err
+		}
+		result.Removed = int(removed.RowsAffected())
+	}
+
+	rows, err := tx.Query(ctx, `
+        INSERT INTO compatibility (item_id, submodel_id, notes)
+        SELECT $2, submodel_id, notes FROM compatibility WHERE item_id = $1
+        ON CONFLICT (item_id, submodel_id) DO UPDATE
+        SET notes = COALESCE(EXCLUDED.notes, compatibility.notes)
+        RETURNING xmax = 0
+    `, sourceID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.Matched++
+		if inserted {
+			result.Added++
+		} else {
+			result.Updated++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, tx.Commit(ctx)
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/search"
+	"github.com/jackc/pgx/v5"
+)
+
+const crossReferenceColumns = `
+	reference_id, item_id, brand, reference_number, normalized_number,
+	reference_type, notes, created_at, updated_at
+`
+
+// interchangeQuery finds the active items a number belongs to: items with it
+// as part number or OEM code, items listing it as a cross-reference, and
+// items sharing an OEM number with any of those. $1 is the normalized number.
+const interchangeQuery = `
+    WITH direct AS (
+        SELECT i.item_id, 'part_number' AS match_type, NULL::integer AS reference_id
+        FROM arac.items i
+        WHERE arac.normalize_code(i.part_number) = $1 OR arac.normalize_code(i.oem_code) = $1
+        UNION ALL
+        SELECT r.item_id, 'reference', r.reference_id
+        FROM arac.item_cross_references r
+        WHERE r.normalized_number = $1
+    ),
+    oem_numbers AS (
+        SELECT r.normalized_number AS number
+        FROM direct d
+        JOIN arac.item_cross_references r ON r.item_id = d.item_id AND r.reference_type = 'oem'
+        UNION
+        SELECT arac.normalize_code(i.oem_code)
+        FROM direct d
+        JOIN arac.items i ON i.item_id = d.item_id
+        WHERE arac.normalize_code(i.oem_code) <> ''
+    ),
+    shared AS (
+        SELECT r.item_id, 'shared_oem' AS match_type, r.reference_id
+        FROM arac.item_cross_references r
+        JOIN oem_numbers o ON r.normalized_number = o.number
+        WHERE r.reference_type = 'oem'
+        UNION ALL
+        SELECT i.item_id, 'shared_oem', NULL::integer
+        FROM arac.items i
+        JOIN oem_numbers o ON arac.normalize_code(i.oem_code) = o.number
+    ),
+    matches AS (
+        SELECT DISTINCT ON (item_id) item_id, match_type, reference_id, priority
+        FROM (
+            SELECT direct.*, 1 AS priority FROM direct
+            UNION ALL
+            SELECT shared.*, 2 AS priority FROM shared
+        ) found
+        ORDER BY item_id, priority, reference_id NULLS FIRST
+    )
+    SELECT filtered.*, m.match_type,
+        r.reference_id, r.brand, r.reference_number, r.normalized_number, r.reference_type
+    FROM (` + itemSelectQuery + ` WHERE i.is_active = true) filtered
+    JOIN matches m ON m.item_id = filtered.item_id
+    LEFT JOIN arac.item_cross_references r ON r.reference_id = m.reference_id
+    WHERE $2 = false OR filtered.current_stock > 0
+    ORDER BY m.priority, filtered.current_stock > 0 DESC, filtered.part_number
+`
+
+func (r *PostgresInventoryRepository) GetCrossReferences(ctx context.Context, itemID int) ([]*inventorymodels.CrossReference, error) {
+	query := `SELECT ` + crossReferenceColumns + `
+		FROM arac.item_cross_references
+		WHERE item_id = $1
+		ORDER BY reference_type, brand, reference_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var references []*inventorymodels.CrossReference
+	for rows.Next() {
+		reference, err := scanCrossReference(rows)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, reference)
+	}
+
+	return references, rows.Err()
+}
+
+func (r *PostgresInventoryRepository) AddCrossReference(ctx context.Context, reference *inventorymodels.CrossReference) (int, error) {
+	query := `
+		INSERT INTO arac.item_cross_references (item_id, brand, reference_number, reference_type, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING reference_id, normalized_number
+	`
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		reference.ItemID,
+		reference.Brand,
+		reference.ReferenceNumber,
+		reference.ReferenceType,
+		reference.Notes,
+	).Scan(&id, &reference.NormalizedNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ImportCrossReferences adds the references, or updates the type and notes
+// of those the item already has, all in one transaction. A failing
+// reference is reported as a *BatchError.
+func (r *PostgresInventoryRepository) ImportCrossReferences(ctx context.Context, references []*inventorymodels.CrossReference) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO arac.item_cross_references (item_id, brand, reference_number, reference_type, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (item_id, arac.search_fold(brand), normalized_number) DO UPDATE
+		SET reference_type = EXCLUDED.reference_type,
+			notes = COALESCE(EXCLUDED.notes, arac.item_cross_references.notes)
+		RETURNING reference_id, normalized_number
+	`
+
+	for i, reference := range references {
+		err := tx.QueryRow(
+			ctx, query,
+			reference.ItemID,
+			reference.Brand,
+			reference.ReferenceNumber,
+			reference.ReferenceType,
+			reference.Notes,
+		).Scan(&reference.ReferenceID, &reference.NormalizedNumber)
+		if err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInventoryRepository) RemoveCrossReference(ctx context.Context, itemID, referenceID int) error {
+	query := `DELETE FROM arac.item_cross_references WHERE item_id = $1 AND reference_id = $2`
+
+	result, err := r.db.Pool.Exec(ctx, query, itemID, referenceID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("cross-reference not found")
+	}
+
+	return nil
+}
+
+// FindInterchangeableItems finds the active items that can replace the part
+// number was read from, best matches first. With inStock only items in
+// stock are returned.
+func (r *PostgresInventoryRepository) FindInterchangeableItems(ctx context.Context, number string, inStock bool) ([]*inventorymodels.InterchangeItem, error) {
+	rows, err := r.db.Pool.Query(ctx, interchangeQuery, search.NormalizeCode(number), inStock)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*inventorymodels.InterchangeItem
+	for rows.Next() {
+		item := &inventorymodels.InterchangeItem{Item: &inventorymodels.Item{}}
+		var referenceID *int
+		var brand, referenceNumber, normalizedNumber, referenceType *string
+
+		targets := append(itemScanTargets(item.Item), &item.MatchType,
+			&referenceID, &brand, &referenceNumber, &normalizedNumber, &referenceType)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+
+		if referenceID != nil {
+			item.Reference = &inventorymodels.CrossReference{
+				ReferenceID:      *referenceID,
+				ItemID:           item.ItemID,
+				Brand:            *brand,
+				ReferenceNumber:  *referenceNumber,
+				NormalizedNumber: *normalizedNumber,
+				ReferenceType:    *referenceType,
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func scanCrossReference(row pgx.Row) (*inventorymodels.CrossReference, error) {
+	reference := &inventorymodels.CrossReference{}
+	err := row.Scan(
+		&reference.ReferenceID,
+		&reference.ItemID,
+		&reference.Brand,
+		&reference.ReferenceNumber,
+		&reference.NormalizedNumber,
+		&reference.ReferenceType,
+		&reference.Notes,
+		&reference.CreatedAt,
+		&reference.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reference, nil
+}
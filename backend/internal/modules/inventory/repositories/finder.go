@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/search"
+)
+
+// FindParts lists the active items that fit the vehicle of a filter, either
+// through the vehicle on the item itself or through its compatibilities.
+// With a year, the item's own year range has to cover it as well.
+func (r *PostgresInventoryRepository) FindParts(ctx context.Context, filter *inventorymodels.PartFinderFilter) ([]*inventorymodels.Item, error) {
+	vehicles := "SELECT s.submodel_id FROM arac.submodels s JOIN arac.models mo ON s.model_id = mo.model_id WHERE 1=1"
+	args := []interface{}{}
+	argPosition := 1
+
+	if filter.MakeID != nil {
+		vehicles += fmt.Sprintf(" AND mo.make_id = $%d", argPosition)
+		args = append(args, *filter.MakeID)
+		argPosition++
+	}
+
+	if filter.ModelID != nil {
+		vehicles += fmt.Sprintf(" AND s.model_id = $%d", argPosition)
+		args = append(args, *filter.ModelID)
+		argPosition++
+	}
+
+	if filter.SubmodelID != nil {
+		vehicles += fmt.Sprintf(" AND s.submodel_id = $%d", argPosition)
+		args = append(args, *filter.SubmodelID)
+		argPosition++
+	}
+
+	// Submodels the catalog seeding left with a year_from of 0 have no known
+	// years, so they do not match one
+	if filter.Year != nil {
+		vehicles += fmt.Sprintf(" AND s.year_from > 0 AND s.year_from <= $%d AND (s.year_to IS NULL OR s.year_to >= $%d)", argPosition, argPosition)
+		args = append(args, *filter.Year)
+		argPosition++
+	}
+
+	if filter.Engine != nil {
+		vehicles += fmt.Sprintf(" AND arac.search_fold(s.engine_type) = $%d", argPosition)
+		args = append(args, search.Fold(*filter.Engine))
+		argPosition++
+	}
+
+	query := itemSelectQuery + fmt.Sprintf(` WHERE i.is_active = true
+		AND (i.submodel_id IN (%[1]s)
+			OR i.item_id IN (SELECT comp.item_id FROM arac.compatibility comp WHERE comp.submodel_id IN (%[1]s)))`, vehicles)
+
+	if filter.Year != nil {
+		query += fmt.Sprintf(" AND (i.year_from IS NULL OR i.year_from <= $%d) AND (i.year_to IS NULL OR i.year_to >= $%d)", argPosition, argPosition)
+		args = append(args, *filter.Year)
+	}
+
+	if filter.InStock {
+		query += " AND i.current_stock > 0"
+	}
+
+	query += " ORDER BY i.part_number"
+
+	return r.queryItems(ctx, query, args...)
+}
+
+// GetCategoryPaths returns the categories with the given IDs together with
+// every category above them
+func (r *PostgresInventoryRepository) GetCategoryPaths(ctx context.Context, categoryIDs []int) ([]*inventorymodels.PartFinderCategory, error) {
+	query := `
+		WITH RECURSIVE path AS (
+			SELECT category_id, name, parent_category_id
+			FROM arac.categories
+			WHERE category_id = ANY($1)
+			UNION
+			SELECT c.category_id, c.name, c.parent_category_id
+			FROM arac.categories c
+			JOIN path p ON c.category_id = p.parent_category_id
+		)
+		SELECT category_id, name, parent_category_id FROM path ORDER BY name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*inventorymodels.PartFinderCategory
+	for rows.Next() {
+		category := &inventorymodels.PartFinderCategory{}
+		if err := rows.Scan(&category.CategoryID, &category.Name, &category.ParentCategoryID); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
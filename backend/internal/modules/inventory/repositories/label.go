@@ -0,0 +1,40 @@
+package repositories
+
+import "context"
+
+// GetItemLocations returns where items are kept in a warehouse, or in the
+// default warehouse when warehouseID is nil, by item ID. The location is
+// the label of the item's bin, such as "1-A-03-2-04", or the free-text
+// location of its stock there when it is not in a bin. Items not stocked
+// in the warehouse are left out.
+func (r *PostgresInventoryRepository) GetItemLocations(ctx context.Context, warehouseID *int, itemIDs []int) (map[int]string, error) {
+	query := `
+		SELECT ws.item_id, COALESCE(p.label, concat_ws('-',
+			NULLIF(TRIM(ws.location_floor), ''), NULLIF(TRIM(ws.location_corridor), ''),
+			NULLIF(TRIM(ws.location_aisle), ''), NULLIF(TRIM(ws.location_shelf), ''),
+			NULLIF(TRIM(ws.location_bin), '')))
+		FROM arac.warehouse_stock ws
+		JOIN arac.warehouses w ON w.warehouse_id = ws.warehouse_id
+		LEFT JOIN arac.bin_location_paths p ON p.location_id = ws.bin_id
+		WHERE ws.item_id = ANY($1)
+			AND (w.warehouse_id = $2 OR ($2::INTEGER IS NULL AND w.is_default))
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, itemIDs, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := map[int]string{}
+	for rows.Next() {
+		var itemID int
+		var location string
+		if err := rows.Scan(&itemID, &location); err != nil {
+			return nil, err
+		}
+		locations[itemID] = location
+	}
+
+	return locations, rows.Err()
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/hsrvms/autoparts/pkg/search"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -20,8 +23,9 @@ func NewPostgresInventoryRepository(database *db.Database) InventoryRepository {
 	}
 }
 
-func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inventorymodels.ItemFilter) ([]*inventorymodels.Item, error) {
-	query := `
+// itemSelectQuery selects items with the names of their category,
+// supplier and vehicle
+const itemSelectQuery = `
     SELECT
         i.item_id,
         i.part_number,
@@ -52,6 +56,8 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
         i.model_id,
         i.submodel_id,
         i.oem_code,
+        i.superseded_by,
+        i.superseded_on,
         c.name as category_name,
         s.name as supplier_name,
         m.make_name,
@@ -63,8 +69,80 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
     LEFT JOIN arac.makes m ON i.make_id = m.make_id
     LEFT JOIN arac.models mo ON i.model_id = mo.model_id
     LEFT JOIN arac.submodels sm ON i.submodel_id = sm.submodel_id
-    WHERE 1=1
-    `
+`
+
+// itemFilterDocument is the text the search filter looks in, folded as
+// search.Fold folds the term
+const itemFilterDocument = `arac.search_fold(concat_ws(' ', i.part_number, i.oem_code, i.description, c.name,
+    i.barcode, s.name, m.make_name, mo.model_name, sm.submodel_name))`
+
+// ItemSorting lists the fields items can be sorted by
+var ItemSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":            {Column: "i.item_id", Type: "integer"},
+		"part_number":   {Column: "i.part_number", Type: "text"},
+		"description":   {Column: "COALESCE(i.description, '')", Type: "text"},
+		"category":      {Column: "COALESCE(c.name, '')", Type: "text"},
+		"sell_price":    {Column: "i.sell_price", Type: "numeric"},
+		"current_stock": {Column: "i.current_stock", Type: "integer"},
+		"created_at":    {Column: "i.created_at", Type: "timestamptz"},
+		"updated_at":    {Column: "i.updated_at", Type: "timestamptz"},
+	},
+	Default:  "part_number",
+	IDColumn: "i.item_id",
+}
+
+func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.Item, error) {
+	query, args := itemFilterQuery(filter)
+
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), args...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	query, args = ItemSorting.Apply(query, args, page)
+	items, err := r.queryItems(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if page != nil && len(items) > 0 {
+		last := items[len(items)-1]
+		page.SetNextCursor(len(items), itemSortValue(last, page.Sort), last.ItemID)
+	}
+
+	return items, nil
+}
+
+// ExportItems calls fn for every item matching the filter, reading them one
+// at a time so large catalogs are never held in memory
+func (r *PostgresInventoryRepository) ExportItems(ctx context.Context, filter *inventorymodels.ItemFilter, fn func(item *inventorymodels.Item) error) error {
+	query, args := itemFilterQuery(filter)
+	query, args = ItemSorting.Apply(query, args, nil)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// itemFilterQuery builds the item select with the conditions of filter
+func itemFilterQuery(filter *inventorymodels.ItemFilter) (string, []interface{}) {
+	query := itemSelectQuery + " WHERE 1=1"
 	args := []interface{}{}
 	argPosition := 1
 
@@ -88,171 +166,86 @@ func (r *PostgresInventoryRepository) GetItems(ctx context.Context, filter *inve
 		}
 
 		if filter.Barcode != nil {
-			query += fmt.Sprintf(" AND i.barcode ILIKE $%d", argPosition)
+			query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM arac.item_barcodes b WHERE b.item_id = i.item_id AND b.barcode ILIKE $%d)", argPosition)
 			args = append(args, "%"+*filter.Barcode+"%")
 			argPosition++
 		}
 
 		if filter.SearchTerm != nil {
-			query += fmt.Sprintf(" AND (i.part_number ILIKE $%d OR i.description ILIKE $%d OR c.name ILIKE $%d OR i.barcode ILIKE $%d OR s.name ILIKE $%d OR m.make_name ILIKE $%d OR mo.model_name ILIKE $%d OR sm.submodel_name ILIKE $%d)",
-				argPosition, argPosition, argPosition, argPosition, argPosition, argPosition, argPosition, argPosition)
-			searchTerm := "%" + *filter.SearchTerm + "%"
-			args = append(args, searchTerm)
-			argPosition++
+			query += fmt.Sprintf(" AND (%s LIKE $%d OR ($%d <> '' AND (arac.normalize_code(i.part_number) LIKE $%d OR arac.normalize_code(i.oem_code) LIKE $%d)))",
+				itemFilterDocument, argPosition, argPosition+1, argPosition+2, argPosition+2)
+			code := search.NormalizeCode(*filter.SearchTerm)
+			args = append(args, "%"+search.Fold(*filter.SearchTerm)+"%", code, "%"+code+"%")
+			argPosition += 3
 		}
 
 		if filter.LowStock != nil && *filter.LowStock {
-			query += " AND i.current_stock <= i.minimum_stock"
+			// Not correlated, so the rolled up stock is worked out once
+			query += ` AND i.item_id IN (
+				SELECT rs.item_id FROM arac.rolled_up_stock rs
+				JOIN arac.items li ON li.item_id = rs.item_id
+				WHERE rs.stock <= li.minimum_stock)`
 		}
 
-		if filter.IsActive != nil {
-			query += fmt.Sprintf(" AND i.is_active = $%d", argPosition)
-			args = append(args, *filter.IsActive)
+		// Vehicle filters match the vehicle on the item itself or any of
+		// its compatibilities
+		if filter.MakeID != nil {
+			query += fmt.Sprintf(` AND (i.make_id = $%d OR EXISTS (
+				SELECT 1 FROM arac.compatibility comp
+				JOIN arac.submodels cs ON comp.submodel_id = cs.submodel_id
+				JOIN arac.models cm ON cs.model_id = cm.model_id
+				WHERE comp.item_id = i.item_id AND cm.make_id = $%d))`, argPosition, argPosition)
+			args = append(args, *filter.MakeID)
 			argPosition++
 		}
-	}
 
-	query += " ORDER BY i.part_number"
+		if filter.ModelID != nil {
+			query += fmt.Sprintf(` AND (i.model_id = $%d OR EXISTS (
+				SELECT 1 FROM arac.compatibility comp
+				JOIN arac.submodels cs ON comp.submodel_id = cs.submodel_id
+				WHERE comp.item_id = i.item_id AND cs.model_id = $%d))`, argPosition, argPosition)
+			args = append(args, *filter.ModelID)
+			argPosition++
+		}
 
-	rows, err := r.db.Pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+		if filter.SubmodelID != nil {
+			query += fmt.Sprintf(` AND (i.submodel_id = $%d OR EXISTS (
+				SELECT 1 FROM arac.compatibility comp
+				WHERE comp.item_id = i.item_id AND comp.submodel_id = $%d))`, argPosition, argPosition)
+			args = append(args, *filter.SubmodelID)
+			argPosition++
+		}
 
-	var items []*inventorymodels.Item
-	for rows.Next() {
-		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID,
-			&item.PartNumber,
-			&item.Description,
-			&item.CategoryID,
-			&item.BuyPrice,
-			&item.SellPrice,
-			&item.CurrentStock,
-			&item.MinimumStock,
-			&item.Barcode,
-			&item.SupplierID,
-			&item.LocationFloor,
-			&item.LocationCorridor,
-			&item.LocationAisle,
-			&item.LocationShelf,
-			&item.LocationBin,
-			&item.WeightKg,
-			&item.DimensionsCm,
-			&item.WarrantyPeriod,
-			&item.ImageURL,
-			&item.IsActive,
-			&item.Notes,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-			&item.YearFrom,
-			&item.YearTo,
-			&item.MakeID,
-			&item.ModelID,
-			&item.SubmodelID,
-			&item.OEMCode,
-			&item.CategoryName,
-			&item.SupplierName,
-			&item.MakeName,
-			&item.ModelName,
-			&item.SubmodelName,
-		)
-		if err != nil {
-			return nil, err
+		if filter.IsActive != nil {
+			query += fmt.Sprintf(" AND i.is_active = $%d", argPosition)
+			args = append(args, *filter.IsActive)
+			argPosition++
 		}
-		items = append(items, item)
 	}
 
-	return items, rows.Err()
+	return query, args
 }
 
 func (r *PostgresInventoryRepository) GetItemByID(ctx context.Context, id int) (*inventorymodels.Item, error) {
-	query := `
-		SELECT
-            i.item_id,
-            i.part_number,
-            i.description,
-            i.category_id,
-            i.buy_price,
-            i.sell_price,
-            i.current_stock,
-            i.minimum_stock,
-            i.barcode,
-            i.supplier_id,
-            i.location_floor,
-            i.location_corridor,
-            i.location_aisle,
-            i.location_shelf,
-            i.location_bin,
-            i.weight_kg,
-            i.dimensions_cm,
-            i.warranty_period,
-            i.image_url,
-            i.is_active,
-            i.notes,
-            i.created_at,
-            i.updated_at,
-            i.year_from,
-            i.year_to,
-            i.make_id,
-            i.model_id,
-            i.submodel_id,
-            i.oem_code,
-            c.name as category_name,
-            s.name as supplier_name,
-            m.make_name,
-            mo.model_name,
-            sm.submodel_name
-        FROM arac.items i
-        LEFT JOIN arac.categories c ON i.category_id = c.category_id
-        LEFT JOIN arac.suppliers s ON i.supplier_id = s.supplier_id
-        LEFT JOIN arac.makes m ON i.make_id = m.make_id
-        LEFT JOIN arac.models mo ON i.model_id = mo.model_id
-        LEFT JOIN arac.submodels sm ON i.submodel_id = sm.submodel_id
-        WHERE i.item_id = $1
-	`
+	return r.queryItem(ctx, itemSelectQuery+" WHERE i.item_id = $1", id)
+}
 
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&item.ItemID,
-		&item.PartNumber,
-		&item.Description,
-		&item.CategoryID,
-		&item.BuyPrice,
-		&item.SellPrice,
-		&item.CurrentStock,
-		&item.MinimumStock,
-		&item.Barcode,
-		&item.SupplierID,
-		&item.LocationFloor,
-		&item.LocationCorridor,
-		&item.LocationAisle,
-		&item.LocationShelf,
-		&item.LocationBin,
-		&item.WeightKg,
-		&item.DimensionsCm,
-		&item.WarrantyPeriod,
-		&item.ImageURL,
-		&item.IsActive,
-		&item.Notes,
-		&item.CreatedAt,
-		&item.UpdatedAt,
-		&item.YearFrom,
-		&item.YearTo,
-		&item.MakeID,
-		&item.ModelID,
-		&item.SubmodelID,
-		&item.OEMCode,
-		&item.CategoryName,
-		&item.SupplierName,
-		&item.MakeName,
-		&item.ModelName,
-		&item.SubmodelName,
-	)
+func (r *PostgresInventoryRepository) GetItemByPartNumber(ctx context.Context, partNumber string) (*inventorymodels.Item, error) {
+	return r.queryItem(ctx, itemSelectQuery+" WHERE i.part_number = $1", partNumber)
+}
 
-	if err != nil {
+// GetItemByBarcode finds the item any of its barcodes belongs to, and sets
+// ScannedBarcode to that barcode
+func (r *PostgresInventoryRepository) GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error) {
+	query := `SELECT scanned.*, ` + itemBarcodeColumns + `
+		FROM (` + itemSelectQuery + `) scanned
+		JOIN arac.item_barcodes b ON b.item_id = scanned.item_id
+		WHERE b.barcode = $1
+	`
+
+	item := &inventorymodels.Item{ScannedBarcode: &inventorymodels.ItemBarcode{}}
+	targets := append(itemScanTargets(item), itemBarcodeScanTargets(item.ScannedBarcode)...)
+	if err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(targets...); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
@@ -262,65 +255,59 @@ func (r *PostgresInventoryRepository) GetItemByID(ctx context.Context, id int) (
 	return item, nil
 }
 
-func (r *PostgresInventoryRepository) GetItemByPartNumber(ctx context.Context, partNumber string) (*inventorymodels.Item, error) {
-	query := `
-		SELECT i.*, c.name as category_name, s.name as supplier_name
-		FROM arac.items i
-		LEFT JOIN arac.categories c ON i.category_id = c.category_id
-		LEFT JOIN arac.suppliers s ON i.supplier_id = s.supplier_id
-		WHERE i.part_number = $1
-	`
-
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, partNumber).Scan(
-		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-		&item.CategoryName, &item.SupplierName,
-	)
+func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
 
+	id, err := r.createItem(ctx, tx, item)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+		return 0, err
 	}
 
-	return item, nil
+	return id, tx.Commit(ctx)
 }
 
-func (r *PostgresInventoryRepository) GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error) {
-	query := `
-		SELECT i.*, c.name as category_name, s.name as supplier_name
-		FROM arac.items i
-		LEFT JOIN arac.categories c ON i.category_id = c.category_id
-		LEFT JOIN arac.suppliers s ON i.supplier_id = s.supplier_id
-		WHERE i.barcode = $1
-	`
+func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inventorymodels.Item) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	item := &inventorymodels.Item{}
-	err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(
-		&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-		&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-		&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-		&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-		&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-		&item.CategoryName, &item.SupplierName,
-	)
+	if err = r.updateItem(ctx, tx, item); err != nil {
+		return err
+	}
 
+	return tx.Commit(ctx)
+}
+
+// ImportItems creates the items without an ID and updates the rest, all in
+// one transaction. A failing item is reported as a *BatchError.
+func (r *PostgresInventoryRepository) ImportItems(ctx context.Context, items []*inventorymodels.Item) error {
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, item := range items {
+		if item.ItemID == 0 {
+			item.ItemID, err = r.createItem(ctx, tx, item)
+		} else {
+			err = r.updateItem(ctx, tx, item)
+		}
+		if err != nil {
+			return &BatchError{Index: i, Err: err}
 		}
-		return nil, err
 	}
 
-	return item, nil
+	return tx.Commit(ctx)
 }
 
-func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error) {
+func (r *PostgresInventoryRepository) createItem(ctx context.Context, tx pgx.Tx, item *inventorymodels.Item) (int, error) {
 	query := `
 	    INSERT INTO arac.items (
 	        part_number, description, category_id, buy_price, sell_price,
@@ -336,7 +323,7 @@ func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inve
 	    ) RETURNING item_id
 	`
 	var id int
-	err := r.db.Pool.QueryRow(
+	err := tx.QueryRow(
 		ctx, query,
 		item.PartNumber, item.Description, item.CategoryID, item.BuyPrice,
 		item.SellPrice, item.CurrentStock, item.MinimumStock, item.Barcode,
@@ -354,7 +341,10 @@ func (r *PostgresInventoryRepository) CreateItem(ctx context.Context, item *inve
 	return id, nil
 }
 
-func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inventorymodels.Item) error {
+// updateItem writes an item inside tx. Stock is left as it is, as it only
+// changes through the stock ledger; item.CurrentStock is set to the stock
+// the item has.
+func (r *PostgresInventoryRepository) updateItem(ctx context.Context, tx pgx.Tx, item *inventorymodels.Item) error {
 	query := `
 	UPDATE arac.items SET
 					part_number = $2,
@@ -362,31 +352,31 @@ func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inve
 					category_id = $4,
 					buy_price = $5,
 					sell_price = $6,
-					current_stock = $7,
-					minimum_stock = $8,
-					barcode = $9,
-					supplier_id = $10,
-					location_floor = $11,
-					location_corridor = $12,
-					location_aisle = $13,
-					location_shelf = $14,
-					location_bin = $15,
-					weight_kg = $16,
-					dimensions_cm = $17,
-					warranty_period = $18,
-					image_url = $19,
-					is_active = $20,
-					notes = $21,
-					make_id = $22,
-					model_id = $23,
-					submodel_id = $24,
-					oem_code = $25,
-					year_from = $26,
-					year_to = $27
+					minimum_stock = $7,
+					barcode = $8,
+					supplier_id = $9,
+					location_floor = $10,
+					location_corridor = $11,
+					location_aisle = $12,
+					location_shelf = $13,
+					location_bin = $14,
+					weight_kg = $15,
+					dimensions_cm = $16,
+					warranty_period = $17,
+					image_url = $18,
+					is_active = $19,
+					notes = $20,
+					make_id = $21,
+					model_id = $22,
+					submodel_id = $23,
+					oem_code = $24,
+					year_from = $25,
+					year_to = $26
 	WHERE item_id = $1
+	RETURNING current_stock
 `
 
-	result, err := r.db.Pool.Exec(
+	err := tx.QueryRow(
 		ctx,
 		query,
 		item.ItemID,
@@ -395,7 +385,6 @@ func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inve
 		item.CategoryID,
 		item.BuyPrice,
 		item.SellPrice,
-		item.CurrentStock,
 		item.MinimumStock,
 		item.Barcode,
 		item.SupplierID,
@@ -416,16 +405,15 @@ func (r *PostgresInventoryRepository) UpdateItem(ctx context.Context, item *inve
 		item.OEMCode,
 		item.YearFrom,
 		item.YearTo,
-	)
+	).Scan(&item.CurrentStock)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("item not found")
+		}
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("item not found")
-	}
-
 	return nil
 }
 
@@ -450,8 +438,8 @@ func (r *PostgresInventoryRepository) GetCompatibilities(ctx context.Context, it
             c.compat_id, c.item_id, c.submodel_id, c.notes, c.created_at,
             m.model_name, mk.make_name, s.submodel_name
         FROM compatibility c
-        JOIN vehicle_submodels s ON c.submodel_id = s.submodel_id
-        JOIN vehicle_models m ON s.model_id = m.model_id
+        JOIN submodels s ON c.submodel_id = s.submodel_id
+        JOIN models m ON s.model_id = m.model_id
         JOIN makes mk ON m.make_id = mk.make_id
         WHERE c.item_id = $1
         ORDER BY mk.make_name, m.model_name, s.submodel_name
@@ -526,17 +514,33 @@ func (r *PostgresInventoryRepository) RemoveCompatibility(ctx context.Context, i
 }
 
 func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error) {
-	query := `
-		SELECT i.*, c.name as category_name, s.name as supplier_name
-		FROM arac.items i
-		LEFT JOIN arac.categories c ON i.category_id = c.category_id
-		LEFT JOIN arac.suppliers s ON i.supplier_id = s.supplier_id
+	query := itemSelectQuery + `
 		JOIN arac.compatibility comp ON i.item_id = comp.item_id
 		WHERE comp.submodel_id = $1 AND i.is_active = true
 		ORDER BY i.part_number
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, submodelID)
+	return r.queryItems(ctx, query, submodelID)
+}
+
+// GetLowStockItems lists the active current parts whose stock, counting the
+// stock of the part numbers they superseded, is at or below the minimum.
+// Given a warehouse, only the stock there counts, against the warehouse's
+// own minimum when it has one; items never stocked there are left out.
+func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context, warehouseID *int) ([]*inventorymodels.Item, error) {
+	if warehouseID != nil {
+		return r.getWarehouseLowStockItems(ctx, *warehouseID)
+	}
+
+	query := `
+		SELECT listed.*, rs.stock - listed.current_stock AS superseded_stock
+		FROM (` + itemSelectQuery + ` WHERE i.is_active = true) listed
+		JOIN arac.rolled_up_stock rs ON rs.item_id = listed.item_id
+		WHERE rs.stock <= listed.minimum_stock
+		ORDER BY rs.stock ASC, listed.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -545,15 +549,7 @@ func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, su
 	var items []*inventorymodels.Item
 	for rows.Next() {
 		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-			&item.CategoryName, &item.SupplierName,
-		)
-		if err != nil {
+		if err := rows.Scan(append(itemScanTargets(item), &item.SupersededStock)...); err != nil {
 			return nil, err
 		}
 		items = append(items, item)
@@ -562,17 +558,22 @@ func (r *PostgresInventoryRepository) GetCompatibleItems(ctx context.Context, su
 	return items, rows.Err()
 }
 
-func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error) {
+func (r *PostgresInventoryRepository) getWarehouseLowStockItems(ctx context.Context, warehouseID int) ([]*inventorymodels.Item, error) {
 	query := `
-		SELECT i.*, c.name as category_name, s.name as supplier_name
-		FROM arac.items i
-		LEFT JOIN arac.categories c ON i.category_id = c.category_id
-		LEFT JOIN arac.suppliers s ON i.supplier_id = s.supplier_id
-		WHERE i.current_stock <= i.minimum_stock AND i.is_active = true
-		ORDER BY i.current_stock ASC, i.part_number
+		SELECT listed.*, rws.stock - ws.quantity AS superseded_stock,
+			w.warehouse_id, w.code, w.name, ws.quantity,
+			COALESCE(ws.minimum_stock, listed.minimum_stock) AS warehouse_minimum
+		FROM (` + itemSelectQuery + ` WHERE i.is_active = true) listed
+		JOIN arac.warehouse_stock ws ON ws.item_id = listed.item_id
+		JOIN arac.warehouses w ON w.warehouse_id = ws.warehouse_id
+		JOIN arac.rolled_up_warehouse_stock rws
+			ON rws.warehouse_id = ws.warehouse_id AND rws.item_id = listed.item_id
+		WHERE ws.warehouse_id = $1
+			AND rws.stock <= COALESCE(ws.minimum_stock, listed.minimum_stock)
+		ORDER BY rws.stock ASC, listed.part_number
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query)
+	rows, err := r.db.Pool.Query(ctx, query, warehouseID)
 	if err != nil {
 		return nil, err
 	}
@@ -580,15 +581,47 @@ func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*
 
 	var items []*inventorymodels.Item
 	for rows.Next() {
-		item := &inventorymodels.Item{}
-		err := rows.Scan(
-			&item.ItemID, &item.PartNumber, &item.Description, &item.CategoryID,
-			&item.BuyPrice, &item.SellPrice, &item.CurrentStock, &item.MinimumStock,
-			&item.Barcode, &item.SupplierID, &item.LocationAisle, &item.LocationShelf,
-			&item.LocationBin, &item.WeightKg, &item.DimensionsCm, &item.WarrantyPeriod,
-			&item.ImageURL, &item.IsActive, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
-			&item.CategoryName, &item.SupplierName,
+		item := &inventorymodels.Item{Warehouse: &inventorymodels.WarehouseStockLevel{}}
+		level := item.Warehouse
+		targets := append(itemScanTargets(item),
+			&item.SupersededStock,
+			&level.WarehouseID,
+			&level.WarehouseCode,
+			&level.WarehouseName,
+			&level.Quantity,
+			&level.MinimumStock,
 		)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *PostgresInventoryRepository) queryItem(ctx context.Context, query string, params ...interface{}) (*inventorymodels.Item, error) {
+	item, err := scanItem(r.db.Pool.QueryRow(ctx, query, params...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *PostgresInventoryRepository) queryItems(ctx context.Context, query string, params ...interface{}) ([]*inventorymodels.Item, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*inventorymodels.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -597,3 +630,76 @@ func (r *PostgresInventoryRepository) GetLowStockItems(ctx context.Context) ([]*
 
 	return items, rows.Err()
 }
+
+// itemSortValue returns the value an item is sorted by, as ItemSorting
+// compares it
+func itemSortValue(item *inventorymodels.Item, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(item.ItemID)
+	case "description":
+		return pagination.String(item.Description)
+	case "category":
+		return pagination.String(item.CategoryName)
+	case "sell_price":
+		return pagination.Float(item.SellPrice)
+	case "current_stock":
+		return strconv.Itoa(item.CurrentStock)
+	case "created_at":
+		return pagination.Time(item.CreatedAt)
+	case "updated_at":
+		return pagination.Time(item.UpdatedAt)
+	default:
+		return item.PartNumber
+	}
+}
+
+func scanItem(row pgx.Row) (*inventorymodels.Item, error) {
+	item := &inventorymodels.Item{}
+	if err := row.Scan(itemScanTargets(item)...); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// itemScanTargets lists where the columns of itemSelectQuery are read into
+func itemScanTargets(item *inventorymodels.Item) []interface{} {
+	return []interface{}{
+		&item.ItemID,
+		&item.PartNumber,
+		&item.Description,
+		&item.CategoryID,
+		&item.BuyPrice,
+		&item.SellPrice,
+		&item.CurrentStock,
+		&item.MinimumStock,
+		&item.Barcode,
+		&item.SupplierID,
+		&item.LocationFloor,
+		&item.LocationCorridor,
+		&item.LocationAisle,
+		&item.LocationShelf,
+		&item.LocationBin,
+		&item.WeightKg,
+		&item.DimensionsCm,
+		&item.WarrantyPeriod,
+		&item.ImageURL,
+		&item.IsActive,
+		&item.Notes,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&item.YearFrom,
+		&item.YearTo,
+		&item.MakeID,
+		&item.ModelID,
+		&item.SubmodelID,
+		&item.OEMCode,
+		&item.SupersededBy,
+		&item.SupersededOn,
+		&item.CategoryName,
+		&item.SupplierName,
+		&item.MakeName,
+		&item.ModelName,
+		&item.SubmodelName,
+	}
+}
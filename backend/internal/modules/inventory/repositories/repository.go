@@ -2,24 +2,72 @@ package repositories
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
+// BatchError reports which item of a batch could not be written
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
 type InventoryRepository interface {
 	// Item operations
-	GetItems(ctx context.Context, filter *inventorymodels.ItemFilter) ([]*inventorymodels.Item, error)
+	GetItems(ctx context.Context, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.Item, error)
+	SearchItems(ctx context.Context, term string, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.ItemSearchResult, error)
 	GetItemByID(ctx context.Context, id int) (*inventorymodels.Item, error)
 	GetItemByPartNumber(ctx context.Context, partNumber string) (*inventorymodels.Item, error)
 	GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error)
 	CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error)
 	UpdateItem(ctx context.Context, item *inventorymodels.Item) error
 	DeleteItem(ctx context.Context, id int) error
-	GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error)
+	GetLowStockItems(ctx context.Context, warehouseID *int) ([]*inventorymodels.Item, error)
+	ImportItems(ctx context.Context, items []*inventorymodels.Item) error
+	ExportItems(ctx context.Context, filter *inventorymodels.ItemFilter, fn func(item *inventorymodels.Item) error) error
 
 	// Compatibility operations
 	GetCompatibilities(ctx context.Context, itemID int) ([]*inventorymodels.Compatibility, error)
 	AddCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) (int, error)
 	RemoveCompatibility(ctx context.Context, itemID, submodelID int) error
 	GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error)
+	FindSubmodels(ctx context.Context, itemID int, selector *inventorymodels.VehicleSelector) ([]*inventorymodels.Compatibility, error)
+	UpsertCompatibilities(ctx context.Context, itemID int, submodelIDs []int, notes *string) (added, updated int, err error)
+	RemoveCompatibilities(ctx context.Context, itemID int, submodelIDs []int) (int, error)
+	CopyCompatibilities(ctx context.Context, sourceID, itemID int, replace bool) (*inventorymodels.BulkCompatibilityResult, error)
+	FindParts(ctx context.Context, filter *inventorymodels.PartFinderFilter) ([]*inventorymodels.Item, error)
+	GetCategoryPaths(ctx context.Context, categoryIDs []int) ([]*inventorymodels.PartFinderCategory, error)
+
+	// Cross-reference operations
+	GetCrossReferences(ctx context.Context, itemID int) ([]*inventorymodels.CrossReference, error)
+	AddCrossReference(ctx context.Context, reference *inventorymodels.CrossReference) (int, error)
+	ImportCrossReferences(ctx context.Context, references []*inventorymodels.CrossReference) error
+	RemoveCrossReference(ctx context.Context, itemID, referenceID int) error
+	FindInterchangeableItems(ctx context.Context, number string, inStock bool) ([]*inventorymodels.InterchangeItem, error)
+
+	// Supersession operations
+	GetCurrentItem(ctx context.Context, itemID int) (*inventorymodels.Item, error)
+	SetSupersession(ctx context.Context, itemID int, successorID *int, on *time.Time) error
+
+	// Barcode operations
+	NextBarcodeNumber(ctx context.Context) (int64, error)
+	GetItemBarcodes(ctx context.Context, itemID int) ([]*inventorymodels.ItemBarcode, error)
+	GetItemBarcode(ctx context.Context, itemID, barcodeID int) (*inventorymodels.ItemBarcode, error)
+	AddItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) (int, error)
+	UpdateItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) error
+	RemoveItemBarcode(ctx context.Context, itemID, barcodeID int) error
+
+	// Label operations
+	GetItemLocations(ctx context.Context, warehouseID *int, itemIDs []int) (map[int]string, error)
 }
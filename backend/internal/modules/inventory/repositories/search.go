@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/hsrvms/autoparts/pkg/search"
+)
+
+// FuzzyThreshold is how similar, from 0 to 1, a word of an item must be to
+// the search for a typo to still find it
+const FuzzyThreshold = 0.5
+
+// ItemSearchSorting lists the fields search results can be sorted by. They
+// are sorted by how well they match unless asked otherwise.
+var ItemSearchSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"relevance":     {Column: "ranked.score", Type: "double precision"},
+		"part_number":   {Column: "ranked.part_number", Type: "text"},
+		"sell_price":    {Column: "ranked.sell_price", Type: "numeric"},
+		"current_stock": {Column: "ranked.current_stock", Type: "integer"},
+	},
+	Default:      "-relevance",
+	IDColumn:     "ranked.item_id",
+	DefaultLimit: pagination.DefaultLimit,
+}
+
+// itemSearchQuery ranks the items of a filter query (%[1]s) against a search.
+// $%[2]d is the folded term, $%[3]d the normalized code, $%[4]d the prefix
+// query and $%[5]d the fuzzy threshold. Only the items whose stored search
+// document is similar to the term, matches the prefix query, or whose code
+// starts with or contains the search are scored, so the trigram and
+// full-text indexes find them first. Part number and OEM code matches rank
+// first, then full-text matches, then fuzzy ones.
+const itemSearchQuery = `
+    SELECT * FROM (
+        SELECT filtered.*,
+            CASE
+                WHEN $%[3]d = '' THEN 0
+                WHEN d.part_code = $%[3]d THEN 10
+                WHEN d.oem_code = $%[3]d THEN 9
+                WHEN d.part_code LIKE $%[3]d || '%%' OR d.oem_code LIKE $%[3]d || '%%' THEN 6
+                WHEN length($%[3]d) >= 3 AND (d.part_code LIKE '%%' || $%[3]d || '%%' OR d.oem_code LIKE '%%' || $%[3]d || '%%') THEN 4
+                ELSE 0
+            END
+            + CASE
+                WHEN $%[4]d <> '' AND d.vector @@ to_tsquery('simple', $%[4]d)
+                THEN 2 + ts_rank_cd(d.vector, to_tsquery('simple', $%[4]d))
+                ELSE 0
+            END
+            + CASE
+                WHEN word_similarity($%[2]d, d.document) >= $%[5]d THEN word_similarity($%[2]d, d.document)
+                ELSE 0
+            END AS score
+        FROM (%[1]s
+            AND ($%[2]d <%% i.search_document
+                OR ($%[4]d <> '' AND to_tsvector('simple', i.search_document) @@ to_tsquery('simple', $%[4]d))
+                OR ($%[3]d <> '' AND (
+                    arac.normalize_code(i.part_number) LIKE $%[3]d || '%%'
+                    OR arac.normalize_code(i.oem_code) LIKE $%[3]d || '%%'
+                    OR (length($%[3]d) >= 3 AND (
+                        arac.normalize_code(i.part_number) LIKE '%%' || $%[3]d || '%%'
+                        OR arac.normalize_code(i.oem_code) LIKE '%%' || $%[3]d || '%%'))
+                )))
+        ) filtered
+        JOIN arac.items doc ON doc.item_id = filtered.item_id
+        CROSS JOIN LATERAL (
+            SELECT
+                doc.search_document AS document,
+                to_tsvector('simple', doc.search_document) AS vector,
+                arac.normalize_code(filtered.part_number) AS part_code,
+                arac.normalize_code(filtered.oem_code) AS oem_code
+        ) d
+    ) ranked
+    WHERE ranked.score > 0`
+
+// SearchItems ranks the items matching a filter by how well they match
+// term. Case and Turkish letters are ignored, part numbers and OEM codes
+// match without their spaces and dashes, and small typos are tolerated.
+func (r *PostgresInventoryRepository) SearchItems(ctx context.Context, term string, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.ItemSearchResult, error) {
+	filtered, args := itemFilterQuery(filter)
+	query := fmt.Sprintf(itemSearchQuery, filtered, len(args)+1, len(args)+2, len(args)+3, len(args)+4)
+	args = append(args, search.Fold(term), search.NormalizeCode(term), search.PrefixQuery(term), FuzzyThreshold)
+
+	// <% finds the words as similar as pg_trgm.word_similarity_threshold,
+	// which is set to FuzzyThreshold for this search only
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	threshold := strconv.FormatFloat(FuzzyThreshold, 'f', -1, 64)
+	if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)`, threshold); err != nil {
+		return nil, err
+	}
+
+	if page != nil {
+		if err := tx.QueryRow(ctx, pagination.CountQuery(query), args...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	query, args = ItemSearchSorting.Apply(query, args, page)
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*inventorymodels.ItemSearchResult
+	for rows.Next() {
+		result := &inventorymodels.ItemSearchResult{Item: &inventorymodels.Item{}}
+		if err := rows.Scan(append(itemScanTargets(result.Item), &result.Score)...); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page != nil && len(results) > 0 {
+		last := results[len(results)-1]
+		page.SetNextCursor(len(results), itemSearchSortValue(last, page.Sort), last.ItemID)
+	}
+
+	return results, nil
+}
+
+// itemSearchSortValue returns the value a search result is sorted by, as
+// ItemSearchSorting compares it
+func itemSearchSortValue(result *inventorymodels.ItemSearchResult, sort string) string {
+	switch sort {
+	case "part_number":
+		return result.PartNumber
+	case "sell_price":
+		return pagination.Float(result.SellPrice)
+	case "current_stock":
+		return strconv.Itoa(result.CurrentStock)
+	default:
+		return pagination.Float(result.Score)
+	}
+}
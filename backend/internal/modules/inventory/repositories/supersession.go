@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+// GetCurrentItem follows the supersessions in effect today from an item to
+// the part that replaces it, which is the item itself when it has not been
+// superseded
+func (r *PostgresInventoryRepository) GetCurrentItem(ctx context.Context, itemID int) (*inventorymodels.Item, error) {
+	return r.queryItem(ctx, itemSelectQuery+" WHERE i.item_id = arac.current_item_id($1)", itemID)
+}
+
+// SetSupersession marks an item as superseded by successorID from the given
+// date. A nil successorID clears the supersession.
+func (r *PostgresInventoryRepository) SetSupersession(ctx context.Context, itemID int, successorID *int, on *time.Time) error {
+	query := `UPDATE arac.items SET superseded_by = $2, superseded_on = $3 WHERE item_id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, itemID, successorID, on)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("item not found")
+	}
+
+	return nil
+}
@@ -1,38 +1,72 @@
 package inventory
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/services"
+	"github.com/hsrvms/autoparts/pkg/config"
 	"github.com/hsrvms/autoparts/pkg/db"
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterRoutes(api *echo.Group, database *db.Database) {
-	// Initialize repository
+func RegisterRoutes(api *echo.Group, database *db.Database, cfg config.BarcodeConfig) {
+	// Initialize repositories
 	repo := repositories.NewPostgresInventoryRepository(database)
 
-	// Initialize service
-	service := services.NewInventoryService(repo)
+	// Initialize services
+	barcodeService := services.NewBarcodeService(cfg)
+	service := services.NewInventoryService(repo, barcodeService)
 
 	// Initialize handler
-	handler := handlers.NewInventoryHandler(service)
+	handler := handlers.NewInventoryHandler(service, barcodeService)
+
+	// Permissions
+	read := authmiddleware.RequirePermission(authmodels.PermInventoryRead)
+	write := authmiddleware.RequirePermission(authmodels.PermInventoryWrite)
+	remove := authmiddleware.RequirePermission(authmodels.PermInventoryDelete)
+	editCosts := authmiddleware.RequirePermission(authmodels.PermInventoryCosts)
 
 	// Item routes
 	items := api.Group("/items")
-	items.GET("", handler.GetItems)
-	items.GET("/low-stock", handler.GetLowStockItems)
-	items.GET("/:id", handler.GetItemByID)
-	items.GET("/barcode/:barcode", handler.GetItemByBarcode)
-	items.POST("", handler.CreateItem)
-	items.PUT("/:id", handler.UpdateItem)
-	items.DELETE("/:id", handler.DeleteItem)
-	items.GET("/barcode/:barcode/image", handler.GetBarcodeImage)
-	items.POST("/generate-barcode", handler.GenerateBarcode)
+	items.GET("", handler.GetItems, read)
+	items.GET("/low-stock", handler.GetLowStockItems, read)
+	items.GET("/search", handler.SearchItems, read)
+	items.GET("/export", handler.ExportItems, read)
+	items.POST("/import", handler.ImportItems, write, editCosts)
+	items.GET("/:id", handler.GetItemByID, read)
+	items.GET("/barcode/:barcode", handler.GetItemByBarcode, read)
+	items.GET("/part-number/:partNumber", handler.GetItemByPartNumber, read)
+	items.POST("", handler.CreateItem, write, editCosts)
+	items.PUT("/:id", handler.UpdateItem, write)
+	items.DELETE("/:id", handler.DeleteItem, remove)
+	items.GET("/barcode/:barcode/image", handler.GetBarcodeImage, read)
+	items.POST("/generate-barcode", handler.GenerateBarcode, write)
+	items.POST("/labels", handler.PrintLabels, read)
+	items.PUT("/:id/supersession", handler.SetSupersession, write)
+	items.DELETE("/:id/supersession", handler.RemoveSupersession, write)
 
 	// Compatibility routes
-	items.GET("/:itemId/compatibilities", handler.GetCompatibilities)
-	items.POST("/:itemId/compatibilities", handler.AddCompatibility)
-	items.DELETE("/:itemId/compatibilities/:submodelId", handler.RemoveCompatibility)
-	api.GET("/submodels/:submodelId/compatible-items", handler.GetCompatibleItems)
+	items.GET("/:itemId/compatibilities", handler.GetCompatibilities, read)
+	items.POST("/:itemId/compatibilities", handler.AddCompatibility, write)
+	items.DELETE("/:itemId/compatibilities/:submodelId", handler.RemoveCompatibility, write)
+	items.POST("/:itemId/compatibilities/bulk", handler.AssignCompatibilities, write)
+	items.POST("/:itemId/compatibilities/bulk-remove", handler.RemoveCompatibilities, write)
+	items.POST("/:itemId/compatibilities/copy", handler.CopyCompatibilities, write)
+	api.GET("/submodels/:submodelId/compatible-items", handler.GetCompatibleItems, read)
+	items.GET("/finder", handler.FindParts, read)
+
+	// Cross-reference routes
+	items.GET("/cross-reference/:number", handler.FindCrossReference, read)
+	items.POST("/cross-references/import", handler.ImportCrossReferences, write)
+	items.GET("/:itemId/cross-references", handler.GetCrossReferences, read)
+	items.POST("/:itemId/cross-references", handler.AddCrossReference, write)
+	items.DELETE("/:itemId/cross-references/:referenceId", handler.RemoveCrossReference, write)
+
+	// Item barcode routes
+	items.GET("/:itemId/barcodes", handler.GetItemBarcodes, read)
+	items.POST("/:itemId/barcodes", handler.AddItemBarcode, write)
+	items.PUT("/:itemId/barcodes/:barcodeId", handler.UpdateItemBarcode, write)
+	items.DELETE("/:itemId/barcodes/:barcodeId", handler.RemoveItemBarcode, write)
 }
@@ -2,80 +2,97 @@ package services
 
 import (
 	"bytes"
-	"fmt"
-	"image/png"
+	"context"
+	"errors"
 
-	"github.com/boombuler/barcode"
-	"github.com/boombuler/barcode/code128"
 	pkgbarcode "github.com/hsrvms/autoparts/pkg/barcode"
+	"github.com/hsrvms/autoparts/pkg/config"
+)
+
+// maxBarcodeAttempts is how many item numbers GenerateBarcode tries before
+// giving up, when barcodes typed in by hand already use them
+const maxBarcodeAttempts = 100
+
+var (
+	ErrUnknownBarcodeFormat = pkgbarcode.ErrUnknownFormat
+	ErrNoCompanyPrefix      = pkgbarcode.ErrNoCompanyPrefix
+	ErrBarcodeUnavailable   = errors.New("no unused barcode could be found")
+	ErrUnknownSymbology     = pkgbarcode.ErrUnknownSymbology
+	ErrUnknownImageFormat   = pkgbarcode.ErrUnknownImageFormat
+	ErrInvalidImageSize     = pkgbarcode.ErrInvalidImageSize
+	ErrInvalidImageDPI      = pkgbarcode.ErrInvalidDPI
+	ErrImageTooSmall        = pkgbarcode.ErrImageTooSmall
+	ErrCannotEncodeBarcode  = pkgbarcode.ErrCannotEncode
 )
 
 // BarcodeService handles barcode operations
 type BarcodeService interface {
-	GenerateBarcode(makeID, modelID, submodelID, categoryID, yearFrom, yearTo int) (string, error)
-	GenerateBarcodeImage(barcode string) ([]byte, error)
+	DefaultFormat() string
+	NewBarcode(format string, number int64) (string, error)
+	GenerateBarcodeImage(barcode string, options *pkgbarcode.ImageOptions) ([]byte, error)
 }
 
 type barcodeService struct {
 	generator *pkgbarcode.Generator
 }
 
-func NewBarcodeService() BarcodeService {
+func NewBarcodeService(cfg config.BarcodeConfig) BarcodeService {
 	return &barcodeService{
-		generator: pkgbarcode.New(),
+		generator: pkgbarcode.New(cfg.GS1CompanyPrefix),
 	}
 }
 
-// GenerateBarcode creates a unique barcode for an item
-func (s *barcodeService) GenerateBarcode(makeID, modelID, submodelID, categoryID, yearFrom, yearTo int) (string, error) {
-	// Validate inputs
-	if makeID <= 0 || modelID <= 0 || submodelID <= 0 || categoryID <= 0 {
-		return "", fmt.Errorf("invalid input parameters")
-	}
-
-	if yearFrom <= 0 || yearTo <= 0 || yearFrom > yearTo {
-		return "", fmt.Errorf("invalid year range")
-	}
-
-	// Generate category prefix
-	categoryPrefix := fmt.Sprintf("C%d", categoryID)
-
-	// Generate unique item ID from make, model, and submodel IDs
-	itemID := makeID*1000000 + modelID*1000 + submodelID
-
-	// Generate barcode
-	barcode, err := s.generator.Generate(categoryPrefix, itemID, yearFrom, yearTo)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate barcode: %w", err)
-	}
+// DefaultFormat returns the format barcodes are issued in when none is
+// asked for
+func (s *barcodeService) DefaultFormat() string {
+	return s.generator.DefaultFormat()
+}
 
-	return barcode, nil
+// NewBarcode creates the barcode of a format for an item number
+func (s *barcodeService) NewBarcode(format string, number int64) (string, error) {
+	return s.generator.Generate(format, number)
 }
 
-// GenerateBarcodeImage generates a PNG image of the barcode
-func (s *barcodeService) GenerateBarcodeImage(barcodeText string) ([]byte, error) {
-	// Validate barcode
-	if !s.generator.Validate(barcodeText) {
-		return nil, fmt.Errorf("invalid barcode format")
+// GenerateBarcodeImage draws a barcode as an image. Barcodes of any
+// issuer are drawn, as long as the symbology can hold them.
+func (s *barcodeService) GenerateBarcodeImage(barcode string, options *pkgbarcode.ImageOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pkgbarcode.DrawImage(&buf, barcode, options); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	// Create barcode
-	barcodeObj, err := code128.Encode(barcodeText)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode barcode: %w", err)
-	}
+// Barcode operations
 
-	// Scale barcode to a reasonable size
-	scaledBarcode, err := barcode.Scale(barcodeObj.(barcode.BarcodeIntCS), 300, 100)
-	if err != nil {
-		return nil, fmt.Errorf("failed to scale barcode: %w", err)
+// GenerateBarcode issues a new barcode in a format, or in the default one
+// when format is empty. Item numbers come from a database sequence, and
+// numbers whose barcode an item already has are skipped, so the barcode is
+// not in use.
+func (s *inventoryService) GenerateBarcode(ctx context.Context, format string) (string, error) {
+	if format == "" {
+		format = s.barcodeService.DefaultFormat()
 	}
 
-	// Encode barcode as PNG
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, scaledBarcode); err != nil {
-		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	for attempt := 0; attempt < maxBarcodeAttempts; attempt++ {
+		number, err := s.repo.NextBarcodeNumber(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		code, err := s.barcodeService.NewBarcode(format, number)
+		if err != nil {
+			return "", err
+		}
+
+		existing, err := s.repo.GetItemByBarcode(ctx, code)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return code, nil
+		}
 	}
 
-	return buf.Bytes(), nil
+	return "", ErrBarcodeUnavailable
 }
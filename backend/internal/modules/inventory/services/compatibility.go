@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+var (
+	ErrSelectorRequired   = errors.New("a make or model is required")
+	ErrInvalidYearRange   = errors.New("year_from cannot be after year_to")
+	ErrSourceItemNotFound = errors.New("source item not found")
+	ErrCopyFromSelf       = errors.New("cannot copy compatibilities from the item itself")
+)
+
+// AssignCompatibilities makes an item compatible with every submodel the
+// request picks. Existing compatibilities are kept, so running it again
+// changes nothing.
+func (s *inventoryService) AssignCompatibilities(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, error) {
+	result, existing, err := s.selectSubmodels(ctx, itemID, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Submodels) == 0 {
+		return result, nil
+	}
+
+	if request.DryRun {
+		for _, submodel := range result.Submodels {
+			if existing[submodel.SubmodelID] {
+				result.Updated++
+			} else {
+				result.Added++
+			}
+		}
+		return result, nil
+	}
+
+	result.Added, result.Updated, err = s.repo.UpsertCompatibilities(ctx, itemID, submodelIDs(result.Submodels), request.Notes)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// RemoveCompatibilities removes an item's compatibility with every submodel
+// the request picks
+func (s *inventoryService) RemoveCompatibilities(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, error) {
+	result, existing, err := s.selectSubmodels(ctx, itemID, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Submodels) == 0 {
+		return result, nil
+	}
+
+	if request.DryRun {
+		for _, submodel := range result.Submodels {
+			if existing[submodel.SubmodelID] {
+				result.Removed++
+			}
+		}
+		return result, nil
+	}
+
+	result.Removed, err = s.repo.RemoveCompatibilities(ctx, itemID, submodelIDs(result.Submodels))
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CopyCompatibilities gives an item the compatibilities of another item,
+// such as the same part from another brand
+func (s *inventoryService) CopyCompatibilities(ctx context.Context, itemID int, request *inventorymodels.CopyCompatibilitiesRequest) (*inventorymodels.BulkCompatibilityResult, error) {
+	if itemID <= 0 || request.SourceItemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if itemID == request.SourceItemID {
+		return nil, ErrCopyFromSelf
+	}
+
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	source, err := s.repo.GetItemByID(ctx, request.SourceItemID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, ErrSourceItemNotFound
+	}
+
+	result, err := s.repo.CopyCompatibilities(ctx, request.SourceItemID, itemID, request.Replace)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Submodels, err = s.repo.GetCompatibilities(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Helper functions
+
+// selectSubmodels checks a bulk request and finds the submodels it picks,
+// along with the submodels the item is already compatible with
+func (s *inventoryService) selectSubmodels(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, map[int]bool, error) {
+	if itemID <= 0 {
+		return nil, nil, ErrInvalidItemID
+	}
+	if request.MakeID == nil && request.ModelID == nil {
+		return nil, nil, ErrSelectorRequired
+	}
+	if request.YearFrom != nil && request.YearTo != nil && *request.YearFrom > *request.YearTo {
+		return nil, nil, ErrInvalidYearRange
+	}
+
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, ErrItemNotFound
+	}
+
+	submodels, err := s.repo.FindSubmodels(ctx, itemID, &request.VehicleSelector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	compatibilities, err := s.repo.GetCompatibilities(ctx, itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := map[int]bool{}
+	for _, compatibility := range compatibilities {
+		existing[compatibility.SubmodelID] = true
+	}
+
+	result := &inventorymodels.BulkCompatibilityResult{
+		DryRun:    request.DryRun,
+		Matched:   len(submodels),
+		Submodels: submodels,
+	}
+	return result, existing, nil
+}
+
+func submodelIDs(compatibilities []*inventorymodels.Compatibility) []int {
+	ids := make([]int, len(compatibilities))
+	for i, compatibility := range compatibilities {
+		ids[i] = compatibility.SubmodelID
+	}
+	return ids
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+	"github.com/hsrvms/autoparts/pkg/search"
+)
+
+var (
+	ErrInvalidReferenceNumber = errors.New("reference number must contain letters or digits")
+	ErrInvalidReferenceType   = errors.New("reference type must be oem, oe-supplier or aftermarket")
+	ErrInvalidReferenceID     = errors.New("invalid cross-reference ID")
+	ErrBrandRequired          = errors.New("brand is required")
+	ErrCrossReferenceExists   = errors.New("item already has this cross-reference")
+)
+
+// crossReferenceFields are the columns of a cross-reference list. Only
+// reference_type and notes may be left out.
+var crossReferenceFields = []string{"part_number", "brand", "reference_number", "reference_type", "notes"}
+
+// referenceTypeNames maps the ways lists name reference types, folded, to
+// the types
+var referenceTypeNames = map[string]string{
+	"oem":         inventorymodels.ReferenceTypeOEM,
+	"oe":          inventorymodels.ReferenceTypeOEM,
+	"original":    inventorymodels.ReferenceTypeOEM,
+	"orijinal":    inventorymodels.ReferenceTypeOEM,
+	"oe-supplier": inventorymodels.ReferenceTypeOESupplier,
+	"oe supplier": inventorymodels.ReferenceTypeOESupplier,
+	"oes":         inventorymodels.ReferenceTypeOESupplier,
+	"aftermarket": inventorymodels.ReferenceTypeAftermarket,
+	"equivalent":  inventorymodels.ReferenceTypeAftermarket,
+	"esdeger":     inventorymodels.ReferenceTypeAftermarket,
+	"muadil":      inventorymodels.ReferenceTypeAftermarket,
+}
+
+// Cross-reference operations
+func (s *inventoryService) GetCrossReferences(ctx context.Context, itemID int) ([]*inventorymodels.CrossReference, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	return s.repo.GetCrossReferences(ctx, itemID)
+}
+
+func (s *inventoryService) AddCrossReference(ctx context.Context, reference *inventorymodels.CrossReference) (int, error) {
+	if reference.ItemID <= 0 {
+		return 0, ErrInvalidItemID
+	}
+	if err := validateCrossReference(reference); err != nil {
+		return 0, err
+	}
+
+	item, err := s.repo.GetItemByID(ctx, reference.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	if item == nil {
+		return 0, ErrItemNotFound
+	}
+
+	references, err := s.repo.GetCrossReferences(ctx, reference.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range references {
+		if sameCrossReference(existing, reference) {
+			return 0, ErrCrossReferenceExists
+		}
+	}
+
+	return s.repo.AddCrossReference(ctx, reference)
+}
+
+func (s *inventoryService) RemoveCrossReference(ctx context.Context, itemID, referenceID int) error {
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if referenceID <= 0 {
+		return ErrInvalidReferenceID
+	}
+
+	return s.repo.RemoveCrossReference(ctx, itemID, referenceID)
+}
+
+// FindInterchangeableItems finds the items that can replace a part, given
+// any number read off it: an OEM number, an OE supplier number or another
+// brand's number
+func (s *inventoryService) FindInterchangeableItems(ctx context.Context, number string, inStock bool) ([]*inventorymodels.InterchangeItem, error) {
+	if search.NormalizeCode(number) == "" {
+		return nil, ErrInvalidReferenceNumber
+	}
+
+	return s.repo.FindInterchangeableItems(ctx, number, inStock)
+}
+
+// ImportCrossReferences adds the cross-references of a CSV or XLSX list
+// with part_number, brand, reference_number and optionally reference_type
+// and notes columns. Rows are matched to items by part number, and a
+// reference an item already has gets its type and notes updated. Nothing is
+// written unless every row passes.
+func (s *inventoryService) ImportCrossReferences(ctx context.Context, file io.Reader, options *inventorymodels.ItemImportOptions) (*inventorymodels.CrossReferenceImportResult, error) {
+	records, err := readRecords(file, options.Format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyImportFile
+	}
+
+	columns, err := mapCrossReferenceColumns(records[0], options.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	value := func(record []string, field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	result := &inventorymodels.CrossReferenceImportResult{DryRun: options.DryRun}
+	var references []*inventorymodels.CrossReference
+	var referenceRows []*inventorymodels.CrossReferenceImportRow
+	items := map[string]*inventorymodels.Item{}
+	existing := map[int][]*inventorymodels.CrossReference{}
+	seen := map[string]int{}
+
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := &inventorymodels.CrossReferenceImportRow{
+			Row:             i + 2,
+			PartNumber:      value(record, "part_number"),
+			Brand:           value(record, "brand"),
+			ReferenceNumber: value(record, "reference_number"),
+			Action:          inventorymodels.ImportActionCreate,
+		}
+		reference := &inventorymodels.CrossReference{
+			Brand:           row.Brand,
+			ReferenceNumber: row.ReferenceNumber,
+			ReferenceType:   inventorymodels.ReferenceTypeAftermarket,
+		}
+		if referenceType := value(record, "reference_type"); referenceType != "" {
+			reference.ReferenceType = referenceTypeNames[search.Fold(referenceType)]
+		}
+		if notes := value(record, "notes"); notes != "" {
+			reference.Notes = &notes
+		}
+
+		if err := validateCrossReference(reference); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+
+		item, ok := items[row.PartNumber]
+		if !ok && row.PartNumber != "" {
+			if item, err = s.repo.GetItemByPartNumber(ctx, row.PartNumber); err != nil {
+				return nil, err
+			}
+			items[row.PartNumber] = item
+		}
+		switch {
+		case row.PartNumber == "":
+			row.Errors = append(row.Errors, "part number is required")
+		case item == nil:
+			row.Errors = append(row.Errors, "no item with part number "+row.PartNumber)
+		default:
+			row.ItemID = item.ItemID
+			reference.ItemID = item.ItemID
+
+			if _, ok := existing[item.ItemID]; !ok {
+				if existing[item.ItemID], err = s.repo.GetCrossReferences(ctx, item.ItemID); err != nil {
+					return nil, err
+				}
+			}
+			for _, other := range existing[item.ItemID] {
+				if sameCrossReference(other, reference) {
+					row.Action = inventorymodels.ImportActionUpdate
+				}
+			}
+
+			key := fmt.Sprintf("%d|%s|%s", item.ItemID, search.Fold(reference.Brand), search.NormalizeCode(reference.ReferenceNumber))
+			if first, ok := seen[key]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("reference already appears on row %d", first))
+			} else {
+				seen[key] = row.Row
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+		if len(row.Errors) > 0 {
+			row.Action = inventorymodels.ImportActionError
+			result.Failed++
+			continue
+		}
+		if row.Action == inventorymodels.ImportActionCreate {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		references = append(references, reference)
+		referenceRows = append(referenceRows, row)
+	}
+
+	if result.Failed > 0 {
+		return result, ErrImportFailed
+	}
+	if options.DryRun {
+		return result, nil
+	}
+
+	if err := s.repo.ImportCrossReferences(ctx, references); err != nil {
+		var batchErr *repositories.BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, err
+		}
+		row := referenceRows[batchErr.Index]
+		row.Action = inventorymodels.ImportActionError
+		row.Errors = append(row.Errors, batchErr.Err.Error())
+		result.Failed = 1
+		return result, ErrImportFailed
+	}
+
+	return result, nil
+}
+
+// Helper functions
+func validateCrossReference(reference *inventorymodels.CrossReference) error {
+	reference.Brand = strings.TrimSpace(reference.Brand)
+	reference.ReferenceNumber = strings.TrimSpace(reference.ReferenceNumber)
+
+	if reference.Brand == "" {
+		return ErrBrandRequired
+	}
+	if search.NormalizeCode(reference.ReferenceNumber) == "" {
+		return ErrInvalidReferenceNumber
+	}
+	switch reference.ReferenceType {
+	case inventorymodels.ReferenceTypeOEM, inventorymodels.ReferenceTypeOESupplier, inventorymodels.ReferenceTypeAftermarket:
+	default:
+		return ErrInvalidReferenceType
+	}
+	return nil
+}
+
+// sameCrossReference reports whether two references are the same number of
+// the same brand, as the database compares them
+func sameCrossReference(a, b *inventorymodels.CrossReference) bool {
+	return search.Fold(a.Brand) == search.Fold(b.Brand) &&
+		search.NormalizeCode(a.ReferenceNumber) == search.NormalizeCode(b.ReferenceNumber)
+}
+
+// mapCrossReferenceColumns works out which column of a cross-reference list
+// holds each field
+func mapCrossReferenceColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	for field := range mapping {
+		known := false
+		for _, name := range crossReferenceFields {
+			known = known || name == field
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownImportField, field)
+		}
+	}
+
+	columns := map[string]int{}
+	for _, field := range crossReferenceFields {
+		name := field
+		mapped, ok := mapping[field]
+		if ok {
+			name = strings.ToLower(strings.TrimSpace(mapped))
+		}
+
+		index, found := positions[name]
+		switch {
+		case found:
+			columns[field] = index
+		case ok:
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, mapped)
+		case field == "part_number":
+			return nil, ErrNoPartNumberColumn
+		case field == "brand" || field == "reference_number":
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, field)
+		}
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+var (
+	ErrVehicleRequired = errors.New("a model or submodel is required")
+	ErrInvalidYear     = errors.New("year is out of range")
+)
+
+// FindParts lists the parts that fit a vehicle, grouped by the category
+// tree with the number of parts under every category. Only categories with
+// parts, and the categories above them, are included.
+func (s *inventoryService) FindParts(ctx context.Context, filter *inventorymodels.PartFinderFilter) (*inventorymodels.PartFinderResult, error) {
+	if filter.ModelID == nil && filter.SubmodelID == nil {
+		return nil, ErrVehicleRequired
+	}
+	if filter.Year != nil && (*filter.Year < 1900 || *filter.Year > time.Now().Year()+1) {
+		return nil, ErrInvalidYear
+	}
+
+	items, err := s.repo.FindParts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &inventorymodels.PartFinderResult{
+		Total:      len(items),
+		Categories: []*inventorymodels.PartFinderCategory{},
+	}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	var categoryIDs []int
+	byCategory := map[int][]*inventorymodels.Item{}
+	for _, item := range items {
+		if _, ok := byCategory[item.CategoryID]; !ok {
+			categoryIDs = append(categoryIDs, item.CategoryID)
+		}
+		byCategory[item.CategoryID] = append(byCategory[item.CategoryID], item)
+	}
+
+	categories, err := s.repo.GetCategoryPaths(ctx, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Categories = buildPartFinderTree(categories, byCategory)
+	return result, nil
+}
+
+// Helper functions
+
+// buildPartFinderTree places the items in their categories and the
+// categories under their parents, keeping the order categories come in
+func buildPartFinderTree(categories []*inventorymodels.PartFinderCategory, items map[int][]*inventorymodels.Item) []*inventorymodels.PartFinderCategory {
+	nodes := map[int]*inventorymodels.PartFinderCategory{}
+	for _, category := range categories {
+		category.Items = items[category.CategoryID]
+		nodes[category.CategoryID] = category
+	}
+
+	var roots []*inventorymodels.PartFinderCategory
+	for _, category := range categories {
+		if category.ParentCategoryID != nil {
+			if parent, ok := nodes[*category.ParentCategoryID]; ok {
+				parent.Subcategories = append(parent.Subcategories, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+
+	for _, root := range roots {
+		countPartFinderItems(root)
+	}
+	return roots
+}
+
+// countPartFinderItems sets the item count of a category and those below it
+func countPartFinderItems(category *inventorymodels.PartFinderCategory) int {
+	category.ItemCount = len(category.Items)
+	for _, subcategory := range category.Subcategories {
+		category.ItemCount += countPartFinderItems(subcategory)
+	}
+	return category.ItemCount
+}
@@ -0,0 +1,587 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrInvalidFileFormat  = errors.New("file must be a CSV or XLSX file")
+	ErrEmptyImportFile    = errors.New("file has no header row")
+	ErrUnknownImportField = errors.New("mapping refers to an unknown item field")
+	ErrMissingColumn      = errors.New("mapped column is not in the file")
+	ErrNoPartNumberColumn = errors.New("file must have a part number column")
+	ErrImportFailed       = errors.New("import has invalid rows, nothing was written")
+)
+
+// utf8BOM is written at the start of CSV exports so spreadsheet programs
+// read Turkish characters correctly
+const utf8BOM = "\ufeff"
+
+// exportFlushRows is how many CSV rows are buffered before they are sent
+const exportFlushRows = 500
+
+// itemColumn is an item field as it appears in an import or export file
+type itemColumn struct {
+	name string
+	get  func(item *inventorymodels.Item) string
+	// set is nil for columns that are only exported
+	set func(item *inventorymodels.Item, value string) error
+	// cost columns are left out of exports for users who may not see costs
+	cost bool
+}
+
+// mappedColumn is an item field read from a column of an import file
+type mappedColumn struct {
+	index  int
+	column *itemColumn
+}
+
+// itemColumns are the columns of an export, in order. A file exported
+// here can be imported again as it is.
+var itemColumns = []*itemColumn{
+	textColumn("part_number", func(item *inventorymodels.Item) *string { return &item.PartNumber }),
+	optionalTextColumn("description", func(item *inventorymodels.Item) **string { return &item.Description }),
+	intColumn("category_id", func(item *inventorymodels.Item) *int { return &item.CategoryID }),
+	readOnlyColumn("category_name", func(item *inventorymodels.Item) string { return valueOf(item.CategoryName) }),
+	intColumn("make_id", func(item *inventorymodels.Item) *int { return &item.MakeID }),
+	intColumn("model_id", func(item *inventorymodels.Item) *int { return &item.ModelID }),
+	intColumn("submodel_id", func(item *inventorymodels.Item) *int { return &item.SubmodelID }),
+	optionalIntColumn("year_from", func(item *inventorymodels.Item) **int { return &item.YearFrom }),
+	optionalIntColumn("year_to", func(item *inventorymodels.Item) **int { return &item.YearTo }),
+	optionalTextColumn("oem_code", func(item *inventorymodels.Item) **string { return &item.OEMCode }),
+	optionalTextColumn("barcode", func(item *inventorymodels.Item) **string { return &item.Barcode }),
+	optionalIntColumn("supplier_id", func(item *inventorymodels.Item) **int { return &item.SupplierID }),
+	costColumn(floatColumn("buy_price", func(item *inventorymodels.Item) *float64 { return &item.BuyPrice })),
+	floatColumn("sell_price", func(item *inventorymodels.Item) *float64 { return &item.SellPrice }),
+	intColumn("current_stock", func(item *inventorymodels.Item) *int { return &item.CurrentStock }),
+	intColumn("minimum_stock", func(item *inventorymodels.Item) *int { return &item.MinimumStock }),
+	optionalTextColumn("location_floor", func(item *inventorymodels.Item) **string { return &item.LocationFloor }),
+	optionalTextColumn("location_corridor", func(item *inventorymodels.Item) **string { return &item.LocationCorridor }),
+	optionalTextColumn("location_aisle", func(item *inventorymodels.Item) **string { return &item.LocationAisle }),
+	optionalTextColumn("location_shelf", func(item *inventorymodels.Item) **string { return &item.LocationShelf }),
+	optionalTextColumn("location_bin", func(item *inventorymodels.Item) **string { return &item.LocationBin }),
+	optionalFloatColumn("weight_kg", func(item *inventorymodels.Item) **float64 { return &item.WeightKg }),
+	optionalTextColumn("dimensions_cm", func(item *inventorymodels.Item) **string { return &item.DimensionsCm }),
+	optionalTextColumn("warranty_period", func(item *inventorymodels.Item) **string { return &item.WarrantyPeriod }),
+	optionalTextColumn("image_url", func(item *inventorymodels.Item) **string { return &item.ImageURL }),
+	boolColumn("is_active", func(item *inventorymodels.Item) *bool { return &item.IsActive }),
+	optionalTextColumn("notes", func(item *inventorymodels.Item) **string { return &item.Notes }),
+}
+
+// ImportItems creates or updates an item for every row of a CSV or XLSX
+// file, matching rows to items by part number. Empty cells leave the value
+// of an existing item as it is. Rows are checked with the same rules as
+// CreateItem and UpdateItem, and nothing is written unless every row passes.
+func (s *inventoryService) ImportItems(ctx context.Context, file io.Reader, options *inventorymodels.ItemImportOptions) (*inventorymodels.ItemImportResult, error) {
+	records, err := readRecords(file, options.Format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyImportFile
+	}
+
+	columns, err := mapColumns(records[0], options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &inventorymodels.ItemImportResult{DryRun: options.DryRun}
+	var items []*inventorymodels.Item
+	var itemRows []*inventorymodels.ItemImportRow
+	partNumberRows := map[string]int{}
+	barcodeRows := map[string]int{}
+
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row, item, err := s.importRow(ctx, i+2, record, columns)
+		if err != nil {
+			return nil, err
+		}
+
+		// The same part number or barcode twice in one file is a mistake
+		if first, ok := partNumberRows[item.PartNumber]; ok && item.PartNumber != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("part number already appears on row %d", first))
+		} else {
+			partNumberRows[item.PartNumber] = row.Row
+		}
+		if item.Barcode != nil && *item.Barcode != "" {
+			if first, ok := barcodeRows[*item.Barcode]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("barcode already appears on row %d", first))
+			} else {
+				barcodeRows[*item.Barcode] = row.Row
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+		if len(row.Errors) > 0 {
+			row.Action = inventorymodels.ImportActionError
+			result.Failed++
+			continue
+		}
+		if row.Action == inventorymodels.ImportActionCreate {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		items = append(items, item)
+		itemRows = append(itemRows, row)
+	}
+
+	if result.Failed > 0 {
+		return result, ErrImportFailed
+	}
+	if options.DryRun {
+		return result, nil
+	}
+
+	if err := s.repo.ImportItems(ctx, items); err != nil {
+		var batchErr *repositories.BatchError
+		if !errors.As(err, &batchErr) {
+			return nil, err
+		}
+		row := itemRows[batchErr.Index]
+		row.Action = inventorymodels.ImportActionError
+		row.Errors = append(row.Errors, batchErr.Err.Error())
+		result.Failed = 1
+		return result, ErrImportFailed
+	}
+
+	for i, item := range items {
+		itemRows[i].ItemID = item.ItemID
+	}
+
+	return result, nil
+}
+
+// ExportItems writes every item matching the filter as a CSV or XLSX file
+func (s *inventoryService) ExportItems(ctx context.Context, filter *inventorymodels.ItemFilter, format string, includeCosts bool, w io.Writer) error {
+	var columns []*itemColumn
+	for _, column := range itemColumns {
+		if includeCosts || !column.cost {
+			columns = append(columns, column)
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	record := func(item *inventorymodels.Item) []string {
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = column.get(item)
+		}
+		return values
+	}
+
+	switch format {
+	case inventorymodels.FileFormatCSV:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+
+		rows := 0
+		err := s.repo.ExportItems(ctx, filter, func(item *inventorymodels.Item) error {
+			if err := writer.Write(record(item)); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlushRows == 0 {
+				writer.Flush()
+				return writer.Error()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case inventorymodels.FileFormatXLSX:
+		workbook := excelize.NewFile()
+		defer workbook.Close()
+
+		sheet := workbook.GetSheetName(0)
+		stream, err := workbook.NewStreamWriter(sheet)
+		if err != nil {
+			return err
+		}
+
+		writeRow := func(row int, values []string) error {
+			cells := make([]interface{}, len(values))
+			for i, value := range values {
+				cells[i] = value
+			}
+			cell, err := excelize.CoordinatesToCellName(1, row)
+			if err != nil {
+				return err
+			}
+			return stream.SetRow(cell, cells)
+		}
+
+		if err := writeRow(1, header); err != nil {
+			return err
+		}
+		row := 1
+		err = s.repo.ExportItems(ctx, filter, func(item *inventorymodels.Item) error {
+			row++
+			return writeRow(row, record(item))
+		})
+		if err != nil {
+			return err
+		}
+		if err := stream.Flush(); err != nil {
+			return err
+		}
+		return workbook.Write(w)
+
+	default:
+		return ErrInvalidFileFormat
+	}
+}
+
+// importRow reads one row of an import into the item it creates or updates
+func (s *inventoryService) importRow(ctx context.Context, rowNumber int, record []string, columns []mappedColumn) (*inventorymodels.ItemImportRow, *inventorymodels.Item, error) {
+	row := &inventorymodels.ItemImportRow{Row: rowNumber, Action: inventorymodels.ImportActionCreate}
+	item := &inventorymodels.Item{IsActive: true}
+
+	for _, mapped := range columns {
+		if mapped.column.name == "part_number" && mapped.index < len(record) {
+			row.PartNumber = strings.TrimSpace(record[mapped.index])
+		}
+	}
+
+	if row.PartNumber != "" {
+		existing, err := s.repo.GetItemByPartNumber(ctx, row.PartNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing != nil {
+			copied := *existing
+			item = &copied
+			row.Action = inventorymodels.ImportActionUpdate
+			row.ItemID = existing.ItemID
+		}
+	}
+
+	for _, mapped := range columns {
+		if mapped.index >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[mapped.index])
+		if value == "" {
+			continue
+		}
+		if err := mapped.column.set(item, value); err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", mapped.column.name, err))
+		}
+	}
+
+	// New items need the references the items table requires
+	if row.Action == inventorymodels.ImportActionCreate {
+		required := map[string]int{
+			"category_id": item.CategoryID,
+			"make_id":     item.MakeID,
+			"model_id":    item.ModelID,
+			"submodel_id": item.SubmodelID,
+		}
+		for _, column := range itemColumns {
+			if id, ok := required[column.name]; ok && id <= 0 {
+				row.Errors = append(row.Errors, column.name+" is required")
+			}
+		}
+	}
+
+	if err := s.validateItem(item); err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+
+	if item.Barcode != nil && *item.Barcode != "" {
+		existing, err := s.repo.GetItemByBarcode(ctx, *item.Barcode)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing != nil && existing.ItemID != item.ItemID {
+			row.Errors = append(row.Errors, ErrDuplicateBarcode.Error())
+		}
+	}
+
+	return row, item, nil
+}
+
+// mapColumns works out which item field each column of the file holds
+func mapColumns(header []string, options *inventorymodels.ItemImportOptions) ([]mappedColumn, error) {
+	positions := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8BOM)))
+		if _, ok := positions[name]; !ok {
+			positions[name] = i
+		}
+	}
+
+	var columns []mappedColumn
+	hasPartNumber := false
+	for _, column := range itemColumns {
+		if column.set == nil || (options.IgnoreStock && column.name == "current_stock") {
+			continue
+		}
+
+		name := column.name
+		mapped, ok := options.Mapping[column.name]
+		if ok {
+			name = strings.ToLower(strings.TrimSpace(mapped))
+		}
+
+		index, found := positions[name]
+		if !found {
+			if ok {
+				return nil, fmt.Errorf("%w: %s", ErrMissingColumn, mapped)
+			}
+			continue
+		}
+		columns = append(columns, mappedColumn{index: index, column: column})
+		hasPartNumber = hasPartNumber || column.name == "part_number"
+	}
+
+	for field := range options.Mapping {
+		if column := findColumn(field); column == nil || column.set == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownImportField, field)
+		}
+	}
+
+	if !hasPartNumber {
+		return nil, ErrNoPartNumberColumn
+	}
+	return columns, nil
+}
+
+// readRecords reads every row of a CSV or XLSX file
+func readRecords(file io.Reader, format string) ([][]string, error) {
+	switch format {
+	case inventorymodels.FileFormatCSV:
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte(utf8BOM))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.Comma = csvDelimiter(data)
+		return reader.ReadAll()
+
+	case inventorymodels.FileFormatXLSX:
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer workbook.Close()
+
+		return workbook.GetRows(workbook.GetSheetName(workbook.GetActiveSheetIndex()))
+
+	default:
+		return nil, ErrInvalidFileFormat
+	}
+}
+
+// csvDelimiter guesses the delimiter from the header line. Spreadsheet
+// programs with a Turkish locale save CSV files with semicolons.
+func csvDelimiter(data []byte) rune {
+	header := data
+	if end := bytes.IndexByte(data, '\n'); end >= 0 {
+		header = data[:end]
+	}
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func findColumn(name string) *itemColumn {
+	for _, column := range itemColumns {
+		if column.name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// Column constructors
+func textColumn(name string, field func(item *inventorymodels.Item) *string) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get:  func(item *inventorymodels.Item) string { return *field(item) },
+		set: func(item *inventorymodels.Item, value string) error {
+			*field(item) = value
+			return nil
+		},
+	}
+}
+
+func optionalTextColumn(name string, field func(item *inventorymodels.Item) **string) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get:  func(item *inventorymodels.Item) string { return valueOf(*field(item)) },
+		set: func(item *inventorymodels.Item, value string) error {
+			*field(item) = &value
+			return nil
+		},
+	}
+}
+
+func intColumn(name string, field func(item *inventorymodels.Item) *int) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get:  func(item *inventorymodels.Item) string { return strconv.Itoa(*field(item)) },
+		set: func(item *inventorymodels.Item, value string) error {
+			n, err := parseInt(value)
+			if err != nil {
+				return err
+			}
+			*field(item) = n
+			return nil
+		},
+	}
+}
+
+func optionalIntColumn(name string, field func(item *inventorymodels.Item) **int) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get: func(item *inventorymodels.Item) string {
+			if n := *field(item); n != nil {
+				return strconv.Itoa(*n)
+			}
+			return ""
+		},
+		set: func(item *inventorymodels.Item, value string) error {
+			n, err := parseInt(value)
+			if err != nil {
+				return err
+			}
+			*field(item) = &n
+			return nil
+		},
+	}
+}
+
+func floatColumn(name string, field func(item *inventorymodels.Item) *float64) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get:  func(item *inventorymodels.Item) string { return formatFloat(*field(item)) },
+		set: func(item *inventorymodels.Item, value string) error {
+			f, err := parseFloat(value)
+			if err != nil {
+				return err
+			}
+			*field(item) = f
+			return nil
+		},
+	}
+}
+
+func optionalFloatColumn(name string, field func(item *inventorymodels.Item) **float64) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get: func(item *inventorymodels.Item) string {
+			if f := *field(item); f != nil {
+				return formatFloat(*f)
+			}
+			return ""
+		},
+		set: func(item *inventorymodels.Item, value string) error {
+			f, err := parseFloat(value)
+			if err != nil {
+				return err
+			}
+			*field(item) = &f
+			return nil
+		},
+	}
+}
+
+func boolColumn(name string, field func(item *inventorymodels.Item) *bool) *itemColumn {
+	return &itemColumn{
+		name: name,
+		get:  func(item *inventorymodels.Item) string { return strconv.FormatBool(*field(item)) },
+		set: func(item *inventorymodels.Item, value string) error {
+			switch strings.ToLower(value) {
+			case "true", "1", "yes", "evet":
+				*field(item) = true
+			case "false", "0", "no", "hayır":
+				*field(item) = false
+			default:
+				return errors.New("must be true or false")
+			}
+			return nil
+		},
+	}
+}
+
+func readOnlyColumn(name string, get func(item *inventorymodels.Item) string) *itemColumn {
+	return &itemColumn{name: name, get: get}
+}
+
+func costColumn(column *itemColumn) *itemColumn {
+	column.cost = true
+	return column
+}
+
+// Value helpers
+func parseInt(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.New("must be a whole number")
+	}
+	return n, nil
+}
+
+// parseFloat accepts both 12.50 and the Turkish 12,50
+func parseFloat(value string) (float64, error) {
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.New("must be a number")
+	}
+	return f, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+var (
+	ErrBarcodeRequired     = errors.New("barcode is required")
+	ErrInvalidBarcodeType  = errors.New("barcode type must be internal, manufacturer or supplier")
+	ErrInvalidPackQuantity = errors.New("pack quantity must be at least 1")
+	ErrInvalidBarcodeID    = errors.New("invalid barcode ID")
+	ErrItemBarcodeNotFound = errors.New("barcode not found")
+	ErrOwnBarcode          = errors.New("the item's own barcode cannot be removed; change the item's barcode instead")
+)
+
+// Item barcode operations
+func (s *inventoryService) GetItemBarcodes(ctx context.Context, itemID int) ([]*inventorymodels.ItemBarcode, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	return s.repo.GetItemBarcodes(ctx, itemID)
+}
+
+// AddItemBarcode adds another barcode an item can be scanned by. A barcode
+// belongs to one item only.
+func (s *inventoryService) AddItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) (int, error) {
+	if barcode.ItemID <= 0 {
+		return 0, ErrInvalidItemID
+	}
+	barcode.Barcode = strings.TrimSpace(barcode.Barcode)
+	if barcode.Barcode == "" {
+		return 0, ErrBarcodeRequired
+	}
+	if err := validateItemBarcode(barcode); err != nil {
+		return 0, err
+	}
+
+	item, err := s.repo.GetItemByID(ctx, barcode.ItemID)
+	if err != nil {
+		return 0, err
+	}
+	if item == nil {
+		return 0, ErrItemNotFound
+	}
+
+	existing, err := s.repo.GetItemByBarcode(ctx, barcode.Barcode)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, ErrDuplicateBarcode
+	}
+
+	return s.repo.AddItemBarcode(ctx, barcode)
+}
+
+func (s *inventoryService) UpdateItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) error {
+	if barcode.ItemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if barcode.BarcodeID <= 0 {
+		return ErrInvalidBarcodeID
+	}
+	if err := validateItemBarcode(barcode); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetItemBarcode(ctx, barcode.ItemID, barcode.BarcodeID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrItemBarcodeNotFound
+	}
+
+	return s.repo.UpdateItemBarcode(ctx, barcode)
+}
+
+// RemoveItemBarcode removes a barcode from an item. The barcode in the
+// item's own record stays for as long as the item has it.
+func (s *inventoryService) RemoveItemBarcode(ctx context.Context, itemID, barcodeID int) error {
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if barcodeID <= 0 {
+		return ErrInvalidBarcodeID
+	}
+
+	barcode, err := s.repo.GetItemBarcode(ctx, itemID, barcodeID)
+	if err != nil {
+		return err
+	}
+	if barcode == nil {
+		return ErrItemBarcodeNotFound
+	}
+
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item != nil && item.Barcode != nil && *item.Barcode == barcode.Barcode {
+		return ErrOwnBarcode
+	}
+
+	return s.repo.RemoveItemBarcode(ctx, itemID, barcodeID)
+}
+
+// validateItemBarcode checks the type and pack quantity of a barcode,
+// defaulting them to a single unit under the manufacturer's code
+func validateItemBarcode(barcode *inventorymodels.ItemBarcode) error {
+	switch barcode.BarcodeType {
+	case "":
+		barcode.BarcodeType = inventorymodels.BarcodeTypeManufacturer
+	case inventorymodels.BarcodeTypeInternal, inventorymodels.BarcodeTypeManufacturer, inventorymodels.BarcodeTypeSupplier:
+	default:
+		return ErrInvalidBarcodeType
+	}
+
+	if barcode.PackQuantity == 0 {
+		barcode.PackQuantity = 1
+	}
+	if barcode.PackQuantity < 1 {
+		return ErrInvalidPackQuantity
+	}
+	return nil
+}
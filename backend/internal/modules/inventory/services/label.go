@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+	"github.com/hsrvms/autoparts/pkg/labels"
+)
+
+// maxLabels is the most labels one request may print
+const maxLabels = 5000
+
+var (
+	ErrNoLabelItems         = errors.New("at least one item is required")
+	ErrInvalidLabelQuantity = errors.New("label quantity must be at least 1")
+	ErrTooManyLabels        = fmt.Errorf("at most %d labels can be printed at once", maxLabels)
+	ErrUnknownLabelTemplate = errors.New("label template must be a4-3x8 or roll-58mm")
+	ErrInvalidLabelFormat   = errors.New("label format must be pdf or zpl")
+	ErrInvalidPrinterDPI    = labels.ErrInvalidDPI
+	ErrItemHasNoBarcode     = errors.New("item has no barcode")
+)
+
+// Label operations
+
+// PrintLabels renders shelf labels for items, as many of each as asked, as
+// a PDF of label sheets or as ZPL for a thermal printer. Every item is
+// checked before anything is written. Labels show where the items are kept
+// in the warehouse asked for.
+func (s *inventoryService) PrintLabels(ctx context.Context, request *inventorymodels.LabelRequest, w io.Writer) error {
+	if request.Template == "" {
+		request.Template = labels.TemplateA4
+	}
+	if request.Format == "" {
+		request.Format = inventorymodels.LabelFormatPDF
+	}
+	if request.DPI == 0 {
+		request.DPI = labels.DefaultDPI
+	}
+
+	template := labels.FindTemplate(request.Template)
+	if template == nil {
+		return ErrUnknownLabelTemplate
+	}
+	if request.Format != inventorymodels.LabelFormatPDF && request.Format != inventorymodels.LabelFormatZPL {
+		return ErrInvalidLabelFormat
+	}
+	if len(request.Items) == 0 {
+		return ErrNoLabelItems
+	}
+
+	total := 0
+	for _, line := range request.Items {
+		if line.ItemID <= 0 {
+			return ErrInvalidItemID
+		}
+		if line.Quantity < 1 {
+			return ErrInvalidLabelQuantity
+		}
+		total += line.Quantity
+	}
+	if total > maxLabels {
+		return ErrTooManyLabels
+	}
+	if request.WarehouseID != nil && *request.WarehouseID <= 0 {
+		return ErrInvalidWarehouseID
+	}
+
+	items := make([]*inventorymodels.Item, len(request.Items))
+	ids := make([]int, len(request.Items))
+	for i, line := range request.Items {
+		item, err := s.repo.GetItemByID(ctx, line.ItemID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("%w: %d", ErrItemNotFound, line.ItemID)
+		}
+		if item.Barcode == nil || *item.Barcode == "" {
+			return fmt.Errorf("%w: %s", ErrItemHasNoBarcode, item.PartNumber)
+		}
+		items[i] = item
+		ids[i] = item.ItemID
+	}
+
+	locations, err := s.repo.GetItemLocations(ctx, request.WarehouseID, ids)
+	if err != nil {
+		return err
+	}
+
+	sheet := make([]labels.Label, 0, len(request.Items))
+	for i, line := range request.Items {
+		sheet = append(sheet, itemLabel(items[i], locations[items[i].ItemID], line.Quantity))
+	}
+
+	if request.Format == inventorymodels.LabelFormatZPL {
+		return labels.WriteZPL(w, template, sheet, request.DPI)
+	}
+	return labels.WritePDF(w, template, sheet)
+}
+
+// Helper functions
+
+// itemLabel returns what goes on the shelf label of an item kept at location
+func itemLabel(item *inventorymodels.Item, location string, copies int) labels.Label {
+	label := labels.Label{
+		Barcode:    *item.Barcode,
+		PartNumber: item.PartNumber,
+		Price:      formatLabelPrice(item.SellPrice),
+		Location:   location,
+		Copies:     copies,
+	}
+	if item.Description != nil {
+		label.Description = *item.Description
+	}
+	return label
+}
+
+// formatLabelPrice writes a price the Turkish way, with a decimal comma
+func formatLabelPrice(price float64) string {
+	return strings.Replace(strconv.FormatFloat(price, 'f', 2, 64), ".", ",", 1) + " TL"
+}
@@ -4,9 +4,12 @@ package services
 import (
 	"context"
 	"errors"
+	"io"
+	"strings"
 
 	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
 	"github.com/hsrvms/autoparts/internal/modules/inventory/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 var (
@@ -18,24 +21,62 @@ var (
 	ErrCompatibilityExists = errors.New("compatibility already exists")
 	ErrInvalidPrice        = errors.New("price must be greater than 0")
 	ErrInvalidStock        = errors.New("stock cannot be negative")
+	ErrEmptySearch         = errors.New("search term is required")
+	ErrInvalidWarehouseID  = errors.New("invalid warehouse ID")
 )
 
+// ItemSorting lists the fields items can be sorted by
+var ItemSorting = repositories.ItemSorting
+
+// ItemSearchSorting lists the fields search results can be sorted by
+var ItemSearchSorting = repositories.ItemSearchSorting
+
 type InventoryService interface {
 	// Item operations
-	GetItems(ctx context.Context, filter *inventorymodels.ItemFilter) ([]*inventorymodels.Item, error)
+	GetItems(ctx context.Context, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.Item, error)
+	SearchItems(ctx context.Context, term string, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.ItemSearchResult, error)
 	GetItemByID(ctx context.Context, id int) (*inventorymodels.Item, error)
 	GetItemByPartNumber(ctx context.Context, partNumber string) (*inventorymodels.Item, error)
 	GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error)
 	CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error)
 	UpdateItem(ctx context.Context, item *inventorymodels.Item) error
 	DeleteItem(ctx context.Context, id int) error
-	GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error)
+	GetLowStockItems(ctx context.Context, warehouseID *int) ([]*inventorymodels.Item, error)
+	ImportItems(ctx context.Context, file io.Reader, options *inventorymodels.ItemImportOptions) (*inventorymodels.ItemImportResult, error)
+	ExportItems(ctx context.Context, filter *inventorymodels.ItemFilter, format string, includeCosts bool, w io.Writer) error
 
 	// Compatibility operations
 	GetCompatibilities(ctx context.Context, itemID int) ([]*inventorymodels.Compatibility, error)
 	AddCompatibility(ctx context.Context, compatibility *inventorymodels.Compatibility) (int, error)
 	RemoveCompatibility(ctx context.Context, itemID, submodelID int) error
 	GetCompatibleItems(ctx context.Context, submodelID int) ([]*inventorymodels.Item, error)
+	AssignCompatibilities(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, error)
+	RemoveCompatibilities(ctx context.Context, itemID int, request *inventorymodels.BulkCompatibilityRequest) (*inventorymodels.BulkCompatibilityResult, error)
+	CopyCompatibilities(ctx context.Context, itemID int, request *inventorymodels.CopyCompatibilitiesRequest) (*inventorymodels.BulkCompatibilityResult, error)
+	FindParts(ctx context.Context, filter *inventorymodels.PartFinderFilter) (*inventorymodels.PartFinderResult, error)
+
+	// Cross-reference operations
+	GetCrossReferences(ctx context.Context, itemID int) ([]*inventorymodels.CrossReference, error)
+	AddCrossReference(ctx context.Context, reference *inventorymodels.CrossReference) (int, error)
+	RemoveCrossReference(ctx context.Context, itemID, referenceID int) error
+	FindInterchangeableItems(ctx context.Context, number string, inStock bool) ([]*inventorymodels.InterchangeItem, error)
+	ImportCrossReferences(ctx context.Context, file io.Reader, options *inventorymodels.ItemImportOptions) (*inventorymodels.CrossReferenceImportResult, error)
+
+	// Supersession operations
+	SetSupersession(ctx context.Context, itemID int, supersession *inventorymodels.Supersession) (*inventorymodels.Item, error)
+	RemoveSupersession(ctx context.Context, itemID int) error
+
+	// Barcode operations
+	GenerateBarcode(ctx context.Context, format string) (string, error)
+
+	// Item barcode operations
+	GetItemBarcodes(ctx context.Context, itemID int) ([]*inventorymodels.ItemBarcode, error)
+	AddItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) (int, error)
+	UpdateItemBarcode(ctx context.Context, barcode *inventorymodels.ItemBarcode) error
+	RemoveItemBarcode(ctx context.Context, itemID, barcodeID int) error
+
+	// Label operations
+	PrintLabels(ctx context.Context, request *inventorymodels.LabelRequest, w io.Writer) error
 }
 
 type inventoryService struct {
@@ -43,16 +84,25 @@ type inventoryService struct {
 	barcodeService BarcodeService
 }
 
-func NewInventoryService(repo repositories.InventoryRepository) InventoryService {
+func NewInventoryService(repo repositories.InventoryRepository, barcodeService BarcodeService) InventoryService {
 	return &inventoryService{
 		repo:           repo,
-		barcodeService: NewBarcodeService(),
+		barcodeService: barcodeService,
 	}
 }
 
 // Item operations
-func (s *inventoryService) GetItems(ctx context.Context, filter *inventorymodels.ItemFilter) ([]*inventorymodels.Item, error) {
-	return s.repo.GetItems(ctx, filter)
+func (s *inventoryService) GetItems(ctx context.Context, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.Item, error) {
+	return s.repo.GetItems(ctx, filter, page)
+}
+
+func (s *inventoryService) SearchItems(ctx context.Context, term string, filter *inventorymodels.ItemFilter, page *pagination.Page) ([]*inventorymodels.ItemSearchResult, error) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return nil, ErrEmptySearch
+	}
+
+	return s.repo.SearchItems(ctx, term, filter, page)
 }
 
 func (s *inventoryService) GetItemByID(ctx context.Context, id int) (*inventorymodels.Item, error) {
@@ -76,7 +126,12 @@ func (s *inventoryService) GetItemByPartNumber(ctx context.Context, partNumber s
 		return nil, errors.New("part number is required")
 	}
 
-	return s.repo.GetItemByPartNumber(ctx, partNumber)
+	item, err := s.repo.GetItemByPartNumber(ctx, partNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.currentItem(ctx, item)
 }
 
 func (s *inventoryService) GetItemByBarcode(ctx context.Context, barcode string) (*inventorymodels.Item, error) {
@@ -84,7 +139,18 @@ func (s *inventoryService) GetItemByBarcode(ctx context.Context, barcode string)
 		return nil, errors.New("barcode is required")
 	}
 
-	return s.repo.GetItemByBarcode(ctx, barcode)
+	item, err := s.repo.GetItemByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentItem(ctx, item)
+	if err != nil || current == nil {
+		return current, err
+	}
+	// The pack the barcode stands for still holds for the successor
+	current.ScannedBarcode = item.ScannedBarcode
+	return current, nil
 }
 
 func (s *inventoryService) CreateItem(ctx context.Context, item *inventorymodels.Item) (int, error) {
@@ -173,8 +239,11 @@ func (s *inventoryService) DeleteItem(ctx context.Context, id int) error {
 	return s.repo.DeleteItem(ctx, id)
 }
 
-func (s *inventoryService) GetLowStockItems(ctx context.Context) ([]*inventorymodels.Item, error) {
-	return s.repo.GetLowStockItems(ctx)
+func (s *inventoryService) GetLowStockItems(ctx context.Context, warehouseID *int) ([]*inventorymodels.Item, error) {
+	if warehouseID != nil && *warehouseID <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+	return s.repo.GetLowStockItems(ctx, warehouseID)
 }
 
 // Compatibility operations
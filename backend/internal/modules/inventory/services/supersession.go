@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	inventorymodels "github.com/hsrvms/autoparts/internal/modules/inventory/models"
+)
+
+var (
+	ErrSupersededBySelf  = errors.New("an item cannot supersede itself")
+	ErrSuccessorNotFound = errors.New("successor item not found")
+	ErrSupersessionCycle = errors.New("the successor is already superseded, directly or not, by this item")
+)
+
+// Supersession operations
+
+// SetSupersession marks an item as replaced by another part from a date,
+// today unless given. Lookups by part number or barcode then lead to the
+// successor, and its stock counts the stock left under the old number.
+func (s *inventoryService) SetSupersession(ctx context.Context, itemID int, supersession *inventorymodels.Supersession) (*inventorymodels.Item, error) {
+	if itemID <= 0 || supersession.SupersededBy <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if supersession.SupersededBy == itemID {
+		return nil, ErrSupersededBySelf
+	}
+
+	item, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	// Walk the successor's chain, future supersessions included, so it can
+	// never lead back to the item
+	seen := map[int]bool{}
+	for id := &supersession.SupersededBy; id != nil && !seen[*id]; {
+		if *id == itemID {
+			return nil, ErrSupersessionCycle
+		}
+		seen[*id] = true
+
+		successor, err := s.repo.GetItemByID(ctx, *id)
+		if err != nil {
+			return nil, err
+		}
+		if successor == nil {
+			return nil, ErrSuccessorNotFound
+		}
+		id = successor.SupersededBy
+	}
+
+	on := supersession.SupersededOn
+	if on == nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		on = &today
+	}
+
+	if err := s.repo.SetSupersession(ctx, itemID, &supersession.SupersededBy, on); err != nil {
+		return nil, err
+	}
+
+	item.SupersededBy = &supersession.SupersededBy
+	item.SupersededOn = on
+	return item, nil
+}
+
+func (s *inventoryService) RemoveSupersession(ctx context.Context, itemID int) error {
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+
+	existing, err := s.repo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrItemNotFound
+	}
+
+	return s.repo.SetSupersession(ctx, itemID, nil, nil)
+}
+
+// Helper functions
+
+// currentItem returns the part that replaces a looked up item today, noting
+// the part number that was looked up when it is a different item
+func (s *inventoryService) currentItem(ctx context.Context, item *inventorymodels.Item) (*inventorymodels.Item, error) {
+	if item == nil || item.SupersededBy == nil {
+		return item, nil
+	}
+
+	current, err := s.repo.GetCurrentItem(ctx, item.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.ItemID == item.ItemID {
+		return item, nil
+	}
+
+	current.ResolvedFrom = &item.PartNumber
+	return current, nil
+}
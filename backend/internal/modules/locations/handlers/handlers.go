@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	locationmodels "github.com/hsrvms/autoparts/internal/modules/locations/models"
+	"github.com/hsrvms/autoparts/internal/modules/locations/services"
+	"github.com/labstack/echo/v4"
+)
+
+type LocationHandler struct {
+	service services.LocationService
+}
+
+func NewLocationHandler(service services.LocationService) *LocationHandler {
+	return &LocationHandler{
+		service: service,
+	}
+}
+
+// GetLocations handles listing of locations in walking order
+func (h *LocationHandler) GetLocations(c echo.Context) error {
+	filter := &locationmodels.LocationFilter{}
+
+	// Parse query parameters
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
+	if parentID := c.QueryParam("parent_id"); parentID != "" {
+		id, err := strconv.Atoi(parentID)
+		if err == nil {
+			filter.ParentID = &id
+		}
+	}
+
+	if level := c.QueryParam("level"); level != "" {
+		filter.Level = &level
+	}
+
+	if includeInactive, err := strconv.ParseBool(c.QueryParam("include_inactive")); err == nil {
+		filter.IncludeInactive = includeInactive
+	}
+
+	ctx := c.Request().Context()
+	locations, err := h.service.GetLocations(ctx, filter)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLevel:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, locations)
+}
+
+// GetLocationByID handles retrieval of a single location
+func (h *LocationHandler) GetLocationByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	ctx := c.Request().Context()
+	location, err := h.service.GetLocationByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLocationID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrLocationNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, location)
+}
+
+// CreateLocation handles creation of a new location
+func (h *LocationHandler) CreateLocation(c echo.Context) error {
+	location := &locationmodels.Location{IsActive: true}
+	if err := c.Bind(location); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateLocation(ctx, location)
+	if err != nil {
+		return locationError(err)
+	}
+
+	created, err := h.service.GetLocationByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateLocation handles updating an existing location
+func (h *LocationHandler) UpdateLocation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	location := &locationmodels.Location{IsActive: true}
+	if err := c.Bind(location); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	location.LocationID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateLocation(ctx, location); err != nil {
+		return locationError(err)
+	}
+
+	updated, err := h.service.GetLocationByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteLocation handles deletion of an empty location
+func (h *LocationHandler) DeleteLocation(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	ctx := c.Request().Context()
+	err = h.service.DeleteLocation(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLocationID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrLocationNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrLocationInUse:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetContents handles listing of the items kept at or under a location
+func (h *LocationHandler) GetContents(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	ctx := c.Request().Context()
+	contents, err := h.service.GetContents(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidLocationID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrLocationNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, contents)
+}
+
+// AssignItem handles putting an item in a bin
+func (h *LocationHandler) AssignItem(c echo.Context) error {
+	binID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.AssignItem(ctx, binID, itemID); err != nil {
+		return locationError(err)
+	}
+
+	contents, err := h.service.GetContents(ctx, binID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, contents)
+}
+
+// UnassignItem handles taking an item out of a bin
+func (h *LocationHandler) UnassignItem(c echo.Context) error {
+	binID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid location ID")
+	}
+
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.UnassignItem(ctx, binID, itemID); err != nil {
+		return locationError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetPutawaySuggestions handles suggesting bins for stock arriving at a
+// warehouse
+func (h *LocationHandler) GetPutawaySuggestions(c echo.Context) error {
+	warehouseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	itemID, err := strconv.Atoi(c.QueryParam("item_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	quantity := 1
+	if value := c.QueryParam("quantity"); value != "" {
+		if quantity, err = strconv.Atoi(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid quantity")
+		}
+	}
+
+	ctx := c.Request().Context()
+	suggestions, err := h.service.GetPutawaySuggestions(ctx, warehouseID, itemID, quantity)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID, services.ErrInvalidItemID,
+			services.ErrInvalidQuantity:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, suggestions)
+}
+
+// GetSalePickList handles the pick list of a sale transaction
+func (h *LocationHandler) GetSalePickList(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid sale transaction ID")
+	}
+
+	ctx := c.Request().Context()
+	list, err := h.service.GetSalePickList(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidSaleID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrSaleNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// GetTransferPickList handles the pick list of a stock transfer
+func (h *LocationHandler) GetTransferPickList(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	list, err := h.service.GetTransferPickList(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTransferID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrTransferNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+// locationError maps the errors of changing locations and what they hold
+// to HTTP errors
+func locationError(err error) error {
+	switch err {
+	case services.ErrInvalidLocationID, services.ErrInvalidWarehouseID,
+		services.ErrInvalidItemID, services.ErrInvalidLevel,
+		services.ErrCodeRequired, services.ErrInvalidCapacity,
+		services.ErrInvalidSortOrder, services.ErrInvalidParent,
+		services.ErrNotABin:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrLocationNotFound, services.ErrWarehouseNotFound,
+		services.ErrItemNotFound, services.ErrItemNotInBin:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrDuplicateCode, services.ErrInactiveLocation:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrOverCapacity, services.ErrCapacityBelowStock:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
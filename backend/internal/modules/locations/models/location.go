@@ -0,0 +1,67 @@
+package locationmodels
+
+import "time"
+
+// Levels of the location hierarchy
+const (
+	LevelFloor    = "floor"
+	LevelCorridor = "corridor"
+	LevelAisle    = "aisle"
+	LevelShelf    = "shelf"
+	LevelBin      = "bin"
+)
+
+// Levels lists the levels from the top of the hierarchy down. Every
+// location but a floor sits in a location of the level above it.
+var Levels = []string{LevelFloor, LevelCorridor, LevelAisle, LevelShelf, LevelBin}
+
+// Location is a floor, corridor, aisle, shelf or bin of a warehouse.
+// Capacity is in units and nil when there is no limit. Locations under the
+// same parent are walked in SortOrder, then by code.
+type Location struct {
+	LocationID  int       `json:"location_id" db:"location_id"`
+	WarehouseID int       `json:"warehouse_id" db:"warehouse_id"`
+	ParentID    *int      `json:"parent_id,omitempty" db:"parent_id"`
+	Level       string    `json:"level" db:"level"`
+	Code        string    `json:"code" db:"code"`
+	Capacity    *int      `json:"capacity,omitempty" db:"capacity"`
+	SortOrder   int       `json:"sort_order" db:"sort_order"`
+	IsActive    bool      `json:"is_active" db:"is_active"`
+	Notes       *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	// Label is the codes of the path from the floor down, e.g. 1-A-03-2-B
+	Label string `json:"label" db:"label"`
+	// Stored is the number of units in the bins at or under the location
+	Stored int `json:"stored" db:"stored"`
+}
+
+type LocationFilter struct {
+	WarehouseID     *int    `query:"warehouse_id"`
+	ParentID        *int    `query:"parent_id"`
+	Level           *string `query:"level"`
+	IncludeInactive bool    `query:"include_inactive"`
+}
+
+// BinContent is the stock of an item kept in a bin
+type BinContent struct {
+	BinID           int     `json:"bin_id" db:"bin_id"`
+	BinLabel        string  `json:"bin_label" db:"bin_label"`
+	ItemID          int     `json:"item_id" db:"item_id"`
+	ItemPartNumber  string  `json:"item_part_number" db:"item_part_number"`
+	ItemDescription *string `json:"item_description,omitempty" db:"item_description"`
+	Quantity        int     `json:"quantity" db:"quantity"`
+}
+
+// PutawaySuggestion is a bin with room for stock being put away. Free is
+// nil for bins without a capacity.
+type PutawaySuggestion struct {
+	BinID     int    `json:"bin_id" db:"bin_id"`
+	Label     string `json:"label" db:"label"`
+	Capacity  *int   `json:"capacity,omitempty" db:"capacity"`
+	Stored    int    `json:"stored" db:"stored"`
+	Free      *int   `json:"free,omitempty" db:"free"`
+	HoldsItem bool   `json:"holds_item" db:"holds_item"`
+}
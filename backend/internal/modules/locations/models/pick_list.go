@@ -0,0 +1,29 @@
+package locationmodels
+
+const (
+	PickListSale     = "sale"
+	PickListTransfer = "transfer"
+)
+
+// PickList is what to take off the shelves for a sale or transfer, in the
+// order the bins are walked past. Items without a bin come last.
+type PickList struct {
+	ReferenceType string          `json:"reference_type"`
+	ReferenceID   int             `json:"reference_id"`
+	Number        string          `json:"number"`
+	WarehouseID   int             `json:"warehouse_id"`
+	Lines         []*PickListLine `json:"lines"`
+}
+
+// PickListLine is one item to pick. Available is what the warehouse holds
+// of it now.
+type PickListLine struct {
+	Sequence        int     `json:"sequence"`
+	ItemID          int     `json:"item_id" db:"item_id"`
+	ItemPartNumber  string  `json:"item_part_number" db:"item_part_number"`
+	ItemDescription *string `json:"item_description,omitempty" db:"item_description"`
+	Quantity        int     `json:"quantity" db:"quantity"`
+	Available       int     `json:"available" db:"available"`
+	BinID           *int    `json:"bin_id,omitempty" db:"bin_id"`
+	BinLabel        *string `json:"bin_label,omitempty" db:"bin_label"`
+}
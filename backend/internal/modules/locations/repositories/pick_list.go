@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	locationmodels "github.com/hsrvms/autoparts/internal/modules/locations/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// pickLinesOrder walks the bins in order and leaves items without a bin to
+// the end
+const pickLinesOrder = ` ORDER BY p.walk_key NULLS LAST, i.part_number`
+
+func (r *PostgresLocationRepository) GetSalePickList(ctx context.Context, transactionID int) (*locationmodels.PickList, error) {
+	list := &locationmodels.PickList{
+		ReferenceType: locationmodels.PickListSale,
+		ReferenceID:   transactionID,
+	}
+
+	err := r.db.Pool.QueryRow(ctx, `
+        SELECT transaction_number, warehouse_id FROM sale_transactions
+        WHERE transaction_id = $1
+    `, transactionID).Scan(&list.Number, &list.WarehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// A transaction can sell the same item on more than one line
+	query := `
+        SELECT s.item_id, i.part_number, i.description, SUM(s.quantity)::INTEGER,
+            COALESCE(ws.quantity, 0), ws.bin_id, p.label
+        FROM sales s
+        JOIN items i ON i.item_id = s.item_id
+        LEFT JOIN warehouse_stock ws ON ws.item_id = s.item_id AND ws.warehouse_id = $2
+        LEFT JOIN bin_location_paths p ON p.location_id = ws.bin_id
+        WHERE s.transaction_id = $1
+        GROUP BY s.item_id, i.part_number, i.description, ws.quantity, ws.bin_id, p.label, p.walk_key
+    ` + pickLinesOrder
+
+	list.Lines, err = r.queryPickLines(ctx, query, transactionID, list.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (r *PostgresLocationRepository) GetTransferPickList(ctx context.Context, transferID int) (*locationmodels.PickList, error) {
+	list := &locationmodels.PickList{
+		ReferenceType: locationmodels.PickListTransfer,
+		ReferenceID:   transferID,
+	}
+
+	err := r.db.Pool.QueryRow(ctx, `
+        SELECT transfer_number, from_warehouse_id FROM stock_transfers
+        WHERE transfer_id = $1
+    `, transferID).Scan(&list.Number, &list.WarehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	query := `
+        SELECT l.item_id, i.part_number, i.description, l.quantity,
+            COALESCE(ws.quantity, 0), ws.bin_id, p.label
+        FROM stock_transfer_lines l
+        JOIN items i ON i.item_id = l.item_id
+        LEFT JOIN warehouse_stock ws ON ws.item_id = l.item_id AND ws.warehouse_id = $2
+        LEFT JOIN bin_location_paths p ON p.location_id = ws.bin_id
+        WHERE l.transfer_id = $1
+    ` + pickLinesOrder
+
+	list.Lines, err = r.queryPickLines(ctx, query, transferID, list.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// queryPickLines reads the lines of a pick list, numbering them in the
+// order they are picked
+func (r *PostgresLocationRepository) queryPickLines(ctx context.Context, query string, params ...interface{}) ([]*locationmodels.PickListLine, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []*locationmodels.PickListLine{}
+	for rows.Next() {
+		line := &locationmodels.PickListLine{Sequence: len(lines) + 1}
+		err := rows.Scan(
+			&line.ItemID,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+			&line.Quantity,
+			&line.Available,
+			&line.BinID,
+			&line.BinLabel,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
@@ -0,0 +1,476 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	locationmodels "github.com/hsrvms/autoparts/internal/modules/locations/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint failure
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is the Postgres error code for a missing or still
+// referenced row
+const foreignKeyViolation = "23503"
+
+// putawaySuggestions is the most bins suggested for putting stock away
+const putawaySuggestions = 10
+
+const locationSelectQuery = `
+        SELECT
+            l.location_id, l.warehouse_id, l.parent_id, l.level, l.code,
+            l.capacity, l.sort_order, l.is_active, l.notes, l.created_at,
+            l.updated_at, p.label,
+            COALESCE((
+                SELECT SUM(ws.quantity)
+                FROM warehouse_stock ws
+                JOIN bin_location_paths bp ON bp.location_id = ws.bin_id
+                WHERE l.location_id = ANY(bp.ids)
+            ), 0) AS stored
+        FROM bin_locations l
+        JOIN bin_location_paths p ON p.location_id = l.location_id
+    `
+
+type PostgresLocationRepository struct {
+	db *db.Database
+}
+
+func NewPostgresLocationRepository(db *db.Database) LocationRepository {
+	return &PostgresLocationRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresLocationRepository) GetLocations(ctx context.Context, filter *locationmodels.LocationFilter) ([]*locationmodels.Location, error) {
+	query := locationSelectQuery + " WHERE 1=1"
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("l.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		if filter.ParentID != nil {
+			conditions = append(conditions, fmt.Sprintf("l.parent_id = $%d", paramCount))
+			params = append(params, *filter.ParentID)
+			paramCount++
+		}
+
+		if filter.Level != nil {
+			conditions = append(conditions, fmt.Sprintf("l.level = $%d", paramCount))
+			params = append(params, *filter.Level)
+			paramCount++
+		}
+
+		if !filter.IncludeInactive {
+			conditions = append(conditions, "l.is_active")
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY l.warehouse_id, p.walk_key"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*locationmodels.Location
+	for rows.Next() {
+		location, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+func (r *PostgresLocationRepository) GetLocationByID(ctx context.Context, id int) (*locationmodels.Location, error) {
+	query := locationSelectQuery + " WHERE l.location_id = $1"
+
+	location, err := scanLocation(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (r *PostgresLocationRepository) CreateLocation(ctx context.Context, location *locationmodels.Location) (int, error) {
+	query := `
+        INSERT INTO bin_locations (
+            warehouse_id, parent_id, level, code, capacity, sort_order, is_active, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING location_id
+    `
+
+	var id int
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		location.WarehouseID,
+		location.ParentID,
+		location.Level,
+		location.Code,
+		location.Capacity,
+		location.SortOrder,
+		location.IsActive,
+		location.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, locationError(err)
+	}
+
+	return id, nil
+}
+
+// UpdateLocation changes a location and rewrites the free-text location of
+// the stock kept under it, as its path may have changed
+func (r *PostgresLocationRepository) UpdateLocation(ctx context.Context, location *locationmodels.Location) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE bin_locations
+        SET parent_id = $1, code = $2, capacity = $3, sort_order = $4,
+            is_active = $5, notes = $6
+        WHERE location_id = $7
+    `
+
+	result, err := tx.Exec(
+		ctx, query,
+		location.ParentID,
+		location.Code,
+		location.Capacity,
+		location.SortOrder,
+		location.IsActive,
+		location.Notes,
+		location.LocationID,
+	)
+	if err != nil {
+		return locationError(err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrLocationNotFound
+	}
+
+	if err = syncBinText(ctx, tx, location.LocationID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresLocationRepository) DeleteLocation(ctx context.Context, id int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM bin_locations WHERE location_id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return ErrLocationInUse
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// GetContents lists the items kept in a bin, or in every bin under a
+// location of a higher level, in walking order
+func (r *PostgresLocationRepository) GetContents(ctx context.Context, id int) ([]*locationmodels.BinContent, error) {
+	query := `
+        SELECT ws.bin_id, bp.label, ws.item_id, i.part_number, i.description, ws.quantity
+        FROM warehouse_stock ws
+        JOIN bin_location_paths bp ON bp.location_id = ws.bin_id
+        JOIN items i ON i.item_id = ws.item_id
+        WHERE $1 = ANY(bp.ids)
+        ORDER BY bp.walk_key, i.part_number
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contents := []*locationmodels.BinContent{}
+	for rows.Next() {
+		content := &locationmodels.BinContent{}
+		err := rows.Scan(
+			&content.BinID,
+			&content.BinLabel,
+			&content.ItemID,
+			&content.ItemPartNumber,
+			&content.ItemDescription,
+			&content.Quantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, content)
+	}
+
+	return contents, rows.Err()
+}
+
+func (r *PostgresLocationRepository) AssignItem(ctx context.Context, binID, itemID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the bin keeps two items from taking its last room at once
+	var warehouseID int
+	var capacity *int
+	err = tx.QueryRow(ctx, `
+        SELECT warehouse_id, capacity FROM bin_locations
+        WHERE location_id = $1
+        FOR UPDATE
+    `, binID).Scan(&warehouseID, &capacity)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrLocationNotFound
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO warehouse_stock (warehouse_id, item_id) VALUES ($1, $2)
+        ON CONFLICT (warehouse_id, item_id) DO NOTHING
+    `, warehouseID, itemID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return ErrItemNotFound
+		}
+		return err
+	}
+
+	var quantity int
+	err = tx.QueryRow(ctx, `
+        SELECT quantity FROM warehouse_stock
+        WHERE warehouse_id = $1 AND item_id = $2
+        FOR UPDATE
+    `, warehouseID, itemID).Scan(&quantity)
+	if err != nil {
+		return err
+	}
+
+	if capacity != nil {
+		var stored int
+		err = tx.QueryRow(ctx, `
+            SELECT COALESCE(SUM(quantity), 0) FROM warehouse_stock
+            WHERE bin_id = $1 AND item_id <> $2
+        `, binID, itemID).Scan(&stored)
+		if err != nil {
+			return err
+		}
+		if stored+quantity > *capacity {
+			return ErrOverCapacity
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE warehouse_stock SET bin_id = $3
+        WHERE warehouse_id = $1 AND item_id = $2
+    `, warehouseID, itemID, binID)
+	if err != nil {
+		return err
+	}
+
+	if err = syncBinText(ctx, tx, binID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UnassignItem takes an item out of a bin, clearing its free-text location
+// along with it
+func (r *PostgresLocationRepository) UnassignItem(ctx context.Context, binID, itemID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var warehouseID int
+	err = tx.QueryRow(ctx, `
+        UPDATE warehouse_stock
+        SET bin_id = NULL, location_floor = NULL, location_corridor = NULL,
+            location_aisle = NULL, location_shelf = NULL, location_bin = NULL
+        WHERE bin_id = $1 AND item_id = $2
+        RETURNING warehouse_id
+    `, binID, itemID).Scan(&warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotInBin
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE items
+        SET location_floor = NULL, location_corridor = NULL, location_aisle = NULL,
+            location_shelf = NULL, location_bin = NULL
+        WHERE item_id = $1
+            AND EXISTS (SELECT 1 FROM warehouses WHERE warehouse_id = $2 AND is_default)
+    `, itemID, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPutawaySuggestions lists the active bins of a warehouse with room for
+// quantity more units: the bin the item is already kept in first, then
+// empty bins, then the rest, each in walking order
+func (r *PostgresLocationRepository) GetPutawaySuggestions(ctx context.Context, warehouseID, itemID, quantity int) ([]*locationmodels.PutawaySuggestion, error) {
+	query := `
+        SELECT b.location_id, p.label, b.capacity, s.stored,
+            b.capacity - s.stored AS free,
+            EXISTS (
+                SELECT 1 FROM warehouse_stock ws
+                WHERE ws.bin_id = b.location_id AND ws.item_id = $2
+            ) AS holds_item
+        FROM bin_locations b
+        JOIN bin_location_paths p ON p.location_id = b.location_id
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(SUM(ws.quantity), 0)::INTEGER AS stored
+            FROM warehouse_stock ws
+            WHERE ws.bin_id = b.location_id
+        ) s
+        WHERE b.warehouse_id = $1
+            AND b.level = 'bin'
+            AND (b.capacity IS NULL OR b.capacity - s.stored >= $3)
+            AND NOT EXISTS (
+                SELECT 1 FROM bin_locations a
+                WHERE a.location_id = ANY(p.ids) AND NOT a.is_active
+            )
+        ORDER BY holds_item DESC, s.stored = 0 DESC, p.walk_key
+        LIMIT $4
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, warehouseID, itemID, quantity, putawaySuggestions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []*locationmodels.PutawaySuggestion{}
+	for rows.Next() {
+		suggestion := &locationmodels.PutawaySuggestion{}
+		err := rows.Scan(
+			&suggestion.BinID,
+			&suggestion.Label,
+			&suggestion.Capacity,
+			&suggestion.Stored,
+			&suggestion.Free,
+			&suggestion.HoldsItem,
+		)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, rows.Err()
+}
+
+// syncBinText copies the path of every bin at or under a location into
+// the free-text location of the stock kept there, and of the items
+// themselves for stock in the default warehouse, which is what labels print
+func syncBinText(ctx context.Context, tx pgx.Tx, locationID int) error {
+	_, err := tx.Exec(ctx, `
+        UPDATE warehouse_stock ws
+        SET location_floor = p.codes[1], location_corridor = p.codes[2],
+            location_aisle = p.codes[3], location_shelf = p.codes[4],
+            location_bin = p.codes[5]
+        FROM bin_location_paths p
+        WHERE p.location_id = ws.bin_id AND $1 = ANY(p.ids)
+    `, locationID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE items i
+        SET location_floor = ws.location_floor, location_corridor = ws.location_corridor,
+            location_aisle = ws.location_aisle, location_shelf = ws.location_shelf,
+            location_bin = ws.location_bin
+        FROM warehouse_stock ws
+        JOIN warehouses w ON w.warehouse_id = ws.warehouse_id AND w.is_default
+        JOIN bin_location_paths p ON p.location_id = ws.bin_id
+        WHERE ws.item_id = i.item_id AND $1 = ANY(p.ids)
+    `, locationID)
+	return err
+}
+
+// locationError maps the constraint failures of writing a location to the
+// errors callers understand
+func locationError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case pgErr.Code == uniqueViolation && pgErr.ConstraintName == "idx_bin_locations_code":
+		return ErrDuplicateCode
+	case pgErr.Code == foreignKeyViolation && pgErr.ConstraintName == "bin_locations_warehouse_id_fkey":
+		return ErrWarehouseNotFound
+	case pgErr.Code == foreignKeyViolation && pgErr.ConstraintName == "bin_locations_parent_id_fkey":
+		return ErrLocationNotFound
+	}
+	return err
+}
+
+func scanLocation(row pgx.Row) (*locationmodels.Location, error) {
+	location := &locationmodels.Location{}
+	err := row.Scan(
+		&location.LocationID,
+		&location.WarehouseID,
+		&location.ParentID,
+		&location.Level,
+		&location.Code,
+		&location.Capacity,
+		&location.SortOrder,
+		&location.IsActive,
+		&location.Notes,
+		&location.CreatedAt,
+		&location.UpdatedAt,
+		&location.Label,
+		&location.Stored,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
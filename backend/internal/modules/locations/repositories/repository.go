@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	locationmodels "github.com/hsrvms/autoparts/internal/modules/locations/models"
+)
+
+var (
+	ErrLocationNotFound  = errors.New("location not found")
+	ErrDuplicateCode     = errors.New("a location with this code already exists here")
+	ErrLocationInUse     = errors.New("location has locations or items under it and cannot be deleted")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+	ErrItemNotFound      = errors.New("item not found")
+	ErrItemNotInBin      = errors.New("item is not kept in this bin")
+	ErrOverCapacity      = errors.New("bin does not have room for the item's stock")
+)
+
+type LocationRepository interface {
+	// Location operations
+	GetLocations(ctx context.Context, filter *locationmodels.LocationFilter) ([]*locationmodels.Location, error)
+	GetLocationByID(ctx context.Context, id int) (*locationmodels.Location, error)
+	CreateLocation(ctx context.Context, location *locationmodels.Location) (int, error)
+	UpdateLocation(ctx context.Context, location *locationmodels.Location) error
+	DeleteLocation(ctx context.Context, id int) error
+
+	// Bin operations
+	GetContents(ctx context.Context, id int) ([]*locationmodels.BinContent, error)
+	// AssignItem makes a bin the place an item is kept in the bin's
+	// warehouse, moving it from any other bin there
+	AssignItem(ctx context.Context, binID, itemID int) error
+	UnassignItem(ctx context.Context, binID, itemID int) error
+	GetPutawaySuggestions(ctx context.Context, warehouseID, itemID, quantity int) ([]*locationmodels.PutawaySuggestion, error)
+
+	// Pick list operations
+	GetSalePickList(ctx context.Context, transactionID int) (*locationmodels.PickList, error)
+	GetTransferPickList(ctx context.Context, transferID int) (*locationmodels.PickList, error)
+}
@@ -0,0 +1,43 @@
+package locations
+
+import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/internal/modules/locations/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/locations/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/locations/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresLocationRepository(database)
+
+	// Initialize service
+	service := services.NewLocationService(repo)
+
+	// Initialize handler
+	handler := handlers.NewLocationHandler(service)
+
+	// Permissions. Laying out a warehouse is for managers; putting items
+	// in bins is part of handling stock.
+	read := authmiddleware.RequirePermission(authmodels.PermStockRead)
+	write := authmiddleware.RequirePermission(authmodels.PermWarehousesWrite)
+	adjust := authmiddleware.RequirePermission(authmodels.PermStockAdjust)
+
+	// Register routes
+	locations := api.Group("/locations")
+	locations.GET("", handler.GetLocations, read)
+	locations.GET("/:id", handler.GetLocationByID, read)
+	locations.POST("", handler.CreateLocation, write)
+	locations.PUT("/:id", handler.UpdateLocation, write)
+	locations.DELETE("/:id", handler.DeleteLocation, write)
+	locations.GET("/:id/contents", handler.GetContents, read)
+	locations.PUT("/:id/items/:itemId", handler.AssignItem, adjust)
+	locations.DELETE("/:id/items/:itemId", handler.UnassignItem, adjust)
+
+	api.GET("/warehouses/:id/putaway", handler.GetPutawaySuggestions, read)
+	api.GET("/sales/transactions/:id/pick-list", handler.GetSalePickList, read)
+	api.GET("/transfers/:id/pick-list", handler.GetTransferPickList, read)
+}
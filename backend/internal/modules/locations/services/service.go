@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	locationmodels "github.com/hsrvms/autoparts/internal/modules/locations/models"
+	"github.com/hsrvms/autoparts/internal/modules/locations/repositories"
+)
+
+var (
+	ErrLocationNotFound   = repositories.ErrLocationNotFound
+	ErrDuplicateCode      = repositories.ErrDuplicateCode
+	ErrLocationInUse      = repositories.ErrLocationInUse
+	ErrWarehouseNotFound  = repositories.ErrWarehouseNotFound
+	ErrItemNotFound       = repositories.ErrItemNotFound
+	ErrItemNotInBin       = repositories.ErrItemNotInBin
+	ErrOverCapacity       = repositories.ErrOverCapacity
+	ErrInvalidLocationID  = errors.New("invalid location ID")
+	ErrInvalidWarehouseID = errors.New("invalid warehouse ID")
+	ErrInvalidItemID      = errors.New("invalid item ID")
+	ErrInvalidLevel       = errors.New("level must be one of floor, corridor, aisle, shelf or bin")
+	ErrCodeRequired       = errors.New("location code is required")
+	ErrInvalidCapacity    = errors.New("capacity cannot be negative")
+	ErrInvalidSortOrder   = errors.New("sort order cannot be negative")
+	ErrInvalidParent      = errors.New("a location must sit in a location of the level above it in the same warehouse, and a floor in none")
+	ErrCapacityBelowStock = errors.New("capacity is less than the stock already kept in the bin")
+	ErrNotABin            = errors.New("items can only be kept in bins")
+	ErrInactiveLocation   = errors.New("location is not active")
+	ErrInvalidQuantity    = errors.New("quantity must be greater than zero")
+	ErrInvalidSaleID      = errors.New("invalid sale transaction ID")
+	ErrSaleNotFound       = errors.New("sale transaction not found")
+	ErrInvalidTransferID  = errors.New("invalid transfer ID")
+	ErrTransferNotFound   = errors.New("transfer not found")
+)
+
+type LocationService interface {
+	// Location operations
+	GetLocations(ctx context.Context, filter *locationmodels.LocationFilter) ([]*locationmodels.Location, error)
+	GetLocationByID(ctx context.Context, id int) (*locationmodels.Location, error)
+	CreateLocation(ctx context.Context, location *locationmodels.Location) (int, error)
+	UpdateLocation(ctx context.Context, location *locationmodels.Location) error
+	DeleteLocation(ctx context.Context, id int) error
+
+	// Bin operations
+	GetContents(ctx context.Context, id int) ([]*locationmodels.BinContent, error)
+	AssignItem(ctx context.Context, binID, itemID int) error
+	UnassignItem(ctx context.Context, binID, itemID int) error
+	GetPutawaySuggestions(ctx context.Context, warehouseID, itemID, quantity int) ([]*locationmodels.PutawaySuggestion, error)
+
+	// Pick list operations
+	GetSalePickList(ctx context.Context, transactionID int) (*locationmodels.PickList, error)
+	GetTransferPickList(ctx context.Context, transferID int) (*locationmodels.PickList, error)
+}
+
+type locationService struct {
+	repo repositories.LocationRepository
+}
+
+func NewLocationService(repo repositories.LocationRepository) LocationService {
+	return &locationService{
+		repo: repo,
+	}
+}
+
+func (s *locationService) GetLocations(ctx context.Context, filter *locationmodels.LocationFilter) ([]*locationmodels.Location, error) {
+	if filter != nil && filter.Level != nil && levelIndex(*filter.Level) < 0 {
+		return nil, ErrInvalidLevel
+	}
+
+	return s.repo.GetLocations(ctx, filter)
+}
+
+func (s *locationService) GetLocationByID(ctx context.Context, id int) (*locationmodels.Location, error) {
+	if id <= 0 {
+		return nil, ErrInvalidLocationID
+	}
+
+	location, err := s.repo.GetLocationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	return location, nil
+}
+
+// CreateLocation adds a location. Locations below a floor take the
+// warehouse of their parent.
+func (s *locationService) CreateLocation(ctx context.Context, location *locationmodels.Location) (int, error) {
+	if levelIndex(location.Level) < 0 {
+		return 0, ErrInvalidLevel
+	}
+	if err := s.validateLocation(location); err != nil {
+		return 0, err
+	}
+	if err := s.checkParent(ctx, location); err != nil {
+		return 0, err
+	}
+
+	return s.repo.CreateLocation(ctx, location)
+}
+
+// UpdateLocation changes a location. Its level and warehouse stay as they
+// are; it can be moved to another parent of the same level.
+func (s *locationService) UpdateLocation(ctx context.Context, location *locationmodels.Location) error {
+	existing, err := s.GetLocationByID(ctx, location.LocationID)
+	if err != nil {
+		return err
+	}
+
+	location.Level = existing.Level
+	location.WarehouseID = existing.WarehouseID
+	if location.ParentID == nil {
+		location.ParentID = existing.ParentID
+	}
+
+	if err := s.validateLocation(location); err != nil {
+		return err
+	}
+	if err := s.checkParent(ctx, location); err != nil {
+		return err
+	}
+	if location.Level == locationmodels.LevelBin && location.Capacity != nil && *location.Capacity < existing.Stored {
+		return ErrCapacityBelowStock
+	}
+
+	return s.repo.UpdateLocation(ctx, location)
+}
+
+func (s *locationService) DeleteLocation(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidLocationID
+	}
+
+	return s.repo.DeleteLocation(ctx, id)
+}
+
+// GetContents lists the items kept in a bin, or in all the bins under a
+// floor, corridor, aisle or shelf
+func (s *locationService) GetContents(ctx context.Context, id int) ([]*locationmodels.BinContent, error) {
+	if _, err := s.GetLocationByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetContents(ctx, id)
+}
+
+func (s *locationService) AssignItem(ctx context.Context, binID, itemID int) error {
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+
+	bin, err := s.GetLocationByID(ctx, binID)
+	if err != nil {
+		return err
+	}
+	if bin.Level != locationmodels.LevelBin {
+		return ErrNotABin
+	}
+	if !bin.IsActive {
+		return ErrInactiveLocation
+	}
+
+	return s.repo.AssignItem(ctx, binID, itemID)
+}
+
+func (s *locationService) UnassignItem(ctx context.Context, binID, itemID int) error {
+	if binID <= 0 {
+		return ErrInvalidLocationID
+	}
+	if itemID <= 0 {
+		return ErrInvalidItemID
+	}
+
+	return s.repo.UnassignItem(ctx, binID, itemID)
+}
+
+func (s *locationService) GetPutawaySuggestions(ctx context.Context, warehouseID, itemID, quantity int) ([]*locationmodels.PutawaySuggestion, error) {
+	if warehouseID <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	return s.repo.GetPutawaySuggestions(ctx, warehouseID, itemID, quantity)
+}
+
+func (s *locationService) GetSalePickList(ctx context.Context, transactionID int) (*locationmodels.PickList, error) {
+	if transactionID <= 0 {
+		return nil, ErrInvalidSaleID
+	}
+
+	list, err := s.repo.GetSalePickList(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrSaleNotFound
+	}
+
+	return list, nil
+}
+
+func (s *locationService) GetTransferPickList(ctx context.Context, transferID int) (*locationmodels.PickList, error) {
+	if transferID <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+
+	list, err := s.repo.GetTransferPickList(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	return list, nil
+}
+
+// Helper functions
+
+func (s *locationService) validateLocation(location *locationmodels.Location) error {
+	location.Code = strings.TrimSpace(location.Code)
+
+	if location.Code == "" {
+		return ErrCodeRequired
+	}
+	if location.Capacity != nil && *location.Capacity < 0 {
+		return ErrInvalidCapacity
+	}
+	if location.SortOrder < 0 {
+		return ErrInvalidSortOrder
+	}
+	return nil
+}
+
+// checkParent makes sure a location sits one level below its parent, in
+// the parent's warehouse, which it takes when none is given
+func (s *locationService) checkParent(ctx context.Context, location *locationmodels.Location) error {
+	if location.Level == locationmodels.LevelFloor {
+		if location.ParentID != nil {
+			return ErrInvalidParent
+		}
+		if location.WarehouseID <= 0 {
+			return ErrInvalidWarehouseID
+		}
+		return nil
+	}
+
+	if location.ParentID == nil {
+		return ErrInvalidParent
+	}
+	parent, err := s.GetLocationByID(ctx, *location.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if location.WarehouseID == 0 {
+		location.WarehouseID = parent.WarehouseID
+	}
+	if parent.WarehouseID != location.WarehouseID ||
+		levelIndex(parent.Level) != levelIndex(location.Level)-1 {
+		return ErrInvalidParent
+	}
+	return nil
+}
+
+// levelIndex returns how deep a level is in the hierarchy, or -1 for an
+// unknown level
+func levelIndex(level string) int {
+	for i, l := range locationmodels.Levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
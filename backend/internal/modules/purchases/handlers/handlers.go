@@ -6,8 +6,10 @@ import (
 	"strconv"
 	"time"
 
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
 	"github.com/hsrvms/autoparts/internal/modules/purchases/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/labstack/echo/v4"
 )
 
@@ -21,8 +23,13 @@ func NewPurchaseHandler(service services.PurchaseService) *PurchaseHandler {
     }
 }
 
-// GetPurchases handles retrieval of all purchases with optional filtering
+// GetPurchases handles retrieval of a page of purchases with optional filtering
 func (h *PurchaseHandler) GetPurchases(c echo.Context) error {
+    page, err := pagination.FromRequest(c, services.PurchaseSorting)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+    }
+
     filter := &purchasemodels.PurchaseFilter{}
 
     // Parse query parameters
@@ -56,13 +63,28 @@ func (h *PurchaseHandler) GetPurchases(c echo.Context) error {
         filter.InvoiceNumber = &invoiceNumber
     }
 
+    if status := c.QueryParam("status"); status != "" {
+        filter.Status = &status
+    }
+
+    if outstanding, err := strconv.ParseBool(c.QueryParam("outstanding")); err == nil {
+        filter.Outstanding = outstanding
+    }
+
+    if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+        id, err := strconv.Atoi(warehouseID)
+        if err == nil {
+            filter.WarehouseID = &id
+        }
+    }
+
     ctx := c.Request().Context()
-    purchases, err := h.service.GetAll(ctx, filter)
+    purchases, err := h.service.GetAll(ctx, filter, page)
     if err != nil {
         return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
     }
 
-    return c.JSON(http.StatusOK, purchases)
+    return pagination.Respond(c, page, purchases)
 }
 
 // GetPurchaseByID handles retrieval of a single purchase
@@ -86,21 +108,30 @@ func (h *PurchaseHandler) GetPurchaseByID(c echo.Context) error {
     return c.JSON(http.StatusOK, purchase)
 }
 
-// CreatePurchase handles creation of a new purchase
+// CreatePurchase handles creation of a new purchase order
 func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
-    purchase := new(purchasemodels.Purchase)
-    if err := c.Bind(purchase); err != nil {
+    req := new(purchasemodels.CreatePurchaseRequest)
+    if err := c.Bind(req); err != nil {
         return echo.NewHTTPError(http.StatusBadRequest, err.Error())
     }
 
+    // Deliveries are always taken in by whoever is signed in
+    order := req.Order()
+    order.ReceivedBy = nil
+    order.ReceivedByUserID = currentUserID(c)
+
     ctx := c.Request().Context()
-    id, err := h.service.Create(ctx, purchase)
+    id, err := h.service.Create(ctx, order)
     if err != nil {
         switch err {
         case services.ErrInvalidSupplierID, services.ErrInvalidItemID,
              services.ErrInvalidQuantity, services.ErrInvalidCostPerUnit,
-             services.ErrInvalidDate:
+             services.ErrInvalidDate, services.ErrInvalidExpectedDate,
+             services.ErrInvalidStatus, services.ErrInvalidStatusChange,
+             services.ErrEmptyPurchase, services.ErrInvalidWarehouseID:
             return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+        case services.ErrItemNotFound, services.ErrWarehouseNotFound:
+            return echo.NewHTTPError(http.StatusNotFound, err.Error())
         case services.ErrDuplicateInvoiceNumber:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
         default:
@@ -108,11 +139,15 @@ func (h *PurchaseHandler) CreatePurchase(c echo.Context) error {
         }
     }
 
-    purchase.PurchaseID = id
+    purchase, err := h.service.GetByID(ctx, id)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+    }
+
     return c.JSON(http.StatusCreated, purchase)
 }
 
-// UpdatePurchase handles updating an existing purchase
+// UpdatePurchase handles updating an existing purchase order
 func (h *PurchaseHandler) UpdatePurchase(c echo.Context) error {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
@@ -129,19 +164,63 @@ func (h *PurchaseHandler) UpdatePurchase(c echo.Context) error {
     err = h.service.Update(ctx, purchase)
     if err != nil {
         switch err {
-        case services.ErrPurchaseNotFound:
+        case services.ErrPurchaseNotFound, services.ErrItemNotFound,
+             services.ErrWarehouseNotFound:
             return echo.NewHTTPError(http.StatusNotFound, err.Error())
         case services.ErrInvalidSupplierID, services.ErrInvalidItemID,
              services.ErrInvalidQuantity, services.ErrInvalidCostPerUnit,
-             services.ErrInvalidDate:
+             services.ErrInvalidDate, services.ErrInvalidExpectedDate,
+             services.ErrInvalidStatus, services.ErrEmptyPurchase,
+             services.ErrInvalidWarehouseID:
             return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-        case services.ErrDuplicateInvoiceNumber:
+        case services.ErrDuplicateInvoiceNumber, services.ErrInvalidStatusChange,
+             services.ErrPurchaseLocked, services.ErrWarehouseLocked:
             return echo.NewHTTPError(http.StatusConflict, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
     }
 
+    updated, err := h.service.GetByID(ctx, id)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+    }
+
+    return c.JSON(http.StatusOK, updated)
+}
+
+// ReceivePurchase handles recording a delivery against a purchase order
+func (h *PurchaseHandler) ReceivePurchase(c echo.Context) error {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, "invalid purchase ID")
+    }
+
+    receipt := new(purchasemodels.PurchaseReceipt)
+    if err := c.Bind(receipt); err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+    }
+    receipt.ReceivedBy = currentUserID(c)
+
+    ctx := c.Request().Context()
+    purchase, err := h.service.Receive(ctx, id, receipt)
+    if err != nil {
+        switch err {
+        case services.ErrPurchaseNotFound, services.ErrItemNotFound,
+             services.ErrLineNotFound, services.ErrBarcodeNotFound:
+            return echo.NewHTTPError(http.StatusNotFound, err.Error())
+        case services.ErrInvalidPurchaseID, services.ErrInvalidQuantity,
+             services.ErrEmptyReceipt:
+            return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+        case services.ErrDuplicateInvoiceNumber, services.ErrPurchaseNotReceivable:
+            return echo.NewHTTPError(http.StatusConflict, err.Error())
+        case services.ErrOverReceipt:
+            return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+        default:
+            return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+        }
+    }
+
     return c.JSON(http.StatusOK, purchase)
 }
 
@@ -156,8 +235,10 @@ func (h *PurchaseHandler) DeletePurchase(c echo.Context) error {
     err = h.service.Delete(ctx, id)
     if err != nil {
         switch err {
-        case services.ErrPurchaseNotFound:
+        case services.ErrPurchaseNotFound, services.ErrItemNotFound:
             return echo.NewHTTPError(http.StatusNotFound, err.Error())
+        case services.ErrInsufficientStock:
+            return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
         default:
             return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
         }
@@ -166,15 +247,18 @@ func (h *PurchaseHandler) DeletePurchase(c echo.Context) error {
     return c.NoContent(http.StatusNoContent)
 }
 
-// GetSupplierPurchases handles retrieval of all purchases for a supplier
+// GetSupplierPurchases handles retrieval of all purchases for a supplier.
+// With outstanding=true only orders still awaiting delivery are returned.
 func (h *PurchaseHandler) GetSupplierPurchases(c echo.Context) error {
     supplierID, err := strconv.Atoi(c.Param("supplierId"))
     if err != nil {
         return echo.NewHTTPError(http.StatusBadRequest, "invalid supplier ID")
     }
 
+    outstandingOnly, _ := strconv.ParseBool(c.QueryParam("outstanding"))
+
     ctx := c.Request().Context()
-    purchases, err := h.service.GetSupplierPurchases(ctx, supplierID)
+    purchases, err := h.service.GetSupplierPurchases(ctx, supplierID, outstandingOnly)
     if err != nil {
         return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
     }
@@ -197,3 +281,11 @@ func (h *PurchaseHandler) GetItemPurchases(c echo.Context) error {
 
     return c.JSON(http.StatusOK, purchases)
 }
+
+// currentUserID returns the ID of the signed-in user, if any
+func currentUserID(c echo.Context) *int {
+    if user := authmiddleware.CurrentUser(c); user != nil {
+        return &user.UserID
+    }
+    return nil
+}
@@ -2,24 +2,111 @@ package purchasemodels
 
 import "time"
 
+const (
+	PurchaseStatusDraft             = "draft"
+	PurchaseStatusOrdered           = "ordered"
+	PurchaseStatusPartiallyReceived = "partially-received"
+	PurchaseStatusReceived          = "received"
+	PurchaseStatusCancelled         = "cancelled"
+)
+
+// Purchase is a purchase order placed with a supplier. ReceivedBy carries
+// the display name of the user who took in the last delivery. Deliveries
+// go into the order's warehouse, the default one when none is given.
 type Purchase struct {
-	PurchaseID    int       `json:"purchase_id" db:"purchase_id"`
-	Date          time.Time `json:"date" db:"date"`
-	SupplierID    int       `json:"supplier_id" db:"supplier_id"`
-	ItemID        int       `json:"item_id" db:"item_id"`
-	Quantity      int       `json:"quantity" db:"quantity"`
-	CostPerUnit   float64   `json:"cost_per_unit" db:"cost_per_unit"`
-	TotalCost     float64   `json:"total_cost" db:"total_cost"`
-	InvoiceNumber *string   `json:"invoice_number,omitempty" db:"invoice_number"`
-	ReceivedBy    *string   `json:"received_by,omitempty" db:"received_by"`
-	Notes         *string   `json:"notes,omitempty" db:"notes"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	PurchaseID       int             `json:"purchase_id" db:"purchase_id"`
+	Date             time.Time       `json:"date" db:"date"`
+	SupplierID       int             `json:"supplier_id" db:"supplier_id"`
+	WarehouseID      int             `json:"warehouse_id" db:"warehouse_id"`
+	Status           string          `json:"status" db:"status"`
+	ExpectedDate     *time.Time      `json:"expected_date,omitempty" db:"expected_date"`
+	InvoiceNumber    *string         `json:"invoice_number,omitempty" db:"invoice_number"`
+	ReceivedByUserID *int            `json:"received_by_user_id,omitempty" db:"received_by_user_id"`
+	ReceivedBy       *string         `json:"received_by,omitempty" db:"received_by"`
+	Notes            *string         `json:"notes,omitempty" db:"notes"`
+	Lines            []*PurchaseLine `json:"lines"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	SupplierName        string  `json:"supplier_name,omitempty" db:"supplier_name"`
+	TotalCost           float64 `json:"total_cost"`
+	QuantityOrdered     int     `json:"quantity_ordered"`
+	QuantityReceived    int     `json:"quantity_received"`
+	QuantityOutstanding int     `json:"quantity_outstanding"`
+}
+
+// PurchaseLine is a single item ordered on a purchase order
+type PurchaseLine struct {
+	LineID           int       `json:"line_id" db:"line_id"`
+	PurchaseID       int       `json:"purchase_id" db:"purchase_id"`
+	ItemID           int       `json:"item_id" db:"item_id"`
+	QuantityOrdered  int       `json:"quantity_ordered" db:"quantity_ordered"`
+	QuantityReceived int       `json:"quantity_received" db:"quantity_received"`
+	CostPerUnit      float64   `json:"cost_per_unit" db:"cost_per_unit"`
+	TotalCost        float64   `json:"total_cost" db:"total_cost"`
+	Notes            *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 
 	// Additional fields for API responses
-	SupplierName    string `json:"supplier_name,omitempty" db:"supplier_name"`
-	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
-	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
+	QuantityOutstanding int    `json:"quantity_outstanding"`
+	ItemPartNumber      string `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription     string `json:"item_description,omitempty" db:"item_description"`
+}
+
+// CreatePurchaseRequest is the body of POST /purchases. Older clients post a
+// single item at the top level; such a purchase is recorded as received
+// on the spot, as it always was.
+type CreatePurchaseRequest struct {
+	Purchase
+	ItemID      int     `json:"item_id"`
+	Quantity    int     `json:"quantity"`
+	CostPerUnit float64 `json:"cost_per_unit"`
+}
+
+// Order returns the purchase order described by the request
+func (r *CreatePurchaseRequest) Order() *Purchase {
+	purchase := r.Purchase
+	if len(purchase.Lines) == 0 && r.ItemID != 0 {
+		purchase.Lines = []*PurchaseLine{{
+			ItemID:          r.ItemID,
+			QuantityOrdered: r.Quantity,
+			CostPerUnit:     r.CostPerUnit,
+		}}
+		if purchase.Status == "" {
+			purchase.Status = PurchaseStatusReceived
+		}
+	}
+	return &purchase
+}
+
+// PurchaseReceipt records a delivery against a purchase order. The
+// receiver is the authenticated user and is not taken from the body.
+type PurchaseReceipt struct {
+	Lines         []ReceiptLine `json:"lines"`
+	ReceivedBy    *int          `json:"-"`
+	InvoiceNumber *string       `json:"invoice_number,omitempty"`
+	Notes         *string       `json:"notes,omitempty"`
+}
+
+// ReceiptLine is the quantity delivered for one line. The line is found by
+// LineID, or by ItemID when no line ID is given.
+type ReceiptLine struct {
+	LineID   int `json:"line_id"`
+	ItemID   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+
+	// Barcode is what was scanned for the line. Given without a line or
+	// item ID, it picks the item and Quantity counts packs of the barcode.
+	Barcode string `json:"barcode,omitempty"`
+}
+
+// ScannedItem is the item a barcode was scanned for, with the number of
+// units one scan stands for
+type ScannedItem struct {
+	ItemID       int `db:"item_id"`
+	PackQuantity int `db:"pack_quantity"`
 }
 
 type PurchaseFilter struct {
@@ -28,4 +115,7 @@ type PurchaseFilter struct {
 	StartDate     *time.Time `query:"start_date"`
 	EndDate       *time.Time `query:"end_date"`
 	InvoiceNumber *string    `query:"invoice_number"`
+	Status        *string    `query:"status"`
+	Outstanding   bool       `query:"outstanding"`
+	WarehouseID   *int       `query:"warehouse_id"`
 }
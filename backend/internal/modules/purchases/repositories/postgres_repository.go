@@ -4,38 +4,62 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// foreignKeyViolation is the Postgres error code for a missing referenced row
+const foreignKeyViolation = "23503"
+
+// purchaseSelectQuery selects purchase order headers with their supplier
+const purchaseSelectQuery = `
+        SELECT
+            p.purchase_id, p.date, p.supplier_id, p.status,
+            p.expected_date, p.invoice_number, p.received_by_user_id,
+            COALESCE(u.full_name, u.username, p.received_by) as received_by,
+            p.notes, p.created_at, p.updated_at,
+            s.name as supplier_name, p.warehouse_id
+        FROM purchases p
+        JOIN suppliers s ON p.supplier_id = s.supplier_id
+        LEFT JOIN users u ON p.received_by_user_id = u.user_id
+`
+
+// PurchaseSorting lists the fields purchase orders can be sorted by
+var PurchaseSorting = &pagination.Sorting{
+    Fields: map[string]pagination.Field{
+        "id":             {Column: "p.purchase_id", Type: "integer"},
+        "date":           {Column: "p.date", Type: "timestamptz"},
+        "status":         {Column: "p.status", Type: "text"},
+        "invoice_number": {Column: "COALESCE(p.invoice_number, '')", Type: "text"},
+        "supplier":       {Column: "s.name", Type: "text"},
+    },
+    Default:  "-date",
+    IDColumn: "p.purchase_id",
+}
+
 type PostgresPurchaseRepository struct {
-    db *db.Database
+    db        *db.Database
+    movements stockmovementrepositories.StockMovementRepository
 }
 
-func NewPostgresPurchaseRepository(database *db.Database) PurchaseRepository {
+func NewPostgresPurchaseRepository(database *db.Database, movements stockmovementrepositories.StockMovementRepository) PurchaseRepository {
     return &PostgresPurchaseRepository{
-        db: database,
+        db:        database,
+        movements: movements,
     }
 }
 
-func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter) ([]*purchasemodels.Purchase, error) {
-    query := `
-        SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
-            i.part_number as item_part_number,
-            i.description as item_description
-        FROM purchases p
-        JOIN suppliers s ON p.supplier_id = s.supplier_id
-        JOIN items i ON p.item_id = i.item_id
-        WHERE 1=1
-    `
+func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter, page *pagination.Page) ([]*purchasemodels.Purchase, error) {
+    query := purchaseSelectQuery + " WHERE 1=1"
 
     var conditions []string
     var params []interface{}
@@ -49,7 +73,10 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
         }
 
         if filter.ItemID != nil {
-            conditions = append(conditions, fmt.Sprintf("p.item_id = $%d", paramCount))
+            conditions = append(conditions, fmt.Sprintf(
+                "EXISTS (SELECT 1 FROM purchase_lines pl WHERE pl.purchase_id = p.purchase_id AND pl.item_id = $%d)",
+                paramCount,
+            ))
             params = append(params, *filter.ItemID)
             paramCount++
         }
@@ -71,14 +98,39 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
             params = append(params, "%"+*filter.InvoiceNumber+"%")
             paramCount++
         }
+
+        if filter.Status != nil {
+            conditions = append(conditions, fmt.Sprintf("p.status = $%d", paramCount))
+            params = append(params, *filter.Status)
+            paramCount++
+        }
+
+        if filter.WarehouseID != nil {
+            conditions = append(conditions, fmt.Sprintf("p.warehouse_id = $%d", paramCount))
+            params = append(params, *filter.WarehouseID)
+            paramCount++
+        }
+
+        if filter.Outstanding {
+            conditions = append(conditions, fmt.Sprintf(
+                "p.status IN ('%s', '%s')",
+                purchasemodels.PurchaseStatusOrdered,
+                purchasemodels.PurchaseStatusPartiallyReceived,
+            ))
+        }
     }
 
     if len(conditions) > 0 {
         query += " AND " + strings.Join(conditions, " AND ")
     }
 
-    query += " ORDER BY p.date DESC"
+    if page != nil {
+        if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), params...).Scan(&page.Total); err != nil {
+            return nil, err
+        }
+    }
 
+    query, params = PurchaseSorting.Apply(query, params, page)
     rows, err := r.db.Pool.Query(ctx, query, params...)
     if err != nil {
         return nil, err
@@ -87,68 +139,32 @@ func (r *PostgresPurchaseRepository) GetAll(ctx context.Context, filter *purchas
 
     var purchases []*purchasemodels.Purchase
     for rows.Next() {
-        purchase := &purchasemodels.Purchase{}
-        err := rows.Scan(
-            &purchase.PurchaseID,
-            &purchase.Date,
-            &purchase.SupplierID,
-            &purchase.ItemID,
-            &purchase.Quantity,
-            &purchase.CostPerUnit,
-            &purchase.TotalCost,
-            &purchase.InvoiceNumber,
-            &purchase.ReceivedBy,
-            &purchase.Notes,
-            &purchase.CreatedAt,
-            &purchase.UpdatedAt,
-            &purchase.SupplierName,
-            &purchase.ItemPartNumber,
-            &purchase.ItemDescription,
-        )
+        purchase, err := scanPurchase(rows)
         if err != nil {
             return nil, err
         }
         purchases = append(purchases, purchase)
     }
+    if err = rows.Err(); err != nil {
+        return nil, err
+    }
+
+    if err = r.loadLines(ctx, purchases); err != nil {
+        return nil, err
+    }
 
-    return purchases, rows.Err()
+    if page != nil && len(purchases) > 0 {
+        last := purchases[len(purchases)-1]
+        page.SetNextCursor(len(purchases), purchaseSortValue(last, page.Sort), last.PurchaseID)
+    }
+
+    return purchases, nil
 }
 
 func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*purchasemodels.Purchase, error) {
-    query := `
-        SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
-            i.part_number as item_part_number,
-            i.description as item_description
-        FROM purchases p
-        JOIN suppliers s ON p.supplier_id = s.supplier_id
-        JOIN items i ON p.item_id = i.item_id
-        WHERE p.purchase_id = $1
-    `
-
-    purchase := &purchasemodels.Purchase{}
-    err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-        &purchase.PurchaseID,
-        &purchase.Date,
-        &purchase.SupplierID,
-        &purchase.ItemID,
-        &purchase.Quantity,
-        &purchase.CostPerUnit,
-        &purchase.TotalCost,
-        &purchase.InvoiceNumber,
-        &purchase.ReceivedBy,
-        &purchase.Notes,
-        &purchase.CreatedAt,
-        &purchase.UpdatedAt,
-        &purchase.SupplierName,
-        &purchase.ItemPartNumber,
-        &purchase.ItemDescription,
-    )
+    query := purchaseSelectQuery + " WHERE p.purchase_id = $1"
 
+    purchase, err := scanPurchase(r.db.Pool.QueryRow(ctx, query, id))
     if err != nil {
         if errors.Is(err, pgx.ErrNoRows) {
             return nil, nil
@@ -156,150 +172,595 @@ func (r *PostgresPurchaseRepository) GetByID(ctx context.Context, id int) (*purc
         return nil, err
     }
 
+    if err = r.loadLines(ctx, []*purchasemodels.Purchase{purchase}); err != nil {
+        return nil, err
+    }
+
     return purchase, nil
 }
 
+// Create stores a purchase order with its lines. An order created as
+// received is booked into stock straight away.
 func (r *PostgresPurchaseRepository) Create(ctx context.Context, purchase *purchasemodels.Purchase) (int, error) {
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback(ctx)
+
+    // Orders that name no warehouse are delivered to the default one
     query := `
         INSERT INTO purchases (
-            date, supplier_id, item_id, quantity,
-            cost_per_unit, total_cost, invoice_number,
-            received_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-        RETURNING purchase_id
+            date, supplier_id, status, expected_date,
+            invoice_number, received_by_user_id, notes, warehouse_id
+        ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7,
+            COALESCE(NULLIF($8, 0), (SELECT warehouse_id FROM warehouses WHERE is_default))
+        )
+        RETURNING purchase_id, warehouse_id
     `
 
+    // Only an order that arrives with its delivery has a receiver yet
+    received := purchase.Status == purchasemodels.PurchaseStatusReceived
+    var receivedBy *int
+    if received {
+        receivedBy = purchase.ReceivedByUserID
+    }
+
     var id int
-    err := r.db.Pool.QueryRow(
+    err = tx.QueryRow(
         ctx, query,
         purchase.Date,
         purchase.SupplierID,
-        purchase.ItemID,
-        purchase.Quantity,
-        purchase.CostPerUnit,
-        purchase.TotalCost,
+        purchase.Status,
+        purchase.ExpectedDate,
         purchase.InvoiceNumber,
-        purchase.ReceivedBy,
+        receivedBy,
         purchase.Notes,
-    ).Scan(&id)
+        purchase.WarehouseID,
+    ).Scan(&id, &purchase.WarehouseID)
 
     if err != nil {
+        if isWarehouseViolation(err) {
+            return 0, ErrWarehouseNotFound
+        }
+        return 0, err
+    }
+    purchase.PurchaseID = id
+
+    if err = insertLines(ctx, tx, purchase, received); err != nil {
+        return 0, err
+    }
+
+    // Add the received quantities to stock
+    if received {
+        deltas := map[int]int{}
+        for _, line := range purchase.Lines {
+            deltas[line.ItemID] += line.QuantityOrdered
+        }
+        if err = r.applyStockDeltas(ctx, tx, id, purchase.WarehouseID, deltas, nil); err != nil {
+            return 0, err
+        }
+    }
+
+    if err = tx.Commit(ctx); err != nil {
         return 0, err
     }
 
     return id, nil
 }
 
+// Update changes the header of a purchase order. Lines are replaced only
+// while the order is still a draft and lines are given.
 func (r *PostgresPurchaseRepository) Update(ctx context.Context, purchase *purchasemodels.Purchase) error {
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    // Lock the purchase so concurrent receipts see a consistent status
+    var oldStatus string
+    err = tx.QueryRow(ctx, `
+        SELECT status FROM purchases WHERE purchase_id = $1 FOR UPDATE
+    `, purchase.PurchaseID).Scan(&oldStatus)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return errors.New("purchase not found")
+        }
+        return err
+    }
+
     query := `
         UPDATE purchases SET
             date = $2,
             supplier_id = $3,
-            item_id = $4,
-            quantity = $5,
-            cost_per_unit = $6,
-            total_cost = $7,
-            invoice_number = $8,
-            received_by = $9,
-            notes = $10
+            status = $4,
+            expected_date = $5,
+            invoice_number = $6,
+            notes = $7,
+            warehouse_id = $8
         WHERE purchase_id = $1
     `
 
-    result, err := r.db.Pool.Exec(
+    _, err = tx.Exec(
         ctx, query,
         purchase.PurchaseID,
         purchase.Date,
         purchase.SupplierID,
-        purchase.ItemID,
-        purchase.Quantity,
-        purchase.CostPerUnit,
-        purchase.TotalCost,
+        purchase.Status,
+        purchase.ExpectedDate,
         purchase.InvoiceNumber,
-        purchase.ReceivedBy,
         purchase.Notes,
+        purchase.WarehouseID,
     )
-
     if err != nil {
+        if isWarehouseViolation(err) {
+            return ErrWarehouseNotFound
+        }
         return err
     }
 
-    if result.RowsAffected() == 0 {
-        return errors.New("purchase not found")
+    if len(purchase.Lines) > 0 && oldStatus == purchasemodels.PurchaseStatusDraft {
+        if _, err = tx.Exec(ctx, `DELETE FROM purchase_lines WHERE purchase_id = $1`, purchase.PurchaseID); err != nil {
+            return err
+        }
+        if err = insertLines(ctx, tx, purchase, false); err != nil {
+            return err
+        }
     }
 
-    return nil
+    return tx.Commit(ctx)
 }
 
+// Delete removes a purchase order and takes whatever it brought in back out
+// of stock.
 func (r *PostgresPurchaseRepository) Delete(ctx context.Context, id int) error {
-    query := `DELETE FROM purchases WHERE purchase_id = $1`
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
 
-    result, err := r.db.Pool.Exec(ctx, query, id)
+    var warehouseID int
+    err = tx.QueryRow(ctx, `
+        SELECT warehouse_id FROM purchases WHERE purchase_id = $1 FOR UPDATE
+    `, id).Scan(&warehouseID)
     if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return errors.New("purchase not found")
+        }
+        return err
+    }
+
+    rows, err := tx.Query(ctx, `
+        SELECT item_id, quantity_received FROM purchase_lines
+        WHERE purchase_id = $1
+        FOR UPDATE
+    `, id)
+    if err != nil {
+        return err
+    }
+
+    deltas := map[int]int{}
+    for rows.Next() {
+        var itemID, quantity int
+        if err := rows.Scan(&itemID, &quantity); err != nil {
+            rows.Close()
+            return err
+        }
+        deltas[itemID] -= quantity
+    }
+    rows.Close()
+    if err = rows.Err(); err != nil {
         return err
     }
 
+    result, err := tx.Exec(ctx, `DELETE FROM purchases WHERE purchase_id = $1`, id)
+    if err != nil {
+        return err
+    }
     if result.RowsAffected() == 0 {
         return errors.New("purchase not found")
     }
 
-    return nil
+    // Reverse the stock that this purchase brought in
+    notes := "purchase deleted"
+    if err = r.applyStockDeltas(ctx, tx, id, warehouseID, deltas, &notes); err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+// Receive books a delivery against a purchase order. Received quantities are
+// added to stock and the order moves to partially-received or received.
+func (r *PostgresPurchaseRepository) Receive(ctx context.Context, purchaseID int, receipt *purchasemodels.PurchaseReceipt) error {
+    tx, err := r.db.Pool.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    var status string
+    var warehouseID int
+    err = tx.QueryRow(ctx, `
+        SELECT status, warehouse_id FROM purchases WHERE purchase_id = $1 FOR UPDATE
+    `, purchaseID).Scan(&status, &warehouseID)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return errors.New("purchase not found")
+        }
+        return err
+    }
+    if status != purchasemodels.PurchaseStatusOrdered &&
+        status != purchasemodels.PurchaseStatusPartiallyReceived {
+        return ErrPurchaseNotReceivable
+    }
+
+    rows, err := tx.Query(ctx, `
+        SELECT line_id, item_id, quantity_ordered, quantity_received
+        FROM purchase_lines
+        WHERE purchase_id = $1
+        ORDER BY line_id
+        FOR UPDATE
+    `, purchaseID)
+    if err != nil {
+        return err
+    }
+
+    var lines []*purchasemodels.PurchaseLine
+    for rows.Next() {
+        line := &purchasemodels.PurchaseLine{}
+        err := rows.Scan(&line.LineID, &line.ItemID, &line.QuantityOrdered, &line.QuantityReceived)
+        if err != nil {
+            rows.Close()
+            return err
+        }
+        lines = append(lines, line)
+    }
+    rows.Close()
+    if err = rows.Err(); err != nil {
+        return err
+    }
+
+    // Work out how much each line receives
+    received := map[int]int{}
+    for _, receiptLine := range receipt.Lines {
+        remaining := receiptLine.Quantity
+        matched := false
+        for _, line := range lines {
+            if receiptLine.LineID != 0 && line.LineID != receiptLine.LineID {
+                continue
+            }
+            if receiptLine.LineID == 0 && line.ItemID != receiptLine.ItemID {
+                continue
+            }
+            matched = true
+
+            outstanding := line.QuantityOrdered - line.QuantityReceived - received[line.LineID]
+            take := remaining
+            if take > outstanding {
+                take = outstanding
+            }
+            received[line.LineID] += take
+            remaining -= take
+            if remaining == 0 {
+                break
+            }
+        }
+        if !matched {
+            return ErrLineNotFound
+        }
+        if remaining > 0 {
+            return ErrOverReceipt
+        }
+    }
+
+    deltas := map[int]int{}
+    complete := true
+    for _, line := range lines {
+        if quantity := received[line.LineID]; quantity > 0 {
+            _, err = tx.Exec(ctx, `
+                UPDATE purchase_lines SET quantity_received = quantity_received + $2
+                WHERE line_id = $1
+            `, line.LineID, quantity)
+            if err != nil {
+                return err
+            }
+            deltas[line.ItemID] += quantity
+        }
+        if line.QuantityReceived+received[line.LineID] < line.QuantityOrdered {
+            complete = false
+        }
+    }
+
+    status = purchasemodels.PurchaseStatusPartiallyReceived
+    if complete {
+        status = purchasemodels.PurchaseStatusReceived
+    }
+
+    _, err = tx.Exec(ctx, `
+        UPDATE purchases SET
+            status = $2,
+            received_by_user_id = COALESCE($3, received_by_user_id),
+            invoice_number = COALESCE($4, invoice_number)
+        WHERE purchase_id = $1
+    `, purchaseID, status, receipt.ReceivedBy, receipt.InvoiceNumber)
+    if err != nil {
+        return err
+    }
+
+    // Only now does the delivery reach the shelf
+    notes := receipt.Notes
+    if notes == nil {
+        defaultNotes := "purchase received"
+        notes = &defaultNotes
+    }
+    if err = r.applyStockDeltas(ctx, tx, purchaseID, warehouseID, deltas, notes); err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
 }
 
 func (r *PostgresPurchaseRepository) GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*purchasemodels.Purchase, error) {
+    query := purchaseSelectQuery + " WHERE p.invoice_number = $1"
+
+    purchase, err := scanPurchase(r.db.Pool.QueryRow(ctx, query, invoiceNumber))
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    if err = r.loadLines(ctx, []*purchasemodels.Purchase{purchase}); err != nil {
+        return nil, err
+    }
+
+    return purchase, nil
+}
+
+func (r *PostgresPurchaseRepository) GetSupplierPurchases(ctx context.Context, supplierID int, outstandingOnly bool) ([]*purchasemodels.Purchase, error) {
+    filter := &purchasemodels.PurchaseFilter{
+        SupplierID:  &supplierID,
+        Outstanding: outstandingOnly,
+    }
+    return r.GetAll(ctx, filter, nil)
+}
+
+func (r *PostgresPurchaseRepository) GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error) {
+    filter := &purchasemodels.PurchaseFilter{
+        ItemID: &itemID,
+    }
+    return r.GetAll(ctx, filter, nil)
+}
+
+// GetScannedItem finds the item any of its barcodes belongs to, or returns
+// nil if no item has the barcode
+func (r *PostgresPurchaseRepository) GetScannedItem(ctx context.Context, barcode string) (*purchasemodels.ScannedItem, error) {
+    query := `
+        SELECT item_id, pack_quantity
+        FROM item_barcodes
+        WHERE barcode = $1
+    `
+
+    item := &purchasemodels.ScannedItem{}
+    err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(&item.ItemID, &item.PackQuantity)
+    if err != nil {
+        if errors.Is(err, pgx.ErrNoRows) {
+            return nil, nil
+        }
+        return nil, err
+    }
+
+    return item, nil
+}
+
+// loadLines fills in the lines and quantity totals of the given purchases
+func (r *PostgresPurchaseRepository) loadLines(ctx context.Context, purchases []*purchasemodels.Purchase) error {
+    if len(purchases) == 0 {
+        return nil
+    }
+
+    byID := make(map[int]*purchasemodels.Purchase, len(purchases))
+    ids := make([]int, 0, len(purchases))
+    for _, purchase := range purchases {
+        purchase.Lines = []*purchasemodels.PurchaseLine{}
+        byID[purchase.PurchaseID] = purchase
+        ids = append(ids, purchase.PurchaseID)
+    }
+
     query := `
         SELECT
-            p.purchase_id, p.date, p.supplier_id, p.item_id,
-            p.quantity, p.cost_per_unit, p.total_cost,
-            p.invoice_number, p.received_by, p.notes,
-            p.created_at, p.updated_at,
-            s.name as supplier_name,
+            pl.line_id, pl.purchase_id, pl.item_id,
+            pl.quantity_ordered, pl.quantity_received,
+            pl.cost_per_unit, pl.total_cost, pl.notes,
+            pl.created_at, pl.updated_at,
             i.part_number as item_part_number,
             i.description as item_description
-        FROM purchases p
-        JOIN suppliers s ON p.supplier_id = s.supplier_id
-        JOIN items i ON p.item_id = i.item_id
-        WHERE p.invoice_number = $1
+        FROM purchase_lines pl
+        JOIN items i ON pl.item_id = i.item_id
+        WHERE pl.purchase_id = ANY($1)
+        ORDER BY pl.purchase_id, pl.line_id
     `
 
+    rows, err := r.db.Pool.Query(ctx, query, ids)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        line := &purchasemodels.PurchaseLine{}
+        err := rows.Scan(
+            &line.LineID,
+            &line.PurchaseID,
+            &line.ItemID,
+            &line.QuantityOrdered,
+            &line.QuantityReceived,
+            &line.CostPerUnit,
+            &line.TotalCost,
+            &line.Notes,
+            &line.CreatedAt,
+            &line.UpdatedAt,
+            &line.ItemPartNumber,
+            &line.ItemDescription,
+        )
+        if err != nil {
+            return err
+        }
+
+        line.QuantityOutstanding = line.QuantityOrdered - line.QuantityReceived
+        if isClosed(byID[line.PurchaseID].Status) {
+            line.QuantityOutstanding = 0
+        }
+
+        purchase := byID[line.PurchaseID]
+        purchase.Lines = append(purchase.Lines, line)
+        purchase.TotalCost += line.TotalCost
+        purchase.QuantityOrdered += line.QuantityOrdered
+        purchase.QuantityReceived += line.QuantityReceived
+        purchase.QuantityOutstanding += line.QuantityOutstanding
+    }
+
+    return rows.Err()
+}
+
+// purchaseSortValue returns the value a purchase order is sorted by, as
+// PurchaseSorting compares it
+func purchaseSortValue(purchase *purchasemodels.Purchase, sort string) string {
+    switch sort {
+    case "id":
+        return strconv.Itoa(purchase.PurchaseID)
+    case "status":
+        return purchase.Status
+    case "invoice_number":
+        return pagination.String(purchase.InvoiceNumber)
+    case "supplier":
+        return purchase.SupplierName
+    default:
+        return pagination.Time(purchase.Date)
+    }
+}
+
+func scanPurchase(row pgx.Row) (*purchasemodels.Purchase, error) {
     purchase := &purchasemodels.Purchase{}
-    err := r.db.Pool.QueryRow(ctx, query, invoiceNumber).Scan(
+    err := row.Scan(
         &purchase.PurchaseID,
         &purchase.Date,
         &purchase.SupplierID,
-        &purchase.ItemID,
-        &purchase.Quantity,
-        &purchase.CostPerUnit,
-        &purchase.TotalCost,
+        &purchase.Status,
+        &purchase.ExpectedDate,
         &purchase.InvoiceNumber,
+        &purchase.ReceivedByUserID,
         &purchase.ReceivedBy,
         &purchase.Notes,
         &purchase.CreatedAt,
         &purchase.UpdatedAt,
         &purchase.SupplierName,
-        &purchase.ItemPartNumber,
-        &purchase.ItemDescription,
+        &purchase.WarehouseID,
     )
-
     if err != nil {
-        if errors.Is(err, pgx.ErrNoRows) {
-            return nil, nil
-        }
         return nil, err
     }
-
     return purchase, nil
 }
 
-func (r *PostgresPurchaseRepository) GetSupplierPurchases(ctx context.Context, supplierID int) ([]*purchasemodels.Purchase, error) {
-    filter := &purchasemodels.PurchaseFilter{
-        SupplierID: &supplierID,
+// insertLines stores the lines of a purchase. Lines of a purchase that
+// arrives with its delivery are stored as fully received.
+func insertLines(ctx context.Context, tx pgx.Tx, purchase *purchasemodels.Purchase, received bool) error {
+    for _, line := range purchase.Lines {
+        line.PurchaseID = purchase.PurchaseID
+        line.QuantityReceived = 0
+        if received {
+            line.QuantityReceived = line.QuantityOrdered
+        }
+
+        err := tx.QueryRow(ctx, `
+            INSERT INTO purchase_lines (
+                purchase_id, item_id, quantity_ordered, quantity_received,
+                cost_per_unit, total_cost, notes
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+            RETURNING line_id
+        `,
+            line.PurchaseID,
+            line.ItemID,
+            line.QuantityOrdered,
+            line.QuantityReceived,
+            line.CostPerUnit,
+            line.TotalCost,
+            line.Notes,
+        ).Scan(&line.LineID)
+        if err != nil {
+            var pgErr *pgconn.PgError
+            if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation &&
+                pgErr.ConstraintName == "purchase_lines_item_id_fkey" {
+                return ErrItemNotFound
+            }
+            return err
+        }
     }
-    return r.GetAll(ctx, filter)
+    return nil
 }
 
-func (r *PostgresPurchaseRepository) GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error) {
-    filter := &purchasemodels.PurchaseFilter{
-        ItemID: &itemID,
+// isWarehouseViolation reports whether err is a purchase naming a
+// warehouse that does not exist
+func isWarehouseViolation(err error) bool {
+    var pgErr *pgconn.PgError
+    return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation &&
+        pgErr.ConstraintName == "purchases_warehouse_id_fkey"
+}
+
+// isClosed reports whether nothing more is expected for a purchase
+func isClosed(status string) bool {
+    return status == purchasemodels.PurchaseStatusCancelled ||
+        status == purchasemodels.PurchaseStatusReceived
+}
+
+// applyStockDeltas records the stock changes caused by a purchase in the
+// stock ledger of its warehouse. Items are locked in ascending ID order so
+// concurrent transactions cannot deadlock. Taking stock back out fails with
+// ErrInsufficientStock when it has already been sold.
+func (r *PostgresPurchaseRepository) applyStockDeltas(ctx context.Context, tx pgx.Tx, purchaseID, warehouseID int, deltas map[int]int, notes *string) error {
+    itemIDs := make([]int, 0, len(deltas))
+    for itemID, delta := range deltas {
+        if delta != 0 {
+            itemIDs = append(itemIDs, itemID)
+        }
     }
-    return r.GetAll(ctx, filter)
+    sort.Ints(itemIDs)
+
+    referenceType := stockmovementmodels.ReferenceTypePurchase
+    for _, itemID := range itemIDs {
+        movement := &stockmovementmodels.StockMovement{
+            ItemID:        itemID,
+            WarehouseID:   warehouseID,
+            MovementType:  stockmovementmodels.MovementTypeIn,
+            Quantity:      deltas[itemID],
+            ReferenceID:   &purchaseID,
+            ReferenceType: &referenceType,
+            Notes:         notes,
+        }
+        if movement.Quantity < 0 {
+            movement.MovementType = stockmovementmodels.MovementTypeOut
+            movement.Quantity = -movement.Quantity
+        }
+
+        if err := r.movements.RecordTx(ctx, tx, movement); err != nil {
+            switch {
+            case errors.Is(err, stockmovementrepositories.ErrItemNotFound):
+                return ErrItemNotFound
+            case errors.Is(err, stockmovementrepositories.ErrInsufficientStock):
+                return ErrInsufficientStock
+            case errors.Is(err, stockmovementrepositories.ErrWarehouseNotFound):
+                return ErrWarehouseNotFound
+            default:
+                return err
+            }
+        }
+    }
+
+    return nil
 }
@@ -2,17 +2,31 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+)
+
+var (
+	ErrItemNotFound      = errors.New("item not found")
+	ErrInsufficientStock = errors.New("insufficient stock to reverse purchase")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+
+	ErrPurchaseNotReceivable = errors.New("only ordered purchases can be received")
+	ErrLineNotFound          = errors.New("purchase line not found")
+	ErrOverReceipt           = errors.New("received quantity exceeds the quantity outstanding")
 )
 
 type PurchaseRepository interface {
-	GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter) ([]*purchasemodels.Purchase, error)
+	GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter, page *pagination.Page) ([]*purchasemodels.Purchase, error)
 	GetByID(ctx context.Context, id int) (*purchasemodels.Purchase, error)
 	Create(ctx context.Context, purchase *purchasemodels.Purchase) (int, error)
 	Update(ctx context.Context, purchase *purchasemodels.Purchase) error
 	Delete(ctx context.Context, id int) error
+	Receive(ctx context.Context, purchaseID int, receipt *purchasemodels.PurchaseReceipt) error
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*purchasemodels.Purchase, error)
-	GetSupplierPurchases(ctx context.Context, supplierID int) ([]*purchasemodels.Purchase, error)
+	GetSupplierPurchases(ctx context.Context, supplierID int, outstandingOnly bool) ([]*purchasemodels.Purchase, error)
 	GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error)
+	GetScannedItem(ctx context.Context, barcode string) (*purchasemodels.ScannedItem, error)
 }
@@ -1,16 +1,20 @@
 package purchases
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/purchases/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/purchases/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/purchases/services"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
 	"github.com/hsrvms/autoparts/pkg/db"
 	"github.com/labstack/echo/v4"
 )
 
 func RegisterRoutes(api *echo.Group, database *db.Database) {
-    // Initialize repository
-    repo := repositories.NewPostgresPurchaseRepository(database)
+    // Initialize repositories
+    movementRepo := stockmovementrepositories.NewPostgresStockMovementRepository(database)
+    repo := repositories.NewPostgresPurchaseRepository(database, movementRepo)
 
     // Initialize service
     service := services.NewPurchaseService(repo)
@@ -18,15 +22,21 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize handler
     handler := handlers.NewPurchaseHandler(service)
 
+    // Permissions
+    read := authmiddleware.RequirePermission(authmodels.PermPurchasesRead)
+    write := authmiddleware.RequirePermission(authmodels.PermPurchasesWrite)
+    receive := authmiddleware.RequirePermission(authmodels.PermPurchasesReceive)
+
     // Register routes
     purchases := api.Group("/purchases")
-    purchases.GET("", handler.GetPurchases)
-    purchases.GET("/:id", handler.GetPurchaseByID)
-    purchases.POST("", handler.CreatePurchase)
-    purchases.PUT("/:id", handler.UpdatePurchase)
-    purchases.DELETE("/:id", handler.DeletePurchase)
+    purchases.GET("", handler.GetPurchases, read)
+    purchases.GET("/:id", handler.GetPurchaseByID, read)
+    purchases.POST("", handler.CreatePurchase, write)
+    purchases.PUT("/:id", handler.UpdatePurchase, write)
+    purchases.DELETE("/:id", handler.DeletePurchase, write)
+    purchases.POST("/:id/receive", handler.ReceivePurchase, receive)
 
     // Additional routes for supplier and item specific purchases
-    api.GET("/suppliers/:supplierId/purchases", handler.GetSupplierPurchases)
-    api.GET("/items/:itemId/purchases", handler.GetItemPurchases)
+    api.GET("/suppliers/:supplierId/purchases", handler.GetSupplierPurchases, read)
+    api.GET("/items/:itemId/purchases", handler.GetItemPurchases, read)
 }
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"math"
 	"time"
 
 	purchasemodels "github.com/hsrvms/autoparts/internal/modules/purchases/models"
 	"github.com/hsrvms/autoparts/internal/modules/purchases/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 var (
@@ -18,15 +20,34 @@ var (
 	ErrInvalidCostPerUnit     = errors.New("cost per unit must be greater than 0")
 	ErrDuplicateInvoiceNumber = errors.New("invoice number already exists")
 	ErrInvalidDate            = errors.New("purchase date cannot be in the future")
+	ErrItemNotFound           = repositories.ErrItemNotFound
+	ErrInsufficientStock      = repositories.ErrInsufficientStock
+	ErrEmptyPurchase          = errors.New("purchase must contain at least one line")
+	ErrInvalidStatus          = errors.New("status must be one of draft, ordered, partially-received, received or cancelled")
+	ErrInvalidStatusChange    = errors.New("purchase status cannot be changed this way")
+	ErrInvalidExpectedDate    = errors.New("expected date cannot be before the purchase date")
+	ErrPurchaseLocked         = errors.New("lines can only be changed while the purchase is a draft")
+	ErrEmptyReceipt           = errors.New("receipt must contain at least one line")
+	ErrPurchaseNotReceivable  = repositories.ErrPurchaseNotReceivable
+	ErrLineNotFound           = repositories.ErrLineNotFound
+	ErrOverReceipt            = repositories.ErrOverReceipt
+	ErrBarcodeNotFound        = errors.New("no item has this barcode")
+	ErrInvalidWarehouseID     = errors.New("invalid warehouse ID")
+	ErrWarehouseNotFound      = repositories.ErrWarehouseNotFound
+	ErrWarehouseLocked        = errors.New("warehouse cannot be changed once stock has been received")
 )
 
+// PurchaseSorting lists the fields purchase orders can be sorted by
+var PurchaseSorting = repositories.PurchaseSorting
+
 type PurchaseService interface {
-	GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter) ([]*purchasemodels.Purchase, error)
+	GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter, page *pagination.Page) ([]*purchasemodels.Purchase, error)
 	GetByID(ctx context.Context, id int) (*purchasemodels.Purchase, error)
 	Create(ctx context.Context, purchase *purchasemodels.Purchase) (int, error)
 	Update(ctx context.Context, purchase *purchasemodels.Purchase) error
 	Delete(ctx context.Context, id int) error
-	GetSupplierPurchases(ctx context.Context, supplierID int) ([]*purchasemodels.Purchase, error)
+	Receive(ctx context.Context, id int, receipt *purchasemodels.PurchaseReceipt) (*purchasemodels.Purchase, error)
+	GetSupplierPurchases(ctx context.Context, supplierID int, outstandingOnly bool) ([]*purchasemodels.Purchase, error)
 	GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error)
 }
 
@@ -40,8 +61,8 @@ func NewPurchaseService(repo repositories.PurchaseRepository) PurchaseService {
 	}
 }
 
-func (s *purchaseService) GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter) ([]*purchasemodels.Purchase, error) {
-	return s.repo.GetAll(ctx, filter)
+func (s *purchaseService) GetAll(ctx context.Context, filter *purchasemodels.PurchaseFilter, page *pagination.Page) ([]*purchasemodels.Purchase, error) {
+	return s.repo.GetAll(ctx, filter, page)
 }
 
 func (s *purchaseService) GetByID(ctx context.Context, id int) (*purchasemodels.Purchase, error) {
@@ -61,10 +82,24 @@ func (s *purchaseService) GetByID(ctx context.Context, id int) (*purchasemodels.
 }
 
 func (s *purchaseService) Create(ctx context.Context, purchase *purchasemodels.Purchase) (int, error) {
+	// New orders start as drafts unless told otherwise
+	if purchase.Status == "" {
+		purchase.Status = purchasemodels.PurchaseStatusDraft
+	}
+	switch purchase.Status {
+	case purchasemodels.PurchaseStatusDraft, purchasemodels.PurchaseStatusOrdered,
+		purchasemodels.PurchaseStatusReceived:
+	default:
+		return 0, ErrInvalidStatusChange
+	}
+
 	// Validate the purchase
 	if err := s.validatePurchase(purchase); err != nil {
 		return 0, err
 	}
+	if err := s.validateLines(purchase.Lines); err != nil {
+		return 0, err
+	}
 
 	// Check if invoice number is unique if provided
 	if purchase.InvoiceNumber != nil && *purchase.InvoiceNumber != "" {
@@ -82,11 +117,6 @@ func (s *purchaseService) Create(ctx context.Context, purchase *purchasemodels.P
 		purchase.Date = time.Now()
 	}
 
-	// Calculate total cost if not provided
-	if purchase.TotalCost == 0 {
-		purchase.TotalCost = float64(purchase.Quantity) * purchase.CostPerUnit
-	}
-
 	return s.repo.Create(ctx, purchase)
 }
 
@@ -95,11 +125,6 @@ func (s *purchaseService) Update(ctx context.Context, purchase *purchasemodels.P
 		return ErrInvalidPurchaseID
 	}
 
-	// Validate the purchase
-	if err := s.validatePurchase(purchase); err != nil {
-		return err
-	}
-
 	// Check if purchase exists
 	existing, err := s.repo.GetByID(ctx, purchase.PurchaseID)
 	if err != nil {
@@ -109,6 +134,39 @@ func (s *purchaseService) Update(ctx context.Context, purchase *purchasemodels.P
 		return ErrPurchaseNotFound
 	}
 
+	if purchase.Status == "" {
+		purchase.Status = existing.Status
+	}
+	if purchase.Date.IsZero() {
+		purchase.Date = existing.Date
+	}
+	if purchase.WarehouseID == 0 {
+		purchase.WarehouseID = existing.WarehouseID
+	}
+
+	// Validate the purchase
+	if err := s.validatePurchase(purchase); err != nil {
+		return err
+	}
+	if !canChangeStatus(existing.Status, purchase.Status) {
+		return ErrInvalidStatusChange
+	}
+
+	// Received stock stays in the warehouse it was booked into
+	if purchase.WarehouseID != existing.WarehouseID && existing.QuantityReceived > 0 {
+		return ErrWarehouseLocked
+	}
+
+	// Lines are fixed once the order has gone out to the supplier
+	if len(purchase.Lines) > 0 {
+		if existing.Status != purchasemodels.PurchaseStatusDraft {
+			return ErrPurchaseLocked
+		}
+		if err := s.validateLines(purchase.Lines); err != nil {
+			return err
+		}
+	}
+
 	// Check if invoice number is unique if changed
 	if purchase.InvoiceNumber != nil && *purchase.InvoiceNumber != "" &&
 		(existing.InvoiceNumber == nil || *purchase.InvoiceNumber != *existing.InvoiceNumber) {
@@ -121,9 +179,6 @@ func (s *purchaseService) Update(ctx context.Context, purchase *purchasemodels.P
 		}
 	}
 
-	// Recalculate total cost
-	purchase.TotalCost = float64(purchase.Quantity) * purchase.CostPerUnit
-
 	return s.repo.Update(ctx, purchase)
 }
 
@@ -144,11 +199,62 @@ func (s *purchaseService) Delete(ctx context.Context, id int) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *purchaseService) GetSupplierPurchases(ctx context.Context, supplierID int) ([]*purchasemodels.Purchase, error) {
+// Receive records a delivery against a purchase order and returns the
+// updated order
+func (s *purchaseService) Receive(ctx context.Context, id int, receipt *purchasemodels.PurchaseReceipt) (*purchasemodels.Purchase, error) {
+	if id <= 0 {
+		return nil, ErrInvalidPurchaseID
+	}
+	if len(receipt.Lines) == 0 {
+		return nil, ErrEmptyReceipt
+	}
+	for i := range receipt.Lines {
+		if err := s.resolveBarcode(ctx, &receipt.Lines[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, line := range receipt.Lines {
+		if line.LineID <= 0 && line.ItemID <= 0 {
+			return nil, ErrLineNotFound
+		}
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+	}
+
+	// Check if purchase exists
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrPurchaseNotFound
+	}
+
+	// Check if invoice number is unique if changed
+	if receipt.InvoiceNumber != nil && *receipt.InvoiceNumber != "" &&
+		(existing.InvoiceNumber == nil || *receipt.InvoiceNumber != *existing.InvoiceNumber) {
+		existingWithInvoice, err := s.repo.GetByInvoiceNumber(ctx, *receipt.InvoiceNumber)
+		if err != nil {
+			return nil, err
+		}
+		if existingWithInvoice != nil && existingWithInvoice.PurchaseID != id {
+			return nil, ErrDuplicateInvoiceNumber
+		}
+	}
+
+	if err := s.repo.Receive(ctx, id, receipt); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *purchaseService) GetSupplierPurchases(ctx context.Context, supplierID int, outstandingOnly bool) ([]*purchasemodels.Purchase, error) {
 	if supplierID <= 0 {
 		return nil, ErrInvalidSupplierID
 	}
-	return s.repo.GetSupplierPurchases(ctx, supplierID)
+	return s.repo.GetSupplierPurchases(ctx, supplierID, outstandingOnly)
 }
 
 func (s *purchaseService) GetItemPurchases(ctx context.Context, itemID int) ([]*purchasemodels.Purchase, error) {
@@ -159,21 +265,86 @@ func (s *purchaseService) GetItemPurchases(ctx context.Context, itemID int) ([]*
 }
 
 // Helper functions
+
+// resolveBarcode fills in the item of a receipt line scanned by barcode.
+// Quantity counts scans, one when left out, and becomes units of the
+// barcode's pack.
+func (s *purchaseService) resolveBarcode(ctx context.Context, line *purchasemodels.ReceiptLine) error {
+	if line.Barcode == "" || line.LineID != 0 || line.ItemID != 0 {
+		return nil
+	}
+
+	scanned, err := s.repo.GetScannedItem(ctx, line.Barcode)
+	if err != nil {
+		return err
+	}
+	if scanned == nil {
+		return ErrBarcodeNotFound
+	}
+
+	if line.Quantity == 0 {
+		line.Quantity = 1
+	}
+	line.ItemID = scanned.ItemID
+	line.Quantity *= scanned.PackQuantity
+	return nil
+}
+
 func (s *purchaseService) validatePurchase(purchase *purchasemodels.Purchase) error {
 	if purchase.SupplierID <= 0 {
 		return ErrInvalidSupplierID
 	}
-	if purchase.ItemID <= 0 {
-		return ErrInvalidItemID
-	}
-	if purchase.Quantity <= 0 {
-		return ErrInvalidQuantity
+	if purchase.WarehouseID < 0 {
+		return ErrInvalidWarehouseID
 	}
-	if purchase.CostPerUnit <= 0 {
-		return ErrInvalidCostPerUnit
+	switch purchase.Status {
+	case purchasemodels.PurchaseStatusDraft, purchasemodels.PurchaseStatusOrdered,
+		purchasemodels.PurchaseStatusPartiallyReceived, purchasemodels.PurchaseStatusReceived,
+		purchasemodels.PurchaseStatusCancelled:
+	default:
+		return ErrInvalidStatus
 	}
 	if !purchase.Date.IsZero() && purchase.Date.After(time.Now()) {
 		return ErrInvalidDate
 	}
+	if purchase.ExpectedDate != nil && !purchase.Date.IsZero() &&
+		purchase.ExpectedDate.Before(purchase.Date.Truncate(24*time.Hour)) {
+		return ErrInvalidExpectedDate
+	}
+	return nil
+}
+
+func (s *purchaseService) validateLines(lines []*purchasemodels.PurchaseLine) error {
+	if len(lines) == 0 {
+		return ErrEmptyPurchase
+	}
+	for _, line := range lines {
+		if line.ItemID <= 0 {
+			return ErrInvalidItemID
+		}
+		if line.QuantityOrdered <= 0 {
+			return ErrInvalidQuantity
+		}
+		if line.CostPerUnit <= 0 {
+			return ErrInvalidCostPerUnit
+		}
+		line.TotalCost = math.Round(float64(line.QuantityOrdered)*line.CostPerUnit*100) / 100
+	}
 	return nil
 }
+
+// canChangeStatus reports whether a purchase may be moved from one status to
+// another by editing it. Receiving is done through Receive instead.
+func canChangeStatus(from, to string) bool {
+	if from == to {
+		return true
+	}
+	switch from {
+	case purchasemodels.PurchaseStatusDraft:
+		return to == purchasemodels.PurchaseStatusOrdered || to == purchasemodels.PurchaseStatusCancelled
+	case purchasemodels.PurchaseStatusOrdered, purchasemodels.PurchaseStatusPartiallyReceived:
+		return to == purchasemodels.PurchaseStatusCancelled
+	default:
+		return false
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"strconv"
 	"time"
 
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
 	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
 	"github.com/hsrvms/autoparts/internal/modules/sales/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/labstack/echo/v4"
 )
 
@@ -20,8 +22,13 @@ func NewSaleHandler(service services.SaleService) *SaleHandler {
 	}
 }
 
-// GetSales handles retrieval of all sales with optional filtering
+// GetSales handles retrieval of a page of sales with optional filtering
 func (h *SaleHandler) GetSales(c echo.Context) error {
+	page, err := pagination.FromRequest(c, services.SaleSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	filter := &salesmodels.SaleFilter{}
 
 	// Parse query parameters
@@ -60,17 +67,31 @@ func (h *SaleHandler) GetSales(c echo.Context) error {
 		filter.TransactionNumber = &transactionNumber
 	}
 
+	if soldByUserID := c.QueryParam("sold_by_user_id"); soldByUserID != "" {
+		id, err := strconv.Atoi(soldByUserID)
+		if err == nil {
+			filter.SoldByUserID = &id
+		}
+	}
+
 	if soldBy := c.QueryParam("sold_by"); soldBy != "" {
 		filter.SoldBy = &soldBy
 	}
 
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
 	ctx := c.Request().Context()
-	sales, err := h.service.GetAll(ctx, filter)
+	sales, err := h.service.GetAll(ctx, filter, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, sales)
+	return pagination.Respond(c, page, sales)
 }
 
 // GetSaleByID handles retrieval of a single sale
@@ -94,21 +115,33 @@ func (h *SaleHandler) GetSaleByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, sale)
 }
 
-// CreateSale handles creation of a new sale
+// CreateSale handles creation of a sale transaction with one or more lines
 func (h *SaleHandler) CreateSale(c echo.Context) error {
-	sale := new(salesmodels.Sale)
-	if err := c.Bind(sale); err != nil {
+	req := new(salesmodels.CreateSaleRequest)
+	if err := c.Bind(req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	// The seller is whoever is signed in, whatever the body says
+	txn := req.Transaction()
+	txn.SoldBy, txn.SoldByUserID = nil, nil
+	if user := authmiddleware.CurrentUser(c); user != nil {
+		txn.SoldByUserID = &user.UserID
+	}
+
 	ctx := c.Request().Context()
-	id, err := h.service.Create(ctx, sale)
+	txn, err := h.service.Create(ctx, txn)
 	if err != nil {
 		switch err {
-		case services.ErrInvalidItemID, services.ErrInvalidQuantity,
-			services.ErrInvalidPricePerUnit, services.ErrInvalidDate,
-			services.ErrInvalidCustomerEmail:
+		case services.ErrSellerRequired:
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		case services.ErrEmptySale, services.ErrInvalidItemID, services.ErrInvalidQuantity,
+			services.ErrInvalidPricePerUnit, services.ErrInvalidLineDiscount,
+			services.ErrInvalidDate, services.ErrInvalidCustomerEmail,
+			services.ErrInvalidWarehouseID:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound, services.ErrBarcodeNotFound, services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case services.ErrDuplicateTransactionNumber:
 			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		case services.ErrInsufficientStock:
@@ -118,8 +151,7 @@ func (h *SaleHandler) CreateSale(c echo.Context) error {
 		}
 	}
 
-	sale.SaleID = id
-	return c.JSON(http.StatusCreated, sale)
+	return c.JSON(http.StatusCreated, txn)
 }
 
 // UpdateSale handles updating an existing sale
@@ -139,14 +171,12 @@ func (h *SaleHandler) UpdateSale(c echo.Context) error {
 	err = h.service.Update(ctx, sale)
 	if err != nil {
 		switch err {
-		case services.ErrSaleNotFound:
+		case services.ErrSaleNotFound, services.ErrItemNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		case services.ErrInvalidItemID, services.ErrInvalidQuantity,
-			services.ErrInvalidPricePerUnit, services.ErrInvalidDate,
-			services.ErrInvalidCustomerEmail:
+			services.ErrInvalidPricePerUnit, services.ErrInvalidLineDiscount,
+			services.ErrInvalidDate:
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		case services.ErrDuplicateTransactionNumber:
-			return echo.NewHTTPError(http.StatusConflict, err.Error())
 		case services.ErrInsufficientStock:
 			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
 		default:
@@ -168,7 +198,7 @@ func (h *SaleHandler) DeleteSale(c echo.Context) error {
 	err = h.service.Delete(ctx, id)
 	if err != nil {
 		switch err {
-		case services.ErrSaleNotFound:
+		case services.ErrSaleNotFound, services.ErrItemNotFound:
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		default:
 			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
@@ -178,7 +208,28 @@ func (h *SaleHandler) DeleteSale(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
-// GetByTransactionNumber handles retrieval of a sale by transaction number
+// DeleteTransaction handles voiding a whole sale transaction
+func (h *SaleHandler) DeleteTransaction(c echo.Context) error {
+	transactionNumber := c.Param("transactionNumber")
+	if transactionNumber == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "transaction number is required")
+	}
+
+	ctx := c.Request().Context()
+	err := h.service.DeleteTransaction(ctx, transactionNumber)
+	if err != nil {
+		switch err {
+		case services.ErrSaleNotFound, services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetByTransactionNumber handles retrieval of a sale transaction with all its lines
 func (h *SaleHandler) GetByTransactionNumber(c echo.Context) error {
 	transactionNumber := c.Param("transactionNumber")
 	if transactionNumber == "" {
@@ -212,3 +263,60 @@ func (h *SaleHandler) GetCustomerSales(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, sales)
 }
+
+// GetEmployeeSales handles the per-employee sales report
+func (h *SaleHandler) GetEmployeeSales(c echo.Context) error {
+	filter := &salesmodels.EmployeeSalesFilter{}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	if userID := c.QueryParam("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err == nil {
+			filter.UserID = &id
+		}
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetEmployeeSales(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetItemSalesReport handles the per-part sales report
+func (h *SaleHandler) GetItemSalesReport(c echo.Context) error {
+	filter := &salesmodels.ItemSalesFilter{}
+
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		if date, err := time.Parse(time.RFC3339, startDate); err == nil {
+			filter.StartDate = &date
+		}
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		if date, err := time.Parse(time.RFC3339, endDate); err == nil {
+			filter.EndDate = &date
+		}
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.service.GetItemSalesReport(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
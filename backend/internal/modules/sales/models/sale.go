@@ -2,17 +2,21 @@ package salesmodels
 
 import "time"
 
+// Sale is a single line of a sale transaction
 type Sale struct {
 	SaleID            int       `json:"sale_id" db:"sale_id"`
+	TransactionID     int       `json:"transaction_id" db:"transaction_id"`
 	Date              time.Time `json:"date" db:"date"`
 	ItemID            int       `json:"item_id" db:"item_id"`
 	Quantity          int       `json:"quantity" db:"quantity"`
 	PricePerUnit      float64   `json:"price_per_unit" db:"price_per_unit"`
+	LineDiscount      float64   `json:"line_discount" db:"line_discount"`
 	TotalPrice        float64   `json:"total_price" db:"total_price"`
 	TransactionNumber string    `json:"transaction_number" db:"transaction_number"`
 	CustomerName      *string   `json:"customer_name,omitempty" db:"customer_name"`
 	CustomerPhone     *string   `json:"customer_phone,omitempty" db:"customer_phone"`
 	CustomerEmail     *string   `json:"customer_email,omitempty" db:"customer_email"`
+	SoldByUserID      *int      `json:"sold_by_user_id,omitempty" db:"sold_by_user_id"`
 	SoldBy            *string   `json:"sold_by,omitempty" db:"sold_by"`
 	Notes             *string   `json:"notes,omitempty" db:"notes"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
@@ -22,6 +26,74 @@ type Sale struct {
 	ItemPartNumber  string `json:"item_part_number,omitempty" db:"item_part_number"`
 	ItemDescription string `json:"item_description,omitempty" db:"item_description"`
 	CategoryName    string `json:"name,omitempty" db:"name"`
+
+	// Barcode is what was scanned for a new line. Given without an item ID,
+	// it picks the item and Quantity counts packs of the barcode.
+	Barcode string `json:"barcode,omitempty" db:"-"`
+
+	// WarehouseID is the warehouse of the line's transaction
+	WarehouseID int `json:"warehouse_id" db:"warehouse_id"`
+}
+
+// ScannedItem is the item a barcode was scanned for, with the number of
+// units one scan stands for
+type ScannedItem struct {
+	ItemID       int     `db:"item_id"`
+	PackQuantity int     `db:"pack_quantity"`
+	SellPrice    float64 `db:"sell_price"`
+}
+
+// SaleTransaction is the header of a receipt. Customer and seller details
+// live here and are shared by every line. The seller is always the user who
+// rang up the sale; SoldBy carries their display name. Stock leaves the
+// warehouse of the transaction, the default one when none is given.
+type SaleTransaction struct {
+	TransactionID     int       `json:"transaction_id" db:"transaction_id"`
+	TransactionNumber string    `json:"transaction_number" db:"transaction_number"`
+	Date              time.Time `json:"date" db:"date"`
+	CustomerName      *string   `json:"customer_name,omitempty" db:"customer_name"`
+	CustomerPhone     *string   `json:"customer_phone,omitempty" db:"customer_phone"`
+	CustomerEmail     *string   `json:"customer_email,omitempty" db:"customer_email"`
+	SoldByUserID      *int      `json:"sold_by_user_id,omitempty" db:"sold_by_user_id"`
+	SoldBy            *string   `json:"sold_by,omitempty" db:"sold_by"`
+	Notes             *string   `json:"notes,omitempty" db:"notes"`
+	Lines             []*Sale   `json:"lines"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+
+	WarehouseID int `json:"warehouse_id" db:"warehouse_id"`
+
+	// Totals calculated from the lines
+	Subtotal      float64 `json:"subtotal"`
+	DiscountTotal float64 `json:"discount_total"`
+	Total         float64 `json:"total"`
+	ItemCount     int     `json:"item_count"`
+}
+
+// CreateSaleRequest is the body of POST /sales. It carries a full basket in
+// Lines; older clients that post a single item at the top level are turned
+// into a one-line transaction.
+type CreateSaleRequest struct {
+	SaleTransaction
+	ItemID       int     `json:"item_id"`
+	Quantity     int     `json:"quantity"`
+	PricePerUnit float64 `json:"price_per_unit"`
+	LineDiscount float64 `json:"line_discount"`
+}
+
+// Transaction returns the transaction described by the request
+func (r *CreateSaleRequest) Transaction() *SaleTransaction {
+	txn := r.SaleTransaction
+	if len(txn.Lines) == 0 && r.ItemID != 0 {
+		txn.Lines = []*Sale{{
+			ItemID:       r.ItemID,
+			Quantity:     r.Quantity,
+			PricePerUnit: r.PricePerUnit,
+			LineDiscount: r.LineDiscount,
+			Notes:        txn.Notes,
+		}}
+	}
+	return &txn
 }
 
 type SaleFilter struct {
@@ -32,5 +104,44 @@ type SaleFilter struct {
 	CustomerPhone     *string    `query:"customer_phone"`
 	CustomerEmail     *string    `query:"customer_email"`
 	TransactionNumber *string    `query:"transaction_number"`
+	SoldByUserID      *int       `query:"sold_by_user_id"`
 	SoldBy            *string    `query:"sold_by"`
+	WarehouseID       *int       `query:"warehouse_id"`
+}
+
+// EmployeeSales sums up the sales rung up by one employee
+type EmployeeSales struct {
+	UserID           *int    `json:"user_id,omitempty" db:"user_id"`
+	Name             string  `json:"name" db:"name"`
+	TransactionCount int     `json:"transaction_count" db:"transaction_count"`
+	ItemCount        int     `json:"item_count" db:"item_count"`
+	Subtotal         float64 `json:"subtotal" db:"subtotal"`
+	DiscountTotal    float64 `json:"discount_total" db:"discount_total"`
+	Total            float64 `json:"total" db:"total"`
+	AverageSale      float64 `json:"average_sale"`
+}
+
+type EmployeeSalesFilter struct {
+	StartDate *time.Time `query:"start_date"`
+	EndDate   *time.Time `query:"end_date"`
+	UserID    *int       `query:"user_id"`
+}
+
+// ItemSales sums up the sales of one part. Sales made under the part
+// numbers it superseded are counted towards it.
+type ItemSales struct {
+	ItemID      int     `json:"item_id" db:"item_id"`
+	PartNumber  string  `json:"part_number" db:"part_number"`
+	Description *string `json:"description,omitempty" db:"description"`
+	SaleCount   int     `json:"sale_count" db:"sale_count"`
+	Quantity    int     `json:"quantity" db:"quantity"`
+	// SupersededQuantity is how much of Quantity was sold under older part
+	// numbers
+	SupersededQuantity int     `json:"superseded_quantity" db:"superseded_quantity"`
+	Total              float64 `json:"total" db:"total"`
+}
+
+type ItemSalesFilter struct {
+	StartDate *time.Time `query:"start_date"`
+	EndDate   *time.Time `query:"end_date"`
 }
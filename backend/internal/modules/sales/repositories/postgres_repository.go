@@ -4,38 +4,73 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// uniqueViolation is the Postgres error code for a unique constraint failure
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is the Postgres error code for a missing referenced row
+const foreignKeyViolation = "23503"
+
 type PostgresSaleRepository struct {
-	db *db.Database
+	db        *db.Database
+	movements stockmovementrepositories.StockMovementRepository
 }
 
-func NewPostgresSaleRepository(database *db.Database) SaleRepository {
+func NewPostgresSaleRepository(database *db.Database, movements stockmovementrepositories.StockMovementRepository) SaleRepository {
 	return &PostgresSaleRepository{
-		db: database,
+		db:        database,
+		movements: movements,
 	}
 }
 
-func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error) {
-	query := `
+// saleSelectQuery selects sale lines together with their transaction
+// header and item details
+const saleSelectQuery = `
         SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
-            s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
+            s.sale_id, s.transaction_id, s.date, s.item_id, s.quantity,
+            s.price_per_unit, s.line_discount, s.total_price,
+            t.transaction_number, t.customer_name, t.customer_phone,
+            t.customer_email, t.sold_by_user_id,
+            COALESCE(u.full_name, u.username, t.sold_by) as sold_by,
+            s.notes, s.created_at, s.updated_at,
             i.part_number as item_part_number,
             i.description as item_description,
-            c.name
+            c.name, t.warehouse_id
         FROM sales s
+        JOIN sale_transactions t ON s.transaction_id = t.transaction_id
+        LEFT JOIN users u ON t.sold_by_user_id = u.user_id
         JOIN items i ON s.item_id = i.item_id
         LEFT JOIN categories c ON i.category_id = c.category_id
-        WHERE 1=1
-    `
+`
+
+// SaleSorting lists the fields sale lines can be sorted by
+var SaleSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":                 {Column: "s.sale_id", Type: "integer"},
+		"date":               {Column: "s.date", Type: "timestamptz"},
+		"quantity":           {Column: "s.quantity", Type: "integer"},
+		"total_price":        {Column: "s.total_price", Type: "numeric"},
+		"transaction_number": {Column: "t.transaction_number", Type: "text"},
+		"part_number":        {Column: "i.part_number", Type: "text"},
+	},
+	Default:  "-date",
+	IDColumn: "s.sale_id",
+}
+
+func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels.SaleFilter, page *pagination.Page) ([]*salesmodels.Sale, error) {
+	query := saleSelectQuery + " WHERE 1=1"
 
 	var conditions []string
 	var params []interface{}
@@ -61,32 +96,44 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels
 		}
 
 		if filter.CustomerName != nil {
-			conditions = append(conditions, fmt.Sprintf("s.customer_name ILIKE $%d", paramCount))
+			conditions = append(conditions, fmt.Sprintf("t.customer_name ILIKE $%d", paramCount))
 			params = append(params, "%"+*filter.CustomerName+"%")
 			paramCount++
 		}
 
 		if filter.CustomerPhone != nil {
-			conditions = append(conditions, fmt.Sprintf("s.customer_phone = $%d", paramCount))
+			conditions = append(conditions, fmt.Sprintf("t.customer_phone = $%d", paramCount))
 			params = append(params, *filter.CustomerPhone)
 			paramCount++
 		}
 
 		if filter.CustomerEmail != nil {
-			conditions = append(conditions, fmt.Sprintf("s.customer_email = $%d", paramCount))
+			conditions = append(conditions, fmt.Sprintf("t.customer_email = $%d", paramCount))
 			params = append(params, *filter.CustomerEmail)
 			paramCount++
 		}
 
 		if filter.TransactionNumber != nil {
-			conditions = append(conditions, fmt.Sprintf("s.transaction_number = $%d", paramCount))
+			conditions = append(conditions, fmt.Sprintf("t.transaction_number = $%d", paramCount))
 			params = append(params, *filter.TransactionNumber)
 			paramCount++
 		}
 
+		if filter.SoldByUserID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.sold_by_user_id = $%d", paramCount))
+			params = append(params, *filter.SoldByUserID)
+			paramCount++
+		}
+
 		if filter.SoldBy != nil {
-			conditions = append(conditions, fmt.Sprintf("s.sold_by = $%d", paramCount))
-			params = append(params, *filter.SoldBy)
+			conditions = append(conditions, fmt.Sprintf("COALESCE(u.full_name, u.username, t.sold_by) ILIKE $%d", paramCount))
+			params = append(params, "%"+*filter.SoldBy+"%")
+			paramCount++
+		}
+
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.warehouse_id = $%d", paramCount))
+			params = append(params, *filter.WarehouseID)
 			paramCount++
 		}
 	}
@@ -95,82 +142,30 @@ func (r *PostgresSaleRepository) GetAll(ctx context.Context, filter *salesmodels
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY s.date DESC"
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), params...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
 
-	rows, err := r.db.Pool.Query(ctx, query, params...)
+	query, params = SaleSorting.Apply(query, params, page)
+	sales, err := r.querySales(ctx, query, params...)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var sales []*salesmodels.Sale
-	for rows.Next() {
-		sale := &salesmodels.Sale{}
-		err := rows.Scan(
-			&sale.SaleID,
-			&sale.Date,
-			&sale.ItemID,
-			&sale.Quantity,
-			&sale.PricePerUnit,
-			&sale.TotalPrice,
-			&sale.TransactionNumber,
-			&sale.CustomerName,
-			&sale.CustomerPhone,
-			&sale.CustomerEmail,
-			&sale.SoldBy,
-			&sale.Notes,
-			&sale.CreatedAt,
-			&sale.UpdatedAt,
-			&sale.ItemPartNumber,
-			&sale.ItemDescription,
-			&sale.CategoryName,
-		)
-		if err != nil {
-			return nil, err
-		}
-		sales = append(sales, sale)
+	if page != nil && len(sales) > 0 {
+		last := sales[len(sales)-1]
+		page.SetNextCursor(len(sales), saleSortValue(last, page.Sort), last.SaleID)
 	}
 
-	return sales, rows.Err()
+	return sales, nil
 }
 
 func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*salesmodels.Sale, error) {
-	query := `
-        SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
-            s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.name
-        FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        LEFT JOIN categories c ON i.category_id = c.category_id
-        WHERE s.sale_id = $1
-    `
-
-	sale := &salesmodels.Sale{}
-	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
-		&sale.SaleID,
-		&sale.Date,
-		&sale.ItemID,
-		&sale.Quantity,
-		&sale.PricePerUnit,
-		&sale.TotalPrice,
-		&sale.TransactionNumber,
-		&sale.CustomerName,
-		&sale.CustomerPhone,
-		&sale.CustomerEmail,
-		&sale.SoldBy,
-		&sale.Notes,
-		&sale.CreatedAt,
-		&sale.UpdatedAt,
-		&sale.ItemPartNumber,
-		&sale.ItemDescription,
-		&sale.CategoryName,
-	)
+	query := saleSelectQuery + " WHERE s.sale_id = $1"
 
+	sale, err := scanSale(r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -181,138 +176,547 @@ func (r *PostgresSaleRepository) GetByID(ctx context.Context, id int) (*salesmod
 	return sale, nil
 }
 
-func (r *PostgresSaleRepository) Create(ctx context.Context, sale *salesmodels.Sale) (int, error) {
+func (r *PostgresSaleRepository) CreateTransaction(ctx context.Context, txn *salesmodels.SaleTransaction) (int, error) {
 	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback(ctx)
 
-	// Insert the sale
+	// Insert the header, numbering it when the client did not and selling
+	// from the default warehouse when it named none
 	query := `
-        INSERT INTO sales (
-            date, item_id, quantity, price_per_unit,
-            total_price, transaction_number, customer_name,
-            customer_phone, customer_email, sold_by, notes
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-        RETURNING sale_id
+        INSERT INTO sale_transactions (
+            transaction_number, date, customer_name,
+            customer_phone, customer_email, sold_by_user_id, notes, warehouse_id
+        ) VALUES (
+            COALESCE(NULLIF($1, ''), 'S' || to_char(CURRENT_DATE, 'YYYYMMDD') || '-' ||
+                lpad(nextval('sale_transaction_number_seq')::text, 6, '0')),
+            $2, $3, $4, $5, $6, $7,
+            COALESCE(NULLIF($8, 0), (SELECT warehouse_id FROM warehouses WHERE is_default))
+        )
+        RETURNING transaction_id, transaction_number, warehouse_id, created_at, updated_at
     `
 
-	var id int
 	err = tx.QueryRow(
 		ctx, query,
-		sale.Date,
-		sale.ItemID,
-		sale.Quantity,
-		sale.PricePerUnit,
-		sale.TotalPrice,
-		sale.TransactionNumber,
-		sale.CustomerName,
-		sale.CustomerPhone,
-		sale.CustomerEmail,
-		sale.SoldBy,
-		sale.Notes,
-	).Scan(&id)
-
+		txn.TransactionNumber,
+		txn.Date,
+		txn.CustomerName,
+		txn.CustomerPhone,
+		txn.CustomerEmail,
+		txn.SoldByUserID,
+		txn.Notes,
+		txn.WarehouseID,
+	).Scan(&txn.TransactionID, &txn.TransactionNumber, &txn.WarehouseID, &txn.CreatedAt, &txn.UpdatedAt)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case uniqueViolation:
+				return 0, ErrDuplicateTransactionNumber
+			case foreignKeyViolation:
+				return 0, ErrWarehouseNotFound
+			}
+		}
 		return 0, err
 	}
 
+	// Insert the lines
+	for _, line := range txn.Lines {
+		line.TransactionID = txn.TransactionID
+		line.Date = txn.Date
+
+		err = tx.QueryRow(ctx, `
+            INSERT INTO sales (
+                transaction_id, date, item_id, quantity,
+                price_per_unit, line_discount, total_price, notes
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            RETURNING sale_id
+        `,
+			line.TransactionID,
+			line.Date,
+			line.ItemID,
+			line.Quantity,
+			line.PricePerUnit,
+			line.LineDiscount,
+			line.TotalPrice,
+			line.Notes,
+		).Scan(&line.SaleID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// Take the sold quantities out of stock, locking items in ascending order
+	lines := make([]*salesmodels.Sale, len(txn.Lines))
+	copy(lines, txn.Lines)
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].ItemID < lines[j].ItemID
+	})
+	for _, line := range lines {
+		if err = r.applyStockDeltas(ctx, tx, line.SaleID, txn.WarehouseID, map[int]int{line.ItemID: -line.Quantity}, nil); err != nil {
+			return 0, err
+		}
+	}
+
 	// Commit the transaction
 	if err = tx.Commit(ctx); err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	return txn.TransactionID, nil
 }
 
+// Update changes a single sale line. Header details such as the customer
+// belong to the transaction and are left untouched.
 func (r *PostgresSaleRepository) Update(ctx context.Context, sale *salesmodels.Sale) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the sale so concurrent edits see a consistent quantity
+	var oldItemID, oldQuantity, warehouseID int
+	err = tx.QueryRow(ctx, `
+        SELECT s.item_id, s.quantity, t.warehouse_id
+        FROM sales s
+        JOIN sale_transactions t ON s.transaction_id = t.transaction_id
+        WHERE s.sale_id = $1
+        FOR UPDATE OF s
+    `, sale.SaleID).Scan(&oldItemID, &oldQuantity, &warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("sale not found")
+		}
+		return err
+	}
+
 	query := `
         UPDATE sales SET
             date = $2,
             item_id = $3,
             quantity = $4,
             price_per_unit = $5,
-            total_price = $6,
-            transaction_number = $7,
-            customer_name = $8,
-            customer_phone = $9,
-            customer_email = $10,
-            sold_by = $11,
-            notes = $12
+            line_discount = $6,
+            total_price = $7,
+            notes = $8
         WHERE sale_id = $1
     `
 
-	result, err := r.db.Pool.Exec(
+	_, err = tx.Exec(
 		ctx, query,
 		sale.SaleID,
 		sale.Date,
 		sale.ItemID,
 		sale.Quantity,
 		sale.PricePerUnit,
+		sale.LineDiscount,
 		sale.TotalPrice,
-		sale.TransactionNumber,
-		sale.CustomerName,
-		sale.CustomerPhone,
-		sale.CustomerEmail,
-		sale.SoldBy,
 		sale.Notes,
 	)
-
 	if err != nil {
 		return err
 	}
 
-	if result.RowsAffected() == 0 {
-		return errors.New("sale not found")
+	// Give the old quantity back and take the new one out
+	deltas := map[int]int{oldItemID: oldQuantity}
+	deltas[sale.ItemID] -= sale.Quantity
+	notes := "sale updated"
+	if err = r.applyStockDeltas(ctx, tx, sale.SaleID, warehouseID, deltas, &notes); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
 
+// Delete removes a single sale line. A transaction left without lines is
+// removed as well.
 func (r *PostgresSaleRepository) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM sales WHERE sale_id = $1`
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
-	result, err := r.db.Pool.Exec(ctx, query, id)
+	var transactionID, itemID, quantity, warehouseID int
+	err = tx.QueryRow(ctx, `
+        DELETE FROM sales s
+        USING sale_transactions t
+        WHERE s.sale_id = $1 AND s.transaction_id = t.transaction_id
+        RETURNING s.transaction_id, s.item_id, s.quantity, t.warehouse_id
+    `, id).Scan(&transactionID, &itemID, &quantity, &warehouseID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("sale not found")
+		}
 		return err
 	}
 
+	// Put the sold quantity back on the shelf
+	notes := "sale deleted"
+	if err = r.applyStockDeltas(ctx, tx, id, warehouseID, map[int]int{itemID: quantity}, &notes); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        DELETE FROM sale_transactions t
+        WHERE t.transaction_id = $1
+          AND NOT EXISTS (SELECT 1 FROM sales s WHERE s.transaction_id = t.transaction_id)
+    `, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteTransaction voids a whole transaction and returns every line to stock
+func (r *PostgresSaleRepository) DeleteTransaction(ctx context.Context, transactionID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var warehouseID int
+	err = tx.QueryRow(ctx, `
+        SELECT warehouse_id FROM sale_transactions WHERE transaction_id = $1 FOR UPDATE
+    `, transactionID).Scan(&warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("sale not found")
+		}
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+        DELETE FROM sales WHERE transaction_id = $1
+        RETURNING sale_id, item_id, quantity
+    `, transactionID)
+	if err != nil {
+		return err
+	}
+
+	var lines []*salesmodels.Sale
+	for rows.Next() {
+		line := &salesmodels.Sale{}
+		if err := rows.Scan(&line.SaleID, &line.ItemID, &line.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		lines = append(lines, line)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].ItemID < lines[j].ItemID
+	})
+	notes := "sale voided"
+	for _, line := range lines {
+		if err = r.applyStockDeltas(ctx, tx, line.SaleID, warehouseID, map[int]int{line.ItemID: line.Quantity}, &notes); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM sale_transactions WHERE transaction_id = $1`, transactionID)
+	if err != nil {
+		return err
+	}
 	if result.RowsAffected() == 0 {
 		return errors.New("sale not found")
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
 
-func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error) {
+func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.SaleTransaction, error) {
 	query := `
         SELECT
-            s.sale_id, s.date, s.item_id, s.quantity,
-            s.price_per_unit, s.total_price, s.transaction_number,
-            s.customer_name, s.customer_phone, s.customer_email,
-            s.sold_by, s.notes, s.created_at, s.updated_at,
-            i.part_number as item_part_number,
-            i.description as item_description,
-            c.name
+            t.transaction_id, t.transaction_number, t.date,
+            t.customer_name, t.customer_phone, t.customer_email,
+            t.sold_by_user_id, COALESCE(u.full_name, u.username, t.sold_by),
+            t.notes, t.created_at, t.updated_at, t.warehouse_id
+        FROM sale_transactions t
+        LEFT JOIN users u ON t.sold_by_user_id = u.user_id
+        WHERE t.transaction_number = $1
+    `
+
+	txn := &salesmodels.SaleTransaction{}
+	err := r.db.Pool.QueryRow(ctx, query, transactionNumber).Scan(
+		&txn.TransactionID,
+		&txn.TransactionNumber,
+		&txn.Date,
+		&txn.CustomerName,
+		&txn.CustomerPhone,
+		&txn.CustomerEmail,
+		&txn.SoldByUserID,
+		&txn.SoldBy,
+		&txn.Notes,
+		&txn.CreatedAt,
+		&txn.UpdatedAt,
+		&txn.WarehouseID,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	txn.Lines, err = r.querySales(ctx, saleSelectQuery+" WHERE s.transaction_id = $1 ORDER BY s.sale_id", txn.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+func (r *PostgresSaleRepository) GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error) {
+	filter := &salesmodels.SaleFilter{
+		ItemID: &itemID,
+	}
+	return r.GetAll(ctx, filter, nil)
+}
+
+func (r *PostgresSaleRepository) GetCustomerSales(ctx context.Context, customerEmail string) ([]*salesmodels.Sale, error) {
+	filter := &salesmodels.SaleFilter{
+		CustomerEmail: &customerEmail,
+	}
+	return r.GetAll(ctx, filter, nil)
+}
+
+// GetEmployeeSales totals sales per seller. Legacy sales that could not be
+// tied to a user are grouped under the name they were recorded with.
+func (r *PostgresSaleRepository) GetEmployeeSales(ctx context.Context, filter *salesmodels.EmployeeSalesFilter) ([]*salesmodels.EmployeeSales, error) {
+	query := `
+        SELECT
+            t.sold_by_user_id,
+            COALESCE(u.full_name, u.username, t.sold_by, '') as name,
+            COUNT(DISTINCT t.transaction_id) as transaction_count,
+            COALESCE(SUM(s.quantity), 0) as item_count,
+            COALESCE(SUM(s.quantity * s.price_per_unit), 0) as subtotal,
+            COALESCE(SUM(s.line_discount), 0) as discount_total,
+            COALESCE(SUM(s.total_price), 0) as total
+        FROM sale_transactions t
+        JOIN sales s ON s.transaction_id = t.transaction_id
+        LEFT JOIN users u ON t.sold_by_user_id = u.user_id
+        WHERE 1=1
+    `
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("t.date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("t.date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+
+		if filter.UserID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.sold_by_user_id = $%d", paramCount))
+			params = append(params, *filter.UserID)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += `
+        GROUP BY t.sold_by_user_id, COALESCE(u.full_name, u.username, t.sold_by, '')
+        ORDER BY total DESC, name
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var report []*salesmodels.EmployeeSales
+	for rows.Next() {
+		employee := &salesmodels.EmployeeSales{}
+		err := rows.Scan(
+			&employee.UserID,
+			&employee.Name,
+			&employee.TransactionCount,
+			&employee.ItemCount,
+			&employee.Subtotal,
+			&employee.DiscountTotal,
+			&employee.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, employee)
+	}
+
+	return report, rows.Err()
+}
+
+// GetItemSalesReport totals sales per part. A sale of a superseded part
+// number is counted towards the part that replaces it today.
+func (r *PostgresSaleRepository) GetItemSalesReport(ctx context.Context, filter *salesmodels.ItemSalesFilter) ([]*salesmodels.ItemSales, error) {
+	query := `
+        SELECT
+            i.item_id,
+            i.part_number,
+            i.description,
+            COUNT(*) as sale_count,
+            COALESCE(SUM(s.quantity), 0) as quantity,
+            COALESCE(SUM(s.quantity) FILTER (WHERE s.item_id <> i.item_id), 0) as superseded_quantity,
+            COALESCE(SUM(s.total_price), 0) as total
         FROM sales s
-        JOIN items i ON s.item_id = i.item_id
-        LEFT JOIN categories c ON i.category_id = c.category_id
-        WHERE s.transaction_number = $1
+        JOIN current_items ci ON ci.item_id = s.item_id
+        JOIN items i ON i.item_id = ci.current_item_id
+        WHERE 1=1
+    `
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.StartDate != nil {
+			conditions = append(conditions, fmt.Sprintf("s.date >= $%d", paramCount))
+			params = append(params, *filter.StartDate)
+			paramCount++
+		}
+
+		if filter.EndDate != nil {
+			conditions = append(conditions, fmt.Sprintf("s.date <= $%d", paramCount))
+			params = append(params, *filter.EndDate)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += `
+        GROUP BY i.item_id, i.part_number, i.description
+        ORDER BY total DESC, i.part_number
     `
 
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var report []*salesmodels.ItemSales
+	for rows.Next() {
+		item := &salesmodels.ItemSales{}
+		err := rows.Scan(
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.SaleCount,
+			&item.Quantity,
+			&item.SupersededQuantity,
+			&item.Total,
+		)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, item)
+	}
+
+	return report, rows.Err()
+}
+
+// GetScannedItem finds the item any of its barcodes belongs to, or returns
+// nil if no item has the barcode
+func (r *PostgresSaleRepository) GetScannedItem(ctx context.Context, barcode string) (*salesmodels.ScannedItem, error) {
+	query := `
+        SELECT b.item_id, b.pack_quantity, i.sell_price
+        FROM item_barcodes b
+        JOIN items i ON b.item_id = i.item_id
+        WHERE b.barcode = $1
+    `
+
+	item := &salesmodels.ScannedItem{}
+	err := r.db.Pool.QueryRow(ctx, query, barcode).Scan(&item.ItemID, &item.PackQuantity, &item.SellPrice)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (r *PostgresSaleRepository) querySales(ctx context.Context, query string, params ...interface{}) ([]*salesmodels.Sale, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []*salesmodels.Sale
+	for rows.Next() {
+		sale, err := scanSale(rows)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, rows.Err()
+}
+
+// saleSortValue returns the value a sale line is sorted by, as SaleSorting
+// compares it
+func saleSortValue(sale *salesmodels.Sale, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(sale.SaleID)
+	case "quantity":
+		return strconv.Itoa(sale.Quantity)
+	case "total_price":
+		return pagination.Float(sale.TotalPrice)
+	case "transaction_number":
+		return sale.TransactionNumber
+	case "part_number":
+		return sale.ItemPartNumber
+	default:
+		return pagination.Time(sale.Date)
+	}
+}
+
+func scanSale(row pgx.Row) (*salesmodels.Sale, error) {
 	sale := &salesmodels.Sale{}
-	err := r.db.Pool.QueryRow(ctx, query, transactionNumber).Scan(
+	err := row.Scan(
 		&sale.SaleID,
+		&sale.TransactionID,
 		&sale.Date,
 		&sale.ItemID,
 		&sale.Quantity,
 		&sale.PricePerUnit,
+		&sale.LineDiscount,
 		&sale.TotalPrice,
 		&sale.TransactionNumber,
 		&sale.CustomerName,
 		&sale.CustomerPhone,
 		&sale.CustomerEmail,
+		&sale.SoldByUserID,
 		&sale.SoldBy,
 		&sale.Notes,
 		&sale.CreatedAt,
@@ -320,28 +724,59 @@ func (r *PostgresSaleRepository) GetByTransactionNumber(ctx context.Context, tra
 		&sale.ItemPartNumber,
 		&sale.ItemDescription,
 		&sale.CategoryName,
+		&sale.WarehouseID,
 	)
-
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
-
 	return sale, nil
 }
 
-func (r *PostgresSaleRepository) GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error) {
-	filter := &salesmodels.SaleFilter{
-		ItemID: &itemID,
+// applyStockDeltas records the stock changes caused by a sale in the stock
+// ledger of the sale's warehouse. Quantities leaving the shelf are booked as
+// sales and quantities coming back as returns. Items are locked in
+// ascending ID order so concurrent transactions cannot deadlock.
+func (r *PostgresSaleRepository) applyStockDeltas(ctx context.Context, tx pgx.Tx, saleID, warehouseID int, deltas map[int]int, notes *string) error {
+	itemIDs := make([]int, 0, len(deltas))
+	for itemID, delta := range deltas {
+		if delta != 0 {
+			itemIDs = append(itemIDs, itemID)
+		}
 	}
-	return r.GetAll(ctx, filter)
-}
+	sort.Ints(itemIDs)
+
+	for _, itemID := range itemIDs {
+		movement := &stockmovementmodels.StockMovement{
+			ItemID:      itemID,
+			WarehouseID: warehouseID,
+			ReferenceID: &saleID,
+			Notes:       notes,
+		}
 
-func (r *PostgresSaleRepository) GetCustomerSales(ctx context.Context, customerEmail string) ([]*salesmodels.Sale, error) {
-	filter := &salesmodels.SaleFilter{
-		CustomerEmail: &customerEmail,
+		referenceType := stockmovementmodels.ReferenceTypeSale
+		if delta := deltas[itemID]; delta < 0 {
+			movement.MovementType = stockmovementmodels.MovementTypeOut
+			movement.Quantity = -delta
+		} else {
+			movement.MovementType = stockmovementmodels.MovementTypeIn
+			movement.Quantity = delta
+			referenceType = stockmovementmodels.ReferenceTypeReturn
+		}
+		movement.ReferenceType = &referenceType
+
+		if err := r.movements.RecordTx(ctx, tx, movement); err != nil {
+			switch {
+			case errors.Is(err, stockmovementrepositories.ErrItemNotFound):
+				return ErrItemNotFound
+			case errors.Is(err, stockmovementrepositories.ErrInsufficientStock):
+				return ErrInsufficientStock
+			case errors.Is(err, stockmovementrepositories.ErrWarehouseNotFound):
+				return ErrWarehouseNotFound
+			default:
+				return err
+			}
+		}
 	}
-	return r.GetAll(ctx, filter)
+
+	return nil
 }
@@ -2,17 +2,31 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+)
+
+var (
+	ErrItemNotFound      = errors.New("item not found")
+	ErrInsufficientStock = errors.New("insufficient stock for sale")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+
+	ErrDuplicateTransactionNumber = errors.New("transaction number already exists")
 )
 
 type SaleRepository interface {
-    GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error)
+    GetAll(ctx context.Context, filter *salesmodels.SaleFilter, page *pagination.Page) ([]*salesmodels.Sale, error)
     GetByID(ctx context.Context, id int) (*salesmodels.Sale, error)
-    Create(ctx context.Context, sale *salesmodels.Sale) (int, error)
+    CreateTransaction(ctx context.Context, txn *salesmodels.SaleTransaction) (int, error)
     Update(ctx context.Context, sale *salesmodels.Sale) error
     Delete(ctx context.Context, id int) error
-    GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error)
+    DeleteTransaction(ctx context.Context, transactionID int) error
+    GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.SaleTransaction, error)
     GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error)
     GetCustomerSales(ctx context.Context, customerEmail string) ([]*salesmodels.Sale, error)
+    GetEmployeeSales(ctx context.Context, filter *salesmodels.EmployeeSalesFilter) ([]*salesmodels.EmployeeSales, error)
+    GetItemSalesReport(ctx context.Context, filter *salesmodels.ItemSalesFilter) ([]*salesmodels.ItemSales, error)
+    GetScannedItem(ctx context.Context, barcode string) (*salesmodels.ScannedItem, error)
 }
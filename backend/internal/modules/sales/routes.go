@@ -1,16 +1,20 @@
 package sales
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/sales/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/sales/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/sales/services"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
 	"github.com/hsrvms/autoparts/pkg/db"
 	"github.com/labstack/echo/v4"
 )
 
 func RegisterRoutes(api *echo.Group, database *db.Database) {
-    // Initialize repository
-    repo := repositories.NewPostgresSaleRepository(database)
+    // Initialize repositories
+    movementRepo := stockmovementrepositories.NewPostgresStockMovementRepository(database)
+    repo := repositories.NewPostgresSaleRepository(database, movementRepo)
 
     // Initialize service
     service := services.NewSaleService(repo)
@@ -18,13 +22,22 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize handler
     handler := handlers.NewSaleHandler(service)
 
+    // Permissions. Changing a sale after the fact counts as voiding it.
+    read := authmiddleware.RequirePermission(authmodels.PermSalesRead)
+    write := authmiddleware.RequirePermission(authmodels.PermSalesWrite)
+    void := authmiddleware.RequirePermission(authmodels.PermSalesVoid)
+    reports := authmiddleware.RequirePermission(authmodels.PermReportsRead)
+
     // Register routes
     sales := api.Group("/sales")
-    sales.GET("", handler.GetSales)
-    sales.GET("/:id", handler.GetSaleByID)
-    sales.POST("", handler.CreateSale)
-    sales.PUT("/:id", handler.UpdateSale)
-    sales.DELETE("/:id", handler.DeleteSale)
-    sales.GET("/transaction/:transactionNumber", handler.GetByTransactionNumber)
-    sales.GET("/customer/:customerEmail", handler.GetCustomerSales)
+    sales.GET("", handler.GetSales, read)
+    sales.GET("/:id", handler.GetSaleByID, read)
+    sales.POST("", handler.CreateSale, write)
+    sales.PUT("/:id", handler.UpdateSale, void)
+    sales.DELETE("/:id", handler.DeleteSale, void)
+    sales.GET("/transaction/:transactionNumber", handler.GetByTransactionNumber, read)
+    sales.DELETE("/transaction/:transactionNumber", handler.DeleteTransaction, void)
+    sales.GET("/customer/:customerEmail", handler.GetCustomerSales, read)
+    sales.GET("/reports/employees", handler.GetEmployeeSales, reports)
+    sales.GET("/reports/items", handler.GetItemSalesReport, reports)
 }
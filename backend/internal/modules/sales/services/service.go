@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"math"
 	"time"
 
 	salesmodels "github.com/hsrvms/autoparts/internal/modules/sales/models"
 	"github.com/hsrvms/autoparts/internal/modules/sales/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 var (
@@ -15,21 +17,34 @@ var (
 	ErrInvalidItemID              = errors.New("invalid item ID")
 	ErrInvalidQuantity            = errors.New("quantity must be greater than 0")
 	ErrInvalidPricePerUnit        = errors.New("price per unit must be greater than 0")
-	ErrDuplicateTransactionNumber = errors.New("transaction number already exists")
+	ErrDuplicateTransactionNumber = repositories.ErrDuplicateTransactionNumber
 	ErrInvalidDate                = errors.New("sale date cannot be in the future")
-	ErrInsufficientStock          = errors.New("insufficient stock for sale")
+	ErrInsufficientStock          = repositories.ErrInsufficientStock
+	ErrItemNotFound               = repositories.ErrItemNotFound
+	ErrWarehouseNotFound          = repositories.ErrWarehouseNotFound
+	ErrInvalidWarehouseID         = errors.New("invalid warehouse ID")
 	ErrInvalidCustomerEmail       = errors.New("invalid customer email format")
+	ErrEmptySale                  = errors.New("sale must contain at least one line")
+	ErrInvalidLineDiscount        = errors.New("line discount must be between 0 and the line amount")
+	ErrSellerRequired             = errors.New("sale must be rung up by a signed-in user")
+	ErrBarcodeNotFound            = errors.New("no item has this barcode")
 )
 
+// SaleSorting lists the fields sale lines can be sorted by
+var SaleSorting = repositories.SaleSorting
+
 type SaleService interface {
-	GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error)
+	GetAll(ctx context.Context, filter *salesmodels.SaleFilter, page *pagination.Page) ([]*salesmodels.Sale, error)
 	GetByID(ctx context.Context, id int) (*salesmodels.Sale, error)
-	Create(ctx context.Context, sale *salesmodels.Sale) (int, error)
+	Create(ctx context.Context, txn *salesmodels.SaleTransaction) (*salesmodels.SaleTransaction, error)
 	Update(ctx context.Context, sale *salesmodels.Sale) error
 	Delete(ctx context.Context, id int) error
-	GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error)
+	DeleteTransaction(ctx context.Context, transactionNumber string) error
+	GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.SaleTransaction, error)
 	GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error)
 	GetCustomerSales(ctx context.Context, customerEmail string) ([]*salesmodels.Sale, error)
+	GetEmployeeSales(ctx context.Context, filter *salesmodels.EmployeeSalesFilter) ([]*salesmodels.EmployeeSales, error)
+	GetItemSalesReport(ctx context.Context, filter *salesmodels.ItemSalesFilter) ([]*salesmodels.ItemSales, error)
 }
 
 type saleService struct {
@@ -42,8 +57,8 @@ func NewSaleService(repo repositories.SaleRepository) SaleService {
 	}
 }
 
-func (s *saleService) GetAll(ctx context.Context, filter *salesmodels.SaleFilter) ([]*salesmodels.Sale, error) {
-	return s.repo.GetAll(ctx, filter)
+func (s *saleService) GetAll(ctx context.Context, filter *salesmodels.SaleFilter, page *pagination.Page) ([]*salesmodels.Sale, error) {
+	return s.repo.GetAll(ctx, filter, page)
 }
 
 func (s *saleService) GetByID(ctx context.Context, id int) (*salesmodels.Sale, error) {
@@ -62,34 +77,54 @@ func (s *saleService) GetByID(ctx context.Context, id int) (*salesmodels.Sale, e
 	return sale, nil
 }
 
-func (s *saleService) Create(ctx context.Context, sale *salesmodels.Sale) (int, error) {
-	// Validate the sale
-	if err := s.validateSale(sale); err != nil {
-		return 0, err
+// Create rings up a whole basket as one transaction. Either every line is
+// sold or none is.
+func (s *saleService) Create(ctx context.Context, txn *salesmodels.SaleTransaction) (*salesmodels.SaleTransaction, error) {
+	if len(txn.Lines) == 0 {
+		return nil, ErrEmptySale
+	}
+	if txn.SoldByUserID == nil {
+		return nil, ErrSellerRequired
+	}
+	if !txn.Date.IsZero() && txn.Date.After(time.Now()) {
+		return nil, ErrInvalidDate
+	}
+	if txn.WarehouseID < 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+
+	// Validate the lines and price them
+	for _, line := range txn.Lines {
+		if err := s.resolveBarcode(ctx, line); err != nil {
+			return nil, err
+		}
+		if err := s.validateSale(line); err != nil {
+			return nil, err
+		}
+		line.TotalPrice = lineTotal(line)
 	}
 
 	// Check if transaction number is unique if provided
-	if sale.TransactionNumber != "" {
-		existing, err := s.repo.GetByTransactionNumber(ctx, sale.TransactionNumber)
+	if txn.TransactionNumber != "" {
+		existing, err := s.repo.GetByTransactionNumber(ctx, txn.TransactionNumber)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
 		if existing != nil {
-			return 0, ErrDuplicateTransactionNumber
+			return nil, ErrDuplicateTransactionNumber
 		}
 	}
 
 	// Set date to current time if not provided
-	if sale.Date.IsZero() {
-		sale.Date = time.Now()
+	if txn.Date.IsZero() {
+		txn.Date = time.Now()
 	}
 
-	// Calculate total price if not provided
-	if sale.TotalPrice == 0 {
-		sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
+	if _, err := s.repo.CreateTransaction(ctx, txn); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Create(ctx, sale)
+	return s.GetByTransactionNumber(ctx, txn.TransactionNumber)
 }
 
 func (s *saleService) Update(ctx context.Context, sale *salesmodels.Sale) error {
@@ -111,19 +146,13 @@ func (s *saleService) Update(ctx context.Context, sale *salesmodels.Sale) error
 		return ErrSaleNotFound
 	}
 
-	// Check if transaction number is unique if changed
-	if sale.TransactionNumber != existing.TransactionNumber {
-		existingByTxn, err := s.repo.GetByTransactionNumber(ctx, sale.TransactionNumber)
-		if err != nil {
-			return err
-		}
-		if existingByTxn != nil && existingByTxn.SaleID != sale.SaleID {
-			return ErrDuplicateTransactionNumber
-		}
+	// Lines keep the date of their transaction unless one is given
+	if sale.Date.IsZero() {
+		sale.Date = existing.Date
 	}
 
 	// Recalculate total price
-	sale.TotalPrice = float64(sale.Quantity) * sale.PricePerUnit
+	sale.TotalPrice = lineTotal(sale)
 
 	return s.repo.Update(ctx, sale)
 }
@@ -145,12 +174,35 @@ func (s *saleService) Delete(ctx context.Context, id int) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *saleService) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.Sale, error) {
+// DeleteTransaction voids a whole transaction, returning every line to stock
+func (s *saleService) DeleteTransaction(ctx context.Context, transactionNumber string) error {
+	if transactionNumber == "" {
+		return errors.New("transaction number is required")
+	}
+
+	existing, err := s.repo.GetByTransactionNumber(ctx, transactionNumber)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrSaleNotFound
+	}
+
+	return s.repo.DeleteTransaction(ctx, existing.TransactionID)
+}
+
+func (s *saleService) GetByTransactionNumber(ctx context.Context, transactionNumber string) (*salesmodels.SaleTransaction, error) {
 	if transactionNumber == "" {
 		return nil, errors.New("transaction number is required")
 	}
 
-	return s.repo.GetByTransactionNumber(ctx, transactionNumber)
+	txn, err := s.repo.GetByTransactionNumber(ctx, transactionNumber)
+	if err != nil || txn == nil {
+		return txn, err
+	}
+
+	calculateTotals(txn)
+	return txn, nil
 }
 
 func (s *saleService) GetItemSales(ctx context.Context, itemID int) ([]*salesmodels.Sale, error) {
@@ -169,7 +221,57 @@ func (s *saleService) GetCustomerSales(ctx context.Context, customerEmail string
 	return s.repo.GetCustomerSales(ctx, customerEmail)
 }
 
+// GetEmployeeSales reports the sales of every employee in a period
+func (s *saleService) GetEmployeeSales(ctx context.Context, filter *salesmodels.EmployeeSalesFilter) ([]*salesmodels.EmployeeSales, error) {
+	report, err := s.repo.GetEmployeeSales(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, employee := range report {
+		if employee.TransactionCount > 0 {
+			employee.AverageSale = roundCents(employee.Total / float64(employee.TransactionCount))
+		}
+	}
+
+	return report, nil
+}
+
+// GetItemSalesReport reports the sales of every part in a period, with sales
+// of superseded part numbers counted towards their successor
+func (s *saleService) GetItemSalesReport(ctx context.Context, filter *salesmodels.ItemSalesFilter) ([]*salesmodels.ItemSales, error) {
+	return s.repo.GetItemSalesReport(ctx, filter)
+}
+
 // Helper functions
+
+// resolveBarcode fills in the item of a line rung up by barcode. Quantity
+// counts scans, one when left out, and becomes units of the barcode's pack;
+// the price defaults to the item's sell price.
+func (s *saleService) resolveBarcode(ctx context.Context, line *salesmodels.Sale) error {
+	if line.Barcode == "" || line.ItemID != 0 {
+		return nil
+	}
+
+	scanned, err := s.repo.GetScannedItem(ctx, line.Barcode)
+	if err != nil {
+		return err
+	}
+	if scanned == nil {
+		return ErrBarcodeNotFound
+	}
+
+	if line.Quantity == 0 {
+		line.Quantity = 1
+	}
+	line.ItemID = scanned.ItemID
+	line.Quantity *= scanned.PackQuantity
+	if line.PricePerUnit == 0 {
+		line.PricePerUnit = scanned.SellPrice
+	}
+	return nil
+}
+
 func (s *saleService) validateSale(sale *salesmodels.Sale) error {
 	if sale.ItemID <= 0 {
 		return ErrInvalidItemID
@@ -180,15 +282,39 @@ func (s *saleService) validateSale(sale *salesmodels.Sale) error {
 	if sale.PricePerUnit <= 0 {
 		return ErrInvalidPricePerUnit
 	}
+	if sale.LineDiscount < 0 || sale.LineDiscount > float64(sale.Quantity)*sale.PricePerUnit {
+		return ErrInvalidLineDiscount
+	}
 	if !sale.Date.IsZero() && sale.Date.After(time.Now()) {
 		return ErrInvalidDate
 	}
 
 	// Additional validations could be added here:
-	// - Check if item exists
-	// - Check if item has sufficient stock
 	// - Validate email format if provided
 	// - etc.
 
 	return nil
 }
+
+// lineTotal returns the amount charged for a line after its discount
+func lineTotal(sale *salesmodels.Sale) float64 {
+	return roundCents(float64(sale.Quantity)*sale.PricePerUnit - sale.LineDiscount)
+}
+
+// calculateTotals sums the lines of a transaction into its totals
+func calculateTotals(txn *salesmodels.SaleTransaction) {
+	txn.Subtotal, txn.DiscountTotal, txn.Total, txn.ItemCount = 0, 0, 0, 0
+	for _, line := range txn.Lines {
+		txn.Subtotal += float64(line.Quantity) * line.PricePerUnit
+		txn.DiscountTotal += line.LineDiscount
+		txn.Total += line.TotalPrice
+		txn.ItemCount += line.Quantity
+	}
+	txn.Subtotal = roundCents(txn.Subtotal)
+	txn.DiscountTotal = roundCents(txn.DiscountTotal)
+	txn.Total = roundCents(txn.Total)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements/services"
+	"github.com/labstack/echo/v4"
+)
+
+type StockMovementHandler struct {
+	service services.StockMovementService
+}
+
+func NewStockMovementHandler(service services.StockMovementService) *StockMovementHandler {
+	return &StockMovementHandler{
+		service: service,
+	}
+}
+
+// GetItemMovements handles retrieval of the stock ledger of an item
+func (h *StockMovementHandler) GetItemMovements(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	filter := &stockmovementmodels.MovementFilter{ItemID: itemID}
+
+	// Parse query parameters
+	if startDate := c.QueryParam("start_date"); startDate != "" {
+		date, err := time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid start date")
+		}
+		filter.StartDate = &date
+	}
+
+	if endDate := c.QueryParam("end_date"); endDate != "" {
+		date, err := time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid end date")
+		}
+		filter.EndDate = &date
+	}
+
+	if movementType := c.QueryParam("movement_type"); movementType != "" {
+		filter.MovementType = &movementType
+	}
+
+	if referenceType := c.QueryParam("reference_type"); referenceType != "" {
+		filter.ReferenceType = &referenceType
+	}
+
+	if reason := c.QueryParam("reason"); reason != "" {
+		filter.Reason = &reason
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.service.GetItemMovements(ctx, filter)
+	if err != nil {
+		switch err {
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidItemID, services.ErrInvalidDateRange,
+			services.ErrInvalidMovementType, services.ErrInvalidReferenceType,
+			services.ErrInvalidReason:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// CreateAdjustment handles a manual stock adjustment of an item
+func (h *StockMovementHandler) CreateAdjustment(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	adjustment := new(stockmovementmodels.StockAdjustment)
+	if err := c.Bind(adjustment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	adjustment.ItemID = itemID
+
+	ctx := c.Request().Context()
+	movement, err := h.service.CreateAdjustment(ctx, adjustment)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidItemID, services.ErrInvalidQuantity,
+			services.ErrInvalidReason, services.ErrReasonRequiresLoss,
+			services.ErrReasonRequiresGain, services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound, services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInsufficientStock:
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusCreated, movement)
+}
+
+// ApplyCycleCount handles a batch of counted quantities for many items
+func (h *StockMovementHandler) ApplyCycleCount(c echo.Context) error {
+	count := new(stockmovementmodels.CycleCount)
+	if err := c.Bind(count); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.ApplyCycleCount(ctx, count)
+	if err != nil {
+		switch err {
+		case services.ErrEmptyCycleCount, services.ErrInvalidItemID,
+			services.ErrInvalidCountedQty, services.ErrDuplicateCountedItem,
+			services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound, services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,48 @@
+package stockmovementmodels
+
+// Reason codes for manual stock adjustments
+const (
+	ReasonDamaged         = "damaged"
+	ReasonLost            = "lost"
+	ReasonFound           = "found"
+	ReasonCountCorrection = "count-correction"
+)
+
+// StockAdjustment is a manual correction of the stock of a single item in
+// a warehouse, the default one when none is given. Quantity is signed:
+// negative values take stock out, positive put it back.
+type StockAdjustment struct {
+	ItemID      int     `json:"-"`
+	WarehouseID int     `json:"warehouse_id"`
+	Quantity    int     `json:"quantity"`
+	Reason      string  `json:"reason"`
+	Note        *string `json:"note,omitempty"`
+}
+
+// CycleCount holds the quantities counted on the shelves of a warehouse,
+// the default one when none is given, for a set of items
+type CycleCount struct {
+	WarehouseID int              `json:"warehouse_id"`
+	Counts      []CycleCountLine `json:"counts"`
+	Note        *string          `json:"note,omitempty"`
+}
+
+type CycleCountLine struct {
+	ItemID          int `json:"item_id"`
+	CountedQuantity int `json:"counted_quantity"`
+}
+
+// CycleCountResult reports what a cycle count changed
+type CycleCountResult struct {
+	ItemsCounted  int                     `json:"items_counted"`
+	ItemsAdjusted int                     `json:"items_adjusted"`
+	Lines         []*CycleCountLineResult `json:"lines"`
+}
+
+type CycleCountLineResult struct {
+	ItemID           int            `json:"item_id"`
+	ExpectedQuantity int            `json:"expected_quantity"`
+	CountedQuantity  int            `json:"counted_quantity"`
+	Difference       int            `json:"difference"`
+	Movement         *StockMovement `json:"movement,omitempty"`
+}
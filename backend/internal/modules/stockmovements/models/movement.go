@@ -0,0 +1,64 @@
+package stockmovementmodels
+
+import "time"
+
+const (
+	MovementTypeIn  = "in"
+	MovementTypeOut = "out"
+)
+
+const (
+	ReferenceTypeSale       = "sale"
+	ReferenceTypePurchase   = "purchase"
+	ReferenceTypeAdjustment = "adjustment"
+	ReferenceTypeReturn     = "return"
+	ReferenceTypeTransfer   = "transfer"
+)
+
+// StockMovement is a single entry in the stock ledger of an item.
+// BalanceAfter is the item's stock over all warehouses once the movement is
+// booked.
+type StockMovement struct {
+	MovementID    int       `json:"movement_id" db:"movement_id"`
+	ItemID        int       `json:"item_id" db:"item_id"`
+	MovementType  string    `json:"movement_type" db:"movement_type"`
+	Quantity      int       `json:"quantity" db:"quantity"`
+	ReferenceID   *int      `json:"reference_id,omitempty" db:"reference_id"`
+	ReferenceType *string   `json:"reference_type,omitempty" db:"reference_type"`
+	Reason        *string   `json:"reason,omitempty" db:"reason"`
+	Notes         *string   `json:"notes,omitempty" db:"notes"`
+	BalanceAfter  int       `json:"balance_after" db:"balance_after"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	// WarehouseID is where the stock moved. Zero books the movement in the
+	// default warehouse.
+	WarehouseID int `json:"warehouse_id" db:"warehouse_id"`
+}
+
+// SignedQuantity returns the quantity as a stock delta
+func (m *StockMovement) SignedQuantity() int {
+	if m.MovementType == MovementTypeOut {
+		return -m.Quantity
+	}
+	return m.Quantity
+}
+
+type MovementFilter struct {
+	ItemID        int        `query:"-"`
+	StartDate     *time.Time `query:"start_date"`
+	EndDate       *time.Time `query:"end_date"`
+	MovementType  *string    `query:"movement_type"`
+	ReferenceType *string    `query:"reference_type"`
+	Reason        *string    `query:"reason"`
+}
+
+// ItemMovementHistory is the ledger of an item over a date range
+type ItemMovementHistory struct {
+	ItemID         int              `json:"item_id"`
+	OpeningBalance int              `json:"opening_balance"`
+	ClosingBalance int              `json:"closing_balance"`
+	TotalIn        int              `json:"total_in"`
+	TotalOut       int              `json:"total_out"`
+	Movements      []*StockMovement `json:"movements"`
+}
@@ -0,0 +1,332 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresStockMovementRepository struct {
+	db *db.Database
+}
+
+func NewPostgresStockMovementRepository(database *db.Database) StockMovementRepository {
+	return &PostgresStockMovementRepository{
+		db: database,
+	}
+}
+
+func (r *PostgresStockMovementRepository) GetItemMovements(ctx context.Context, filter *stockmovementmodels.MovementFilter) ([]*stockmovementmodels.StockMovement, error) {
+	query := `
+        SELECT
+            movement_id, item_id, warehouse_id, movement_type, quantity,
+            reference_id, reference_type, reason, notes,
+            COALESCE(balance_after, 0), created_at, updated_at
+        FROM stock_movements
+        WHERE item_id = $1
+    `
+
+	conditions := []string{}
+	params := []interface{}{filter.ItemID}
+	paramCount := 2
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", paramCount))
+		params = append(params, *filter.StartDate)
+		paramCount++
+	}
+
+	if filter.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", paramCount))
+		params = append(params, *filter.EndDate)
+		paramCount++
+	}
+
+	if filter.MovementType != nil {
+		conditions = append(conditions, fmt.Sprintf("movement_type = $%d", paramCount))
+		params = append(params, *filter.MovementType)
+		paramCount++
+	}
+
+	if filter.ReferenceType != nil {
+		conditions = append(conditions, fmt.Sprintf("reference_type = $%d", paramCount))
+		params = append(params, *filter.ReferenceType)
+		paramCount++
+	}
+
+	if filter.Reason != nil {
+		conditions = append(conditions, fmt.Sprintf("reason = $%d", paramCount))
+		params = append(params, *filter.Reason)
+		paramCount++
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY created_at, movement_id"
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []*stockmovementmodels.StockMovement{}
+	for rows.Next() {
+		movement := &stockmovementmodels.StockMovement{}
+		err := rows.Scan(
+			&movement.MovementID,
+			&movement.ItemID,
+			&movement.WarehouseID,
+			&movement.MovementType,
+			&movement.Quantity,
+			&movement.ReferenceID,
+			&movement.ReferenceType,
+			&movement.Reason,
+			&movement.Notes,
+			&movement.BalanceAfter,
+			&movement.CreatedAt,
+			&movement.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+
+	return movements, rows.Err()
+}
+
+// GetBalanceBefore returns the stock of an item just before the given time.
+// Without a time it returns the stock before the first recorded movement, and
+// items without any movement fall back to their current stock.
+func (r *PostgresStockMovementRepository) GetBalanceBefore(ctx context.Context, itemID int, before *time.Time) (int, error) {
+	query := `
+        SELECT COALESCE(
+            (SELECT balance_after
+             FROM stock_movements
+             WHERE item_id = $1 AND $2::timestamptz IS NOT NULL AND created_at < $2
+             ORDER BY created_at DESC, movement_id DESC
+             LIMIT 1),
+            (SELECT balance_after - CASE WHEN movement_type = 'in' THEN quantity ELSE -quantity END
+             FROM stock_movements
+             WHERE item_id = $1
+             ORDER BY created_at, movement_id
+             LIMIT 1),
+            (SELECT current_stock FROM items WHERE item_id = $1)
+        )
+    `
+
+	var balance *int
+	if err := r.db.Pool.QueryRow(ctx, query, itemID, before).Scan(&balance); err != nil {
+		return 0, err
+	}
+	if balance == nil {
+		return 0, ErrItemNotFound
+	}
+
+	return *balance, nil
+}
+
+func (r *PostgresStockMovementRepository) RecordTx(ctx context.Context, tx pgx.Tx, movement *stockmovementmodels.StockMovement) error {
+	delta := movement.SignedQuantity()
+
+	// Lock the item row so the balance we record is the one we leave behind
+	var currentStock int
+	err := tx.QueryRow(ctx, `
+        SELECT current_stock FROM items WHERE item_id = $1 FOR UPDATE
+    `, movement.ItemID).Scan(&currentStock)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrItemNotFound
+		}
+		return err
+	}
+
+	warehouseID, warehouseStock, err := lockWarehouseStock(ctx, tx, movement.WarehouseID, movement.ItemID)
+	if err != nil {
+		return err
+	}
+	movement.WarehouseID = warehouseID
+
+	if warehouseStock+delta < 0 {
+		return ErrInsufficientStock
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE warehouse_stock SET quantity = quantity + $3
+        WHERE warehouse_id = $1 AND item_id = $2
+    `, warehouseID, movement.ItemID, delta)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+        UPDATE items SET current_stock = current_stock + $2
+        WHERE item_id = $1
+        RETURNING current_stock
+    `, movement.ItemID, delta).Scan(&movement.BalanceAfter)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO stock_movements (
+            item_id, warehouse_id, movement_type, quantity, reference_id,
+            reference_type, reason, notes, balance_after
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING movement_id, created_at, updated_at
+    `
+
+	return tx.QueryRow(
+		ctx, query,
+		movement.ItemID,
+		movement.WarehouseID,
+		movement.MovementType,
+		movement.Quantity,
+		movement.ReferenceID,
+		movement.ReferenceType,
+		movement.Reason,
+		movement.Notes,
+		movement.BalanceAfter,
+	).Scan(&movement.MovementID, &movement.CreatedAt, &movement.UpdatedAt)
+}
+
+func (r *PostgresStockMovementRepository) CreateAdjustment(ctx context.Context, movement *stockmovementmodels.StockMovement) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = r.RecordTx(ctx, tx, movement); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ApplyCycleCount books the difference between the counted and the recorded
+// quantity of every item in the counted warehouse as a count-correction.
+// Either every item is corrected or none is.
+func (r *PostgresStockMovementRepository) ApplyCycleCount(ctx context.Context, count *stockmovementmodels.CycleCount) (*stockmovementmodels.CycleCountResult, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock items in ascending ID order so concurrent counts cannot deadlock
+	lines := make([]stockmovementmodels.CycleCountLine, len(count.Counts))
+	copy(lines, count.Counts)
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].ItemID < lines[j].ItemID
+	})
+
+	result := &stockmovementmodels.CycleCountResult{
+		ItemsCounted: len(lines),
+		Lines:        make([]*stockmovementmodels.CycleCountLineResult, 0, len(lines)),
+	}
+
+	reason := stockmovementmodels.ReasonCountCorrection
+	referenceType := stockmovementmodels.ReferenceTypeAdjustment
+	for _, line := range lines {
+		// Lock the item before its warehouse stock, in the order RecordTx does
+		var itemID int
+		err := tx.QueryRow(ctx, `
+            SELECT item_id FROM items WHERE item_id = $1 FOR UPDATE
+        `, line.ItemID).Scan(&itemID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrItemNotFound
+			}
+			return nil, err
+		}
+
+		warehouseID, currentStock, err := lockWarehouseStock(ctx, tx, count.WarehouseID, line.ItemID)
+		if err != nil {
+			return nil, err
+		}
+
+		lineResult := &stockmovementmodels.CycleCountLineResult{
+			ItemID:           line.ItemID,
+			ExpectedQuantity: currentStock,
+			CountedQuantity:  line.CountedQuantity,
+			Difference:       line.CountedQuantity - currentStock,
+		}
+
+		if lineResult.Difference != 0 {
+			movement := &stockmovementmodels.StockMovement{
+				ItemID:        line.ItemID,
+				WarehouseID:   warehouseID,
+				MovementType:  stockmovementmodels.MovementTypeIn,
+				Quantity:      lineResult.Difference,
+				ReferenceType: &referenceType,
+				Reason:        &reason,
+				Notes:         count.Note,
+			}
+			if movement.Quantity < 0 {
+				movement.MovementType = stockmovementmodels.MovementTypeOut
+				movement.Quantity = -movement.Quantity
+			}
+
+			if err := r.RecordTx(ctx, tx, movement); err != nil {
+				return nil, err
+			}
+			lineResult.Movement = movement
+			result.ItemsAdjusted++
+		}
+
+		result.Lines = append(result.Lines, lineResult)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// lockWarehouseStock locks the stock row of an item in a warehouse, the
+// default one when warehouseID is zero, creating it when the item has never
+// been there. It returns the warehouse and the quantity in it.
+func lockWarehouseStock(ctx context.Context, tx pgx.Tx, warehouseID, itemID int) (int, int, error) {
+	err := tx.QueryRow(ctx, `
+        SELECT warehouse_id FROM warehouses
+        WHERE CASE WHEN $1 = 0 THEN is_default ELSE warehouse_id = $1 END
+    `, warehouseID).Scan(&warehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, ErrWarehouseNotFound
+		}
+		return 0, 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO warehouse_stock (warehouse_id, item_id) VALUES ($1, $2)
+        ON CONFLICT (warehouse_id, item_id) DO NOTHING
+    `, warehouseID, itemID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var quantity int
+	err = tx.QueryRow(ctx, `
+        SELECT quantity FROM warehouse_stock
+        WHERE warehouse_id = $1 AND item_id = $2
+        FOR UPDATE
+    `, warehouseID, itemID).Scan(&quantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return warehouseID, quantity, nil
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrItemNotFound      = errors.New("item not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrWarehouseNotFound = errors.New("warehouse not found")
+)
+
+type StockMovementRepository interface {
+	GetItemMovements(ctx context.Context, filter *stockmovementmodels.MovementFilter) ([]*stockmovementmodels.StockMovement, error)
+	GetBalanceBefore(ctx context.Context, itemID int, before *time.Time) (int, error)
+	CreateAdjustment(ctx context.Context, movement *stockmovementmodels.StockMovement) error
+	ApplyCycleCount(ctx context.Context, count *stockmovementmodels.CycleCount) (*stockmovementmodels.CycleCountResult, error)
+
+	// RecordTx changes the stock of the movement's item in its warehouse
+	// and writes the movement to the ledger as part of the caller's
+	// transaction.
+	RecordTx(ctx context.Context, tx pgx.Tx, movement *stockmovementmodels.StockMovement) error
+}
@@ -0,0 +1,31 @@
+package stockmovements
+
+import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repository
+	repo := repositories.NewPostgresStockMovementRepository(database)
+
+	// Initialize service
+	service := services.NewStockMovementService(repo)
+
+	// Initialize handler
+	handler := handlers.NewStockMovementHandler(service)
+
+	// Permissions
+	read := authmiddleware.RequirePermission(authmodels.PermStockRead)
+	adjust := authmiddleware.RequirePermission(authmodels.PermStockAdjust)
+
+	// Register routes
+	api.GET("/items/:id/movements", handler.GetItemMovements, read)
+	api.POST("/items/:id/adjustments", handler.CreateAdjustment, adjust)
+	api.POST("/items/cycle-counts", handler.ApplyCycleCount, adjust)
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
+)
+
+var (
+	ErrItemNotFound         = repositories.ErrItemNotFound
+	ErrInsufficientStock    = repositories.ErrInsufficientStock
+	ErrWarehouseNotFound    = repositories.ErrWarehouseNotFound
+	ErrInvalidWarehouseID   = errors.New("invalid warehouse ID")
+	ErrInvalidItemID        = errors.New("invalid item ID")
+	ErrInvalidDateRange     = errors.New("start date cannot be after end date")
+	ErrInvalidMovementType  = errors.New("movement type must be 'in' or 'out'")
+	ErrInvalidReferenceType = errors.New("invalid reference type")
+	ErrInvalidReason        = errors.New("reason must be one of damaged, lost, found or count-correction")
+	ErrInvalidQuantity      = errors.New("adjustment quantity cannot be zero")
+	ErrReasonRequiresLoss   = errors.New("damaged and lost adjustments must reduce stock")
+	ErrReasonRequiresGain   = errors.New("found adjustments must increase stock")
+	ErrEmptyCycleCount      = errors.New("cycle count must contain at least one item")
+	ErrInvalidCountedQty    = errors.New("counted quantity cannot be negative")
+	ErrDuplicateCountedItem = errors.New("item counted more than once")
+)
+
+type StockMovementService interface {
+	GetItemMovements(ctx context.Context, filter *stockmovementmodels.MovementFilter) (*stockmovementmodels.ItemMovementHistory, error)
+	CreateAdjustment(ctx context.Context, adjustment *stockmovementmodels.StockAdjustment) (*stockmovementmodels.StockMovement, error)
+	ApplyCycleCount(ctx context.Context, count *stockmovementmodels.CycleCount) (*stockmovementmodels.CycleCountResult, error)
+}
+
+type stockMovementService struct {
+	repo repositories.StockMovementRepository
+}
+
+func NewStockMovementService(repo repositories.StockMovementRepository) StockMovementService {
+	return &stockMovementService{
+		repo: repo,
+	}
+}
+
+func (s *stockMovementService) GetItemMovements(ctx context.Context, filter *stockmovementmodels.MovementFilter) (*stockmovementmodels.ItemMovementHistory, error) {
+	if err := s.validateFilter(filter); err != nil {
+		return nil, err
+	}
+
+	// Resolving the opening balance also tells us whether the item exists
+	openingBalance, err := s.repo.GetBalanceBefore(ctx, filter.ItemID, filter.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.repo.GetItemMovements(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &stockmovementmodels.ItemMovementHistory{
+		ItemID:         filter.ItemID,
+		OpeningBalance: openingBalance,
+		ClosingBalance: openingBalance,
+		Movements:      movements,
+	}
+
+	for _, movement := range movements {
+		if movement.MovementType == stockmovementmodels.MovementTypeIn {
+			history.TotalIn += movement.Quantity
+		} else {
+			history.TotalOut += movement.Quantity
+		}
+		history.ClosingBalance = movement.BalanceAfter
+	}
+
+	return history, nil
+}
+
+func (s *stockMovementService) CreateAdjustment(ctx context.Context, adjustment *stockmovementmodels.StockAdjustment) (*stockmovementmodels.StockMovement, error) {
+	if err := s.validateAdjustment(adjustment); err != nil {
+		return nil, err
+	}
+
+	referenceType := stockmovementmodels.ReferenceTypeAdjustment
+	movement := &stockmovementmodels.StockMovement{
+		ItemID:        adjustment.ItemID,
+		WarehouseID:   adjustment.WarehouseID,
+		MovementType:  stockmovementmodels.MovementTypeIn,
+		Quantity:      adjustment.Quantity,
+		ReferenceType: &referenceType,
+		Reason:        &adjustment.Reason,
+		Notes:         adjustment.Note,
+	}
+	if movement.Quantity < 0 {
+		movement.MovementType = stockmovementmodels.MovementTypeOut
+		movement.Quantity = -movement.Quantity
+	}
+
+	if err := s.repo.CreateAdjustment(ctx, movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *stockMovementService) ApplyCycleCount(ctx context.Context, count *stockmovementmodels.CycleCount) (*stockmovementmodels.CycleCountResult, error) {
+	if len(count.Counts) == 0 {
+		return nil, ErrEmptyCycleCount
+	}
+	if count.WarehouseID < 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+
+	seen := make(map[int]bool, len(count.Counts))
+	for _, line := range count.Counts {
+		if line.ItemID <= 0 {
+			return nil, ErrInvalidItemID
+		}
+		if line.CountedQuantity < 0 {
+			return nil, ErrInvalidCountedQty
+		}
+		if seen[line.ItemID] {
+			return nil, ErrDuplicateCountedItem
+		}
+		seen[line.ItemID] = true
+	}
+
+	return s.repo.ApplyCycleCount(ctx, count)
+}
+
+// Helper functions
+func (s *stockMovementService) validateAdjustment(adjustment *stockmovementmodels.StockAdjustment) error {
+	if adjustment.ItemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if adjustment.WarehouseID < 0 {
+		return ErrInvalidWarehouseID
+	}
+	if adjustment.Quantity == 0 {
+		return ErrInvalidQuantity
+	}
+
+	switch adjustment.Reason {
+	case stockmovementmodels.ReasonDamaged, stockmovementmodels.ReasonLost:
+		if adjustment.Quantity > 0 {
+			return ErrReasonRequiresLoss
+		}
+	case stockmovementmodels.ReasonFound:
+		if adjustment.Quantity < 0 {
+			return ErrReasonRequiresGain
+		}
+	case stockmovementmodels.ReasonCountCorrection:
+	default:
+		return ErrInvalidReason
+	}
+
+	return nil
+}
+
+func (s *stockMovementService) validateFilter(filter *stockmovementmodels.MovementFilter) error {
+	if filter.ItemID <= 0 {
+		return ErrInvalidItemID
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && filter.StartDate.After(*filter.EndDate) {
+		return ErrInvalidDateRange
+	}
+	if filter.MovementType != nil &&
+		*filter.MovementType != stockmovementmodels.MovementTypeIn &&
+		*filter.MovementType != stockmovementmodels.MovementTypeOut {
+		return ErrInvalidMovementType
+	}
+	if filter.ReferenceType != nil {
+		switch *filter.ReferenceType {
+		case stockmovementmodels.ReferenceTypeSale, stockmovementmodels.ReferenceTypePurchase,
+			stockmovementmodels.ReferenceTypeAdjustment, stockmovementmodels.ReferenceTypeReturn,
+			stockmovementmodels.ReferenceTypeTransfer:
+		default:
+			return ErrInvalidReferenceType
+		}
+	}
+	if filter.Reason != nil {
+		switch *filter.Reason {
+		case stockmovementmodels.ReasonDamaged, stockmovementmodels.ReasonLost,
+			stockmovementmodels.ReasonFound, stockmovementmodels.ReasonCountCorrection:
+		default:
+			return ErrInvalidReason
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/labstack/echo/v4"
 )
 
@@ -19,8 +20,13 @@ func NewSupplierHandler(service services.SupplierService) *SupplierHandler {
     }
 }
 
-// GetSuppliers handles retrieval of all suppliers with optional filtering
+// GetSuppliers handles retrieval of a page of suppliers with optional filtering
 func (h *SupplierHandler) GetSuppliers(c echo.Context) error {
+    page, err := pagination.FromRequest(c, services.SupplierSorting)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+    }
+
     filter := &suppliermodels.SupplierFilter{}
 
     // Parse query parameters
@@ -34,12 +40,12 @@ func (h *SupplierHandler) GetSuppliers(c echo.Context) error {
     }
 
     ctx := c.Request().Context()
-    suppliers, err := h.service.GetAll(ctx, filter)
+    suppliers, err := h.service.GetAll(ctx, filter, page)
     if err != nil {
         return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
     }
 
-    return c.JSON(http.StatusOK, suppliers)
+    return pagination.Respond(c, page, suppliers)
 }
 
 // GetSupplierByID handles retrieval of a single supplier
@@ -5,9 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -21,35 +23,47 @@ func NewPostgresSupplierRepository(database *db.Database) SupplierRepository {
 	}
 }
 
-func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error) {
+// SupplierSorting lists the fields suppliers can be sorted by
+var SupplierSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":         {Column: "s.supplier_id", Type: "integer"},
+		"name":       {Column: "s.name", Type: "text"},
+		"created_at": {Column: "s.created_at", Type: "timestamptz"},
+	},
+	Default:  "name",
+	IDColumn: "s.supplier_id",
+}
+
+func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter, page *pagination.Page) ([]*suppliermodels.Supplier, error) {
 	query := `
-        SELECT DISTINCT s.supplier_id, s.name, s.contact_person, s.phone, s.email,
+        SELECT s.supplier_id, s.name, s.contact_person, s.phone, s.email,
                s.address, s.tax_number, s.notes, s.is_active, s.created_at, s.updated_at
         FROM arac.suppliers s
+        WHERE 1=1
     `
 	params := []interface{}{}
 	paramCount := 1
 
 	if filter != nil {
 		if filter.SearchTerm != nil {
-			query += fmt.Sprintf(" WHERE (s.name ILIKE $%d OR s.contact_person ILIKE $%d OR s.email ILIKE $%d)",
+			query += fmt.Sprintf(" AND (s.name ILIKE $%d OR s.contact_person ILIKE $%d OR s.email ILIKE $%d)",
 				paramCount, paramCount, paramCount)
 			params = append(params, "%"+*filter.SearchTerm+"%")
 			paramCount++
 		}
 
 		if filter.HasActiveItems != nil && *filter.HasActiveItems {
-			if paramCount == 1 {
-				query += " WHERE"
-			} else {
-				query += " AND"
-			}
-			query += " EXISTS (SELECT 1 FROM items i WHERE i.supplier_id = s.supplier_id AND i.is_active = true)"
+			query += " AND EXISTS (SELECT 1 FROM items i WHERE i.supplier_id = s.supplier_id AND i.is_active = true)"
 		}
 	}
 
-	query += " ORDER BY s.name"
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), params...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
 
+	query, params = SupplierSorting.Apply(query, params, page)
 	rows, err := r.db.Pool.Query(ctx, query, params...)
 	if err != nil {
 		return nil, err
@@ -77,8 +91,16 @@ func (r *PostgresSupplierRepository) GetAll(ctx context.Context, filter *supplie
 		}
 		suppliers = append(suppliers, supplier)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if page != nil && len(suppliers) > 0 {
+		last := suppliers[len(suppliers)-1]
+		page.SetNextCursor(len(suppliers), supplierSortValue(last, page.Sort), last.SupplierID)
+	}
 
-	return suppliers, rows.Err()
+	return suppliers, nil
 }
 
 func (r *PostgresSupplierRepository) GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error) {
@@ -192,3 +214,16 @@ func (r *PostgresSupplierRepository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// supplierSortValue returns the value a supplier is sorted by, as
+// SupplierSorting compares it
+func supplierSortValue(supplier *suppliermodels.Supplier, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(supplier.SupplierID)
+	case "created_at":
+		return pagination.Time(supplier.CreatedAt)
+	default:
+		return supplier.Name
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"context"
 
 	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 type SupplierRepository interface {
-    GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error)
+    GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter, page *pagination.Page) ([]*suppliermodels.Supplier, error)
     GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error)
     Create(ctx context.Context, supplier *suppliermodels.Supplier) (int, error)
     Update(ctx context.Context, supplier *suppliermodels.Supplier) error
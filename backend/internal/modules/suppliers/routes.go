@@ -1,6 +1,8 @@
 package suppliers
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers/services"
@@ -18,11 +20,15 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
     // Initialize handler
     handler := handlers.NewSupplierHandler(service)
 
+    // Permissions
+    read := authmiddleware.RequirePermission(authmodels.PermSuppliersRead)
+    write := authmiddleware.RequirePermission(authmodels.PermSuppliersWrite)
+
     // Register routes
     suppliers := api.Group("/suppliers")
-    suppliers.GET("", handler.GetSuppliers)
-    suppliers.GET("/:id", handler.GetSupplierByID)
-    suppliers.POST("", handler.CreateSupplier)
-    suppliers.PUT("/:id", handler.UpdateSupplier)
-    suppliers.DELETE("/:id", handler.DeleteSupplier)
+    suppliers.GET("", handler.GetSuppliers, read)
+    suppliers.GET("/:id", handler.GetSupplierByID, read)
+    suppliers.POST("", handler.CreateSupplier, write)
+    suppliers.PUT("/:id", handler.UpdateSupplier, write)
+    suppliers.DELETE("/:id", handler.DeleteSupplier, write)
 }
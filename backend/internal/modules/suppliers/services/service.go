@@ -6,6 +6,7 @@ import (
 
 	suppliermodels "github.com/hsrvms/autoparts/internal/modules/suppliers/models"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 var (
@@ -15,8 +16,11 @@ var (
 	ErrSupplierHasItems      = errors.New("cannot delete supplier with associated items")
 )
 
+// SupplierSorting lists the fields suppliers can be sorted by
+var SupplierSorting = repositories.SupplierSorting
+
 type SupplierService interface {
-	GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error)
+	GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter, page *pagination.Page) ([]*suppliermodels.Supplier, error)
 	GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error)
 	Create(ctx context.Context, supplier *suppliermodels.Supplier) (int, error)
 	Update(ctx context.Context, supplier *suppliermodels.Supplier) error
@@ -33,8 +37,8 @@ func NewSupplierService(repo repositories.SupplierRepository) SupplierService {
 	}
 }
 
-func (s *supplierService) GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter) ([]*suppliermodels.Supplier, error) {
-	return s.repo.GetAll(ctx, filter)
+func (s *supplierService) GetAll(ctx context.Context, filter *suppliermodels.SupplierFilter, page *pagination.Page) ([]*suppliermodels.Supplier, error) {
+	return s.repo.GetAll(ctx, filter, page)
 }
 
 func (s *supplierService) GetByID(ctx context.Context, id int) (*suppliermodels.Supplier, error) {
@@ -61,7 +65,7 @@ func (s *supplierService) Create(ctx context.Context, supplier *suppliermodels.S
 	// Check for existing suppliers with the same name
 	existing, err := s.repo.GetAll(ctx, &suppliermodels.SupplierFilter{
 		SearchTerm: &supplier.Name,
-	})
+	}, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -97,7 +101,7 @@ func (s *supplierService) Update(ctx context.Context, supplier *suppliermodels.S
 	if existing.Name != supplier.Name {
 		suppliers, err := s.repo.GetAll(ctx, &suppliermodels.SupplierFilter{
 			SearchTerm: &supplier.Name,
-		})
+		}, nil)
 		if err != nil {
 			return err
 		}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
 	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/labstack/echo/v4"
 )
 
@@ -224,12 +228,36 @@ func (h *VehicleHandler) DeleteModel(c echo.Context) error {
 
 // Submodel handlers
 func (h *VehicleHandler) GetAllSubmodels(c echo.Context) error {
+	page, err := pagination.FromRequest(c, services.SubmodelSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	ctx := c.Request().Context()
-	submodels, err := h.service.GetAllSubmodels(ctx)
+	submodels, err := h.service.GetAllSubmodels(ctx, page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return c.JSON(http.StatusOK, submodels)
+	return pagination.Respond(c, page, submodels)
+}
+
+// GetBodyStylesByModel handles listing the body styles of a model
+func (h *VehicleHandler) GetBodyStylesByModel(c echo.Context) error {
+	modelID, err := strconv.Atoi(c.Param("modelId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid model ID")
+	}
+
+	ctx := c.Request().Context()
+	bodyStyles, err := h.service.GetBodyStylesByModel(ctx, modelID)
+	if err != nil {
+		if err == services.ErrModelNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, bodyStyles)
 }
 
 func (h *VehicleHandler) GetSubmodelsByModel(c echo.Context) error {
@@ -334,3 +362,214 @@ func (h *VehicleHandler) DeleteSubmodel(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// SeedVehicles handles loading makes, models and body styles from an
+// uploaded copy of the vehicle catalog (Vehicle.csv)
+func (h *VehicleHandler) SeedVehicles(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	defer src.Close()
+
+	ctx := c.Request().Context()
+	result, err := h.service.SeedVehicles(ctx, src)
+	if err != nil {
+		var parseErr *csv.ParseError
+		switch {
+		case errors.Is(err, services.ErrEmptySeedFile), errors.Is(err, services.ErrMissingSeedColumn),
+			errors.As(err, &parseErr):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Staged submodel handlers
+
+// StageSubmodelListings handles queueing the engine variants of uploaded
+// scraped listing fragments for review. Several files can be sent as file.
+func (h *VehicleHandler) StageSubmodelListings(c echo.Context) error {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	var files []io.Reader
+	for _, header := range form.File["file"] {
+		src, err := header.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		defer src.Close()
+		files = append(files, src)
+	}
+
+	ctx := c.Request().Context()
+	result, err := h.service.StageSubmodelListings(ctx, files)
+	if err != nil {
+		switch err {
+		case services.ErrNoListings:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *VehicleHandler) GetStagedSubmodels(c echo.Context) error {
+	ctx := c.Request().Context()
+	staged, err := h.service.GetStagedSubmodels(ctx, c.QueryParam("status"))
+	if err != nil {
+		switch err {
+		case services.ErrInvalidStagingStatus:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, staged)
+}
+
+func (h *VehicleHandler) UpdateStagedSubmodel(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid staged submodel ID")
+	}
+
+	staged := new(vehiclemodels.StagedSubmodel)
+	if err := c.Bind(staged); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	staged.StagedID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateStagedSubmodel(ctx, staged); err != nil {
+		return stagingError(err)
+	}
+
+	return c.JSON(http.StatusOK, staged)
+}
+
+// ApproveStagedSubmodel handles creating the submodel a staged variant
+// describes
+func (h *VehicleHandler) ApproveStagedSubmodel(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid staged submodel ID")
+	}
+
+	ctx := c.Request().Context()
+	submodel, err := h.service.ApproveStagedSubmodel(ctx, id)
+	if err != nil {
+		return stagingError(err)
+	}
+
+	return c.JSON(http.StatusCreated, submodel)
+}
+
+func (h *VehicleHandler) RejectStagedSubmodel(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid staged submodel ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.service.RejectStagedSubmodel(ctx, id); err != nil {
+		return stagingError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Vehicle identification handlers
+
+// DecodeVIN handles decoding a VIN into its make, model year and the
+// submodels it may be, with the items that fit them
+func (h *VehicleHandler) DecodeVIN(c echo.Context) error {
+	ctx := c.Request().Context()
+	decoded, err := h.service.DecodeVIN(ctx, c.Param("vin"))
+	if err != nil {
+		switch err {
+		case services.ErrInvalidVIN:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, decoded)
+}
+
+func (h *VehicleHandler) GetVINManufacturers(c echo.Context) error {
+	ctx := c.Request().Context()
+	manufacturers, err := h.service.GetVINManufacturers(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, manufacturers)
+}
+
+// SaveVINManufacturer handles adding a manufacturer identifier to the local
+// table or changing the make it belongs to
+func (h *VehicleHandler) SaveVINManufacturer(c echo.Context) error {
+	manufacturer := new(vehiclemodels.VINManufacturer)
+	if err := c.Bind(manufacturer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	manufacturer.WMI = c.Param("wmi")
+
+	ctx := c.Request().Context()
+	if err := h.service.SaveVINManufacturer(ctx, manufacturer); err != nil {
+		switch err {
+		case services.ErrMakeNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrInvalidWMI, services.ErrInvalidMakeID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, manufacturer)
+}
+
+func (h *VehicleHandler) DeleteVINManufacturer(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.service.DeleteVINManufacturer(ctx, c.Param("wmi")); err != nil {
+		switch err {
+		case services.ErrVINManufacturerNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// stagingError maps the errors of reviewing a staged submodel to responses
+func stagingError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrStagedSubmodelNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrStagedSubmodelReviewed), errors.Is(err, services.ErrDuplicateSubmodel):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrIncompleteSubmodel):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
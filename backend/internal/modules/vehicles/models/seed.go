@@ -0,0 +1,36 @@
+package vehiclemodels
+
+// What seeding did with a row of the vehicle catalog
+const (
+	SeedStatusInserted    = "inserted"
+	SeedStatusSkipped     = "skipped"
+	SeedStatusConflicting = "conflicting"
+)
+
+// VehicleSeedRow is one row of the vehicle catalog that was not inserted
+type VehicleSeedRow struct {
+	Row         int    `json:"row"`
+	Make        string `json:"make"`
+	Model       string `json:"model"`
+	BodyStyle   string `json:"body_style,omitempty"`
+	VehicleType string `json:"vehicle_type,omitempty"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason"`
+}
+
+// VehicleSeedResult summarises loading the vehicle catalog. Rows are
+// inserted when they add a make, model or body style, skipped when there is
+// nothing new in them, and conflicting when they disagree with a record that
+// is already there. Existing records are never changed.
+type VehicleSeedResult struct {
+	Inserted    int `json:"inserted"`
+	Skipped     int `json:"skipped"`
+	Conflicting int `json:"conflicting"`
+
+	MakesCreated      int `json:"makes_created"`
+	ModelsCreated     int `json:"models_created"`
+	BodyStylesCreated int `json:"body_styles_created"`
+
+	// Rows lists the skipped and conflicting rows with the reason
+	Rows []*VehicleSeedRow `json:"rows"`
+}
@@ -0,0 +1,56 @@
+package vehiclemodels
+
+import "time"
+
+// Review status of a staged submodel
+const (
+	StagingStatusPending  = "pending"
+	StagingStatusApproved = "approved"
+	StagingStatusRejected = "rejected"
+)
+
+// Fuel types, as the app offers them
+const (
+	FuelPetrol   = "Benzin"
+	FuelDiesel   = "Dizel"
+	FuelHybrid   = "Hibrit"
+	FuelElectric = "Elektrik"
+	FuelLPG      = "LPG"
+)
+
+// StagedSubmodel is an engine variant read from a scraped listing, waiting
+// for review. Approving it creates the submodel, and the make and model if
+// they do not exist yet.
+type StagedSubmodel struct {
+	StagedID   int    `json:"staged_id" db:"staged_id"`
+	SourceSlug string `json:"source_slug" db:"source_slug"`
+
+	MakeName           string   `json:"make_name" db:"make_name"`
+	ModelName          string   `json:"model_name" db:"model_name"`
+	SubmodelName       string   `json:"submodel_name" db:"submodel_name"`
+	YearFrom           *int     `json:"year_from" db:"year_from"`
+	YearTo             *int     `json:"year_to" db:"year_to"`
+	EngineType         string   `json:"engine_type" db:"engine_type"`
+	EngineDisplacement *float64 `json:"engine_displacement" db:"engine_displacement"`
+	FuelType           *string  `json:"fuel_type" db:"fuel_type"`
+	TransmissionType   *string  `json:"transmission_type" db:"transmission_type"`
+	BodyType           *string  `json:"body_type" db:"body_type"`
+
+	// ListingCount is how many listings the source had for the variant
+	ListingCount int       `json:"listing_count" db:"listing_count"`
+	Status       string    `json:"status" db:"status"`
+	SubmodelID   *int      `json:"submodel_id,omitempty" db:"submodel_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SubmodelStagingResult summarises staging scraped listings. Variants that
+// were staged before keep their reviewed values; only the listing count is
+// refreshed.
+type SubmodelStagingResult struct {
+	Staged  int `json:"staged"`
+	Updated int `json:"updated"`
+	// Unresolved lists the links of variants whose make or model was in
+	// neither the uploaded files nor the catalog
+	Unresolved []string `json:"unresolved"`
+}
@@ -23,6 +23,16 @@ type Model struct {
 	MakeName string `json:"make_name,omitempty" db:"-"`
 }
 
+// BodyStyle is a body a model is built with (Sedan, Hatchback, ...), as
+// the vehicle catalog lists it. It says nothing about years or engines.
+type BodyStyle struct {
+	BodyStyleID int       `json:"body_style_id" db:"body_style_id"`
+	ModelID     int       `json:"model_id" db:"model_id"`
+	BodyStyle   string    `json:"body_style" db:"body_style"`
+	VehicleType *string   `json:"vehicle_type,omitempty" db:"vehicle_type"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
 // Submodel represents specific variants of a model
 type Submodel struct {
 	SubmodelID         int       `json:"submodel_id" db:"submodel_id"`
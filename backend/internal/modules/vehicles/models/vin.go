@@ -0,0 +1,62 @@
+package vehiclemodels
+
+import "time"
+
+// VINManufacturer ties a world manufacturer identifier, the first three
+// characters of a VIN, to a make
+type VINManufacturer struct {
+	WMI          string    `json:"wmi" db:"wmi"`
+	MakeID       int       `json:"make_id" db:"make_id"`
+	Manufacturer *string   `json:"manufacturer,omitempty" db:"manufacturer"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	MakeName string `json:"make_name,omitempty" db:"-"`
+}
+
+// DecodedVIN is what a VIN tells about a vehicle, with the submodels it may
+// be. The VIN does not say which submodel it is, so the submodels of the
+// make are candidates, nearest to the model year first.
+type DecodedVIN struct {
+	VIN string `json:"vin"`
+	// WMI identifies the manufacturer, VDS describes the vehicle in the
+	// manufacturer's own way and VIS identifies the single vehicle
+	WMI    string `json:"wmi"`
+	VDS    string `json:"vds"`
+	VIS    string `json:"vis"`
+	Region string `json:"region"`
+	// Manufacturer names who the WMI stands for, when it is known
+	Manufacturer *string `json:"manufacturer,omitempty"`
+	// ModelYear is read from the tenth character. Many European makers do
+	// not fill it in, so it may be wrong for them.
+	ModelYear *int `json:"model_year,omitempty"`
+	// ModelYearReliable tells whether the model year can be trusted: the
+	// check digit matches and the VIN is not European. Only then are the
+	// candidates limited to the model year; otherwise they are ordered by
+	// how far their years are from it.
+	ModelYearReliable bool `json:"model_year_reliable"`
+	// CheckDigitValid tells whether the ninth character matches the check
+	// digit. Only North American VINs are required to have one.
+	CheckDigitValid bool `json:"check_digit_valid"`
+
+	Make       *Make           `json:"make,omitempty"`
+	Candidates []*VINCandidate `json:"candidates"`
+}
+
+// VINCandidate is a submodel a VIN may belong to, with the items that fit it
+type VINCandidate struct {
+	*Submodel
+	Items []*VINItem `json:"items"`
+}
+
+// VINItem is an active item that fits a candidate submodel
+type VINItem struct {
+	SubmodelID   int     `json:"-"`
+	ItemID       int     `json:"item_id"`
+	PartNumber   string  `json:"part_number"`
+	Description  *string `json:"description,omitempty"`
+	CategoryName *string `json:"category_name,omitempty"`
+	SellPrice    float64 `json:"sell_price"`
+	CurrentStock int     `json:"current_stock"`
+}
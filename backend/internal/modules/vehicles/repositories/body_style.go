@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Body style operations
+func (r *PostgresVehicleRepository) GetBodyStylesByModel(ctx context.Context, modelID int) ([]*vehiclemodels.BodyStyle, error) {
+	query := `
+		SELECT body_style_id, model_id, body_style, vehicle_type, created_at
+		FROM arac.model_body_styles
+		WHERE model_id = $1
+		ORDER BY body_style
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, modelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bodyStyles []*vehiclemodels.BodyStyle
+	for rows.Next() {
+		bodyStyle, err := scanBodyStyle(rows)
+		if err != nil {
+			return nil, err
+		}
+		bodyStyles = append(bodyStyles, bodyStyle)
+	}
+
+	return bodyStyles, rows.Err()
+}
+
+// GetBodyStyleByName finds a body style of a model, ignoring case
+func (r *PostgresVehicleRepository) GetBodyStyleByName(ctx context.Context, modelID int, name string) (*vehiclemodels.BodyStyle, error) {
+	query := `
+		SELECT body_style_id, model_id, body_style, vehicle_type, created_at
+		FROM arac.model_body_styles
+		WHERE model_id = $1 AND LOWER(body_style) = LOWER($2)
+	`
+
+	bodyStyle, err := scanBodyStyle(r.db.Pool.QueryRow(ctx, query, modelID, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return bodyStyle, nil
+}
+
+func (r *PostgresVehicleRepository) CreateBodyStyle(ctx context.Context, bodyStyle *vehiclemodels.BodyStyle) (int, error) {
+	query := `
+		INSERT INTO arac.model_body_styles (model_id, body_style, vehicle_type)
+		VALUES ($1, $2, $3)
+		RETURNING body_style_id, created_at
+	`
+
+	err := r.db.Pool.QueryRow(
+		ctx, query,
+		bodyStyle.ModelID,
+		bodyStyle.BodyStyle,
+		bodyStyle.VehicleType,
+	).Scan(&bodyStyle.BodyStyleID, &bodyStyle.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return bodyStyle.BodyStyleID, nil
+}
+
+func scanBodyStyle(row pgx.Row) (*vehiclemodels.BodyStyle, error) {
+	bodyStyle := &vehiclemodels.BodyStyle{}
+	err := row.Scan(
+		&bodyStyle.BodyStyleID,
+		&bodyStyle.ModelID,
+		&bodyStyle.BodyStyle,
+		&bodyStyle.VehicleType,
+		&bodyStyle.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return bodyStyle, nil
+}
@@ -4,9 +4,11 @@ package repositories
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
 	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -79,6 +81,36 @@ func (r *PostgresVehicleRepository) GetMakeByID(ctx context.Context, id int) (*v
 	return make, nil
 }
 
+// GetMakeByName finds a make by name, ignoring case. An exact match wins
+// over one that differs only in case.
+func (r *PostgresVehicleRepository) GetMakeByName(ctx context.Context, name string) (*vehiclemodels.Make, error) {
+	query := `
+		SELECT make_id, make_name, country, created_at, updated_at
+		FROM arac.makes
+		WHERE lower(make_name) = lower($1)
+		ORDER BY make_name = $1 DESC
+		LIMIT 1
+	`
+
+	make := &vehiclemodels.Make{}
+	err := r.db.Pool.QueryRow(ctx, query, name).Scan(
+		&make.MakeID,
+		&make.MakeName,
+		&make.Country,
+		&make.CreatedAt,
+		&make.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return make, nil
+}
+
 func (r *PostgresVehicleRepository) CreateMake(ctx context.Context, make *vehiclemodels.Make) (int, error) {
 	query := `
 		INSERT INTO arac.makes (make_name, country)
@@ -230,6 +262,38 @@ func (r *PostgresVehicleRepository) GetModelByID(ctx context.Context, id int) (*
 	return model, nil
 }
 
+// GetModelByName finds a model of a make by name, ignoring case
+func (r *PostgresVehicleRepository) GetModelByName(ctx context.Context, makeID int, name string) (*vehiclemodels.Model, error) {
+	query := `
+		SELECT m.model_id, m.make_id, m.model_name, m.created_at, m.updated_at,
+			   mk.make_name
+		FROM arac.models m
+		JOIN arac.makes mk ON m.make_id = mk.make_id
+		WHERE m.make_id = $1 AND lower(m.model_name) = lower($2)
+		ORDER BY m.model_name = $2 DESC
+		LIMIT 1
+	`
+
+	model := &vehiclemodels.Model{}
+	err := r.db.Pool.QueryRow(ctx, query, makeID, name).Scan(
+		&model.ModelID,
+		&model.MakeID,
+		&model.ModelName,
+		&model.CreatedAt,
+		&model.UpdatedAt,
+		&model.MakeName,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return model, nil
+}
+
 func (r *PostgresVehicleRepository) CreateModel(ctx context.Context, model *vehiclemodels.Model) (int, error) {
 	query := `
 		INSERT INTO arac.models (make_id, model_name)
@@ -280,8 +344,22 @@ func (r *PostgresVehicleRepository) DeleteModel(ctx context.Context, id int) err
 	return nil
 }
 
+// SubmodelSorting lists the fields submodels can be sorted by. Sorting by
+// vehicle orders them by make, then model, then submodel name.
+var SubmodelSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":            {Column: "s.submodel_id", Type: "integer"},
+		"vehicle":       {Column: "concat_ws(' ', mk.make_name, m.model_name, s.submodel_name)", Type: "text"},
+		"submodel_name": {Column: "s.submodel_name", Type: "text"},
+		"year_from":     {Column: "s.year_from", Type: "integer"},
+		"created_at":    {Column: "s.created_at", Type: "timestamptz"},
+	},
+	Default:  "vehicle",
+	IDColumn: "s.submodel_id",
+}
+
 // Submodel operations
-func (r *PostgresVehicleRepository) GetAllSubmodels(ctx context.Context) ([]*vehiclemodels.Submodel, error) {
+func (r *PostgresVehicleRepository) GetAllSubmodels(ctx context.Context, page *pagination.Page) ([]*vehiclemodels.Submodel, error) {
 	query := `
 		SELECT s.submodel_id, s.model_id, s.submodel_name, s.year_from, s.year_to,
 			   s.engine_type, s.engine_displacement, s.fuel_type, s.transmission_type,
@@ -290,10 +368,18 @@ func (r *PostgresVehicleRepository) GetAllSubmodels(ctx context.Context) ([]*veh
 		FROM arac.submodels s
 		JOIN arac.models m ON s.model_id = m.model_id
 		JOIN arac.makes mk ON m.make_id = mk.make_id
-		ORDER BY mk.make_name, m.model_name, s.submodel_name
+		WHERE 1=1
 	`
+	var params []interface{}
 
-	rows, err := r.db.Pool.Query(ctx, query)
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query)).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	query, params = SubmodelSorting.Apply(query, params, page)
+	rows, err := r.db.Pool.Query(ctx, query, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -323,8 +409,16 @@ func (r *PostgresVehicleRepository) GetAllSubmodels(ctx context.Context) ([]*veh
 		}
 		submodels = append(submodels, submodel)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return submodels, rows.Err()
+	if page != nil && len(submodels) > 0 {
+		last := submodels[len(submodels)-1]
+		page.SetNextCursor(len(submodels), submodelSortValue(last, page.Sort), last.SubmodelID)
+	}
+
+	return submodels, nil
 }
 
 func (r *PostgresVehicleRepository) GetSubmodelsByModel(ctx context.Context, modelID int) ([]*vehiclemodels.Submodel, error) {
@@ -496,3 +590,20 @@ func (r *PostgresVehicleRepository) DeleteSubmodel(ctx context.Context, id int)
 
 	return nil
 }
+
+// submodelSortValue returns the value a submodel is sorted by, as
+// SubmodelSorting compares it
+func submodelSortValue(submodel *vehiclemodels.Submodel, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(submodel.SubmodelID)
+	case "submodel_name":
+		return submodel.SubmodelName
+	case "year_from":
+		return strconv.Itoa(submodel.YearFrom)
+	case "created_at":
+		return pagination.Time(submodel.CreatedAt)
+	default:
+		return submodel.MakeName + " " + submodel.ModelName + " " + submodel.SubmodelName
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 
 	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 // VehicleRepository defines the interface for vehicle database operations
@@ -12,6 +13,7 @@ type VehicleRepository interface {
 	// Make operations
 	GetAllMakes(ctx context.Context) ([]*vehiclemodels.Make, error)
 	GetMakeByID(ctx context.Context, id int) (*vehiclemodels.Make, error)
+	GetMakeByName(ctx context.Context, name string) (*vehiclemodels.Make, error)
 	CreateMake(ctx context.Context, make *vehiclemodels.Make) (int, error)
 	UpdateMake(ctx context.Context, make *vehiclemodels.Make) error
 	DeleteMake(ctx context.Context, id int) error
@@ -20,15 +22,35 @@ type VehicleRepository interface {
 	GetAllModels(ctx context.Context) ([]*vehiclemodels.Model, error)
 	GetModelsByMake(ctx context.Context, makeID int) ([]*vehiclemodels.Model, error)
 	GetModelByID(ctx context.Context, id int) (*vehiclemodels.Model, error)
+	GetModelByName(ctx context.Context, makeID int, name string) (*vehiclemodels.Model, error)
 	CreateModel(ctx context.Context, model *vehiclemodels.Model) (int, error)
 	UpdateModel(ctx context.Context, model *vehiclemodels.Model) error
 	DeleteModel(ctx context.Context, id int) error
 
+	// Body style operations
+	GetBodyStylesByModel(ctx context.Context, modelID int) ([]*vehiclemodels.BodyStyle, error)
+	GetBodyStyleByName(ctx context.Context, modelID int, name string) (*vehiclemodels.BodyStyle, error)
+	CreateBodyStyle(ctx context.Context, bodyStyle *vehiclemodels.BodyStyle) (int, error)
+
 	// Submodel operations
-	GetAllSubmodels(ctx context.Context) ([]*vehiclemodels.Submodel, error)
+	GetAllSubmodels(ctx context.Context, page *pagination.Page) ([]*vehiclemodels.Submodel, error)
 	GetSubmodelsByModel(ctx context.Context, modelID int) ([]*vehiclemodels.Submodel, error)
 	GetSubmodelByID(ctx context.Context, id int) (*vehiclemodels.Submodel, error)
 	CreateSubmodel(ctx context.Context, submodel *vehiclemodels.Submodel) (int, error)
 	UpdateSubmodel(ctx context.Context, submodel *vehiclemodels.Submodel) error
 	DeleteSubmodel(ctx context.Context, id int) error
+
+	// Staged submodel operations
+	StageSubmodels(ctx context.Context, staged []*vehiclemodels.StagedSubmodel) (int, error)
+	GetStagedSubmodels(ctx context.Context, status string) ([]*vehiclemodels.StagedSubmodel, error)
+	GetStagedSubmodelByID(ctx context.Context, id int) (*vehiclemodels.StagedSubmodel, error)
+	UpdateStagedSubmodel(ctx context.Context, submodel *vehiclemodels.StagedSubmodel) error
+
+	// Vehicle identification operations
+	GetVINManufacturers(ctx context.Context) ([]*vehiclemodels.VINManufacturer, error)
+	GetVINManufacturer(ctx context.Context, wmi string) (*vehiclemodels.VINManufacturer, error)
+	SaveVINManufacturer(ctx context.Context, manufacturer *vehiclemodels.VINManufacturer) error
+	DeleteVINManufacturer(ctx context.Context, wmi string) error
+	GetSubmodelsByMakeAndYear(ctx context.Context, makeID int, year *int) ([]*vehiclemodels.Submodel, error)
+	GetItemsForSubmodels(ctx context.Context, submodelIDs []int) ([]*vehiclemodels.VINItem, error)
 }
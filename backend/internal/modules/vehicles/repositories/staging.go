@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const stagedSubmodelColumns = `
+	staged_id, source_slug, make_name, model_name, submodel_name,
+	year_from, year_to, engine_type, engine_displacement, fuel_type,
+	transmission_type, body_type, listing_count, status, submodel_id,
+	created_at, updated_at
+`
+
+// StageSubmodels adds variants to the review queue. Variants already in it,
+// matched by their source link, only get their listing count refreshed so
+// reviewed values are kept. It returns how many were new.
+func (r *PostgresVehicleRepository) StageSubmodels(ctx context.Context, staged []*vehiclemodels.StagedSubmodel) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO arac.staged_submodels (
+			source_slug, make_name, model_name, submodel_name,
+			engine_type, engine_displacement, fuel_type, listing_count
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (source_slug) DO UPDATE SET listing_count = EXCLUDED.listing_count
+		RETURNING staged_id, status, (xmax = 0) AS inserted
+	`
+
+	created := 0
+	for _, submodel := range staged {
+		var inserted bool
+		err := tx.QueryRow(
+			ctx,
+			query,
+			submodel.SourceSlug,
+			submodel.MakeName,
+			submodel.ModelName,
+			submodel.SubmodelName,
+			submodel.EngineType,
+			submodel.EngineDisplacement,
+			submodel.FuelType,
+			submodel.ListingCount,
+		).Scan(&submodel.StagedID, &submodel.Status, &inserted)
+		if err != nil {
+			return 0, err
+		}
+		if inserted {
+			created++
+		}
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+// GetStagedSubmodels lists staged variants, optionally only those with a
+// status
+func (r *PostgresVehicleRepository) GetStagedSubmodels(ctx context.Context, status string) ([]*vehiclemodels.StagedSubmodel, error) {
+	query := `SELECT ` + stagedSubmodelColumns + ` FROM arac.staged_submodels WHERE 1=1`
+	var params []interface{}
+	if status != "" {
+		query += ` AND status = $1`
+		params = append(params, status)
+	}
+	query += ` ORDER BY make_name, model_name, submodel_name`
+
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var staged []*vehiclemodels.StagedSubmodel
+	for rows.Next() {
+		submodel, err := scanStagedSubmodel(rows)
+		if err != nil {
+			return nil, err
+		}
+		staged = append(staged, submodel)
+	}
+
+	return staged, rows.Err()
+}
+
+func (r *PostgresVehicleRepository) GetStagedSubmodelByID(ctx context.Context, id int) (*vehiclemodels.StagedSubmodel, error) {
+	query := `SELECT ` + stagedSubmodelColumns + ` FROM arac.staged_submodels WHERE staged_id = $1`
+
+	submodel, err := scanStagedSubmodel(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return submodel, nil
+}
+
+// UpdateStagedSubmodel saves the reviewed values, status and created
+// submodel of a staged variant
+func (r *PostgresVehicleRepository) UpdateStagedSubmodel(ctx context.Context, submodel *vehiclemodels.StagedSubmodel) error {
+	query := `
+		UPDATE arac.staged_submodels
+		SET make_name = $2, model_name = $3, submodel_name = $4,
+			year_from = $5, year_to = $6, engine_type = $7, engine_displacement = $8,
+			fuel_type = $9, transmission_type = $10, body_type = $11,
+			status = $12, submodel_id = $13
+		WHERE staged_id = $1
+	`
+
+	result, err := r.db.Pool.Exec(
+		ctx,
+		query,
+		submodel.StagedID,
+		submodel.MakeName,
+		submodel.ModelName,
+		submodel.SubmodelName,
+		submodel.YearFrom,
+		submodel.YearTo,
+		submodel.EngineType,
+		submodel.EngineDisplacement,
+		submodel.FuelType,
+		submodel.TransmissionType,
+		submodel.BodyType,
+		submodel.Status,
+		submodel.SubmodelID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("staged submodel not found")
+	}
+
+	return nil
+}
+
+func scanStagedSubmodel(row pgx.Row) (*vehiclemodels.StagedSubmodel, error) {
+	submodel := &vehiclemodels.StagedSubmodel{}
+	var engineType *string
+	err := row.Scan(
+		&submodel.StagedID,
+		&submodel.SourceSlug,
+		&submodel.MakeName,
+		&submodel.ModelName,
+		&submodel.SubmodelName,
+		&submodel.YearFrom,
+		&submodel.YearTo,
+		&engineType,
+		&submodel.EngineDisplacement,
+		&submodel.FuelType,
+		&submodel.TransmissionType,
+		&submodel.BodyType,
+		&submodel.ListingCount,
+		&submodel.Status,
+		&submodel.SubmodelID,
+		&submodel.CreatedAt,
+		&submodel.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if engineType != nil {
+		submodel.EngineType = *engineType
+	}
+	return submodel, nil
+}
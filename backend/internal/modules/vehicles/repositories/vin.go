@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// VIN manufacturer operations
+func (r *PostgresVehicleRepository) GetVINManufacturers(ctx context.Context) ([]*vehiclemodels.VINManufacturer, error) {
+	query := `
+		SELECT v.wmi, v.make_id, v.manufacturer, v.created_at, v.updated_at, m.make_name
+		FROM arac.vin_manufacturers v
+		JOIN arac.makes m ON v.make_id = m.make_id
+		ORDER BY v.wmi
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var manufacturers []*vehiclemodels.VINManufacturer
+	for rows.Next() {
+		manufacturer, err := scanVINManufacturer(rows)
+		if err != nil {
+			return nil, err
+		}
+		manufacturers = append(manufacturers, manufacturer)
+	}
+
+	return manufacturers, rows.Err()
+}
+
+func (r *PostgresVehicleRepository) GetVINManufacturer(ctx context.Context, wmi string) (*vehiclemodels.VINManufacturer, error) {
+	query := `
+		SELECT v.wmi, v.make_id, v.manufacturer, v.created_at, v.updated_at, m.make_name
+		FROM arac.vin_manufacturers v
+		JOIN arac.makes m ON v.make_id = m.make_id
+		WHERE v.wmi = $1
+	`
+
+	manufacturer, err := scanVINManufacturer(r.db.Pool.QueryRow(ctx, query, wmi))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return manufacturer, nil
+}
+
+// SaveVINManufacturer adds a manufacturer identifier, or updates the make
+// and name of one that exists
+func (r *PostgresVehicleRepository) SaveVINManufacturer(ctx context.Context, manufacturer *vehiclemodels.VINManufacturer) error {
+	query := `
+		INSERT INTO arac.vin_manufacturers (wmi, make_id, manufacturer)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (wmi) DO UPDATE
+		SET make_id = EXCLUDED.make_id, manufacturer = EXCLUDED.manufacturer
+		RETURNING created_at, updated_at
+	`
+
+	return r.db.Pool.QueryRow(
+		ctx, query,
+		manufacturer.WMI,
+		manufacturer.MakeID,
+		manufacturer.Manufacturer,
+	).Scan(&manufacturer.CreatedAt, &manufacturer.UpdatedAt)
+}
+
+func (r *PostgresVehicleRepository) DeleteVINManufacturer(ctx context.Context, wmi string) error {
+	query := `DELETE FROM arac.vin_manufacturers WHERE wmi = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, wmi)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("VIN manufacturer not found")
+	}
+
+	return nil
+}
+
+// GetSubmodelsByMakeAndYear lists the submodels of a make built in a year,
+// or all of them without a year. Submodels whose years are not known (a
+// year_from of 0) are left out when a year is given.
+func (r *PostgresVehicleRepository) GetSubmodelsByMakeAndYear(ctx context.Context, makeID int, year *int) ([]*vehiclemodels.Submodel, error) {
+	query := `
+		SELECT s.submodel_id, s.model_id, s.submodel_name, s.year_from, s.year_to,
+			   COALESCE(s.engine_type, ''), s.engine_displacement, s.fuel_type, s.transmission_type,
+			   s.body_type, s.created_at, s.updated_at,
+			   m.model_name, mk.make_name
+		FROM arac.submodels s
+		JOIN arac.models m ON s.model_id = m.model_id
+		JOIN arac.makes mk ON m.make_id = mk.make_id
+		WHERE m.make_id = $1
+			AND ($2::integer IS NULL OR (s.year_from > 0 AND s.year_from <= $2 AND (s.year_to IS NULL OR s.year_to >= $2)))
+		ORDER BY m.model_name, s.year_from DESC, s.submodel_name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, makeID, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submodels []*vehiclemodels.Submodel
+	for rows.Next() {
+		submodel := &vehiclemodels.Submodel{}
+		err := rows.Scan(
+			&submodel.SubmodelID,
+			&submodel.ModelID,
+			&submodel.SubmodelName,
+			&submodel.YearFrom,
+			&submodel.YearTo,
+			&submodel.EngineType,
+			&submodel.EngineDisplacement,
+			&submodel.FuelType,
+			&submodel.TransmissionType,
+			&submodel.BodyType,
+			&submodel.CreatedAt,
+			&submodel.UpdatedAt,
+			&submodel.ModelName,
+			&submodel.MakeName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		submodels = append(submodels, submodel)
+	}
+
+	return submodels, rows.Err()
+}
+
+// GetItemsForSubmodels lists the active items that fit each of the
+// submodels, through the vehicle on the item or its compatibilities. An
+// item fitting several submodels is listed once for each.
+func (r *PostgresVehicleRepository) GetItemsForSubmodels(ctx context.Context, submodelIDs []int) ([]*vehiclemodels.VINItem, error) {
+	query := `
+		SELECT fits.submodel_id, i.item_id, i.part_number, i.description, c.name,
+			   i.sell_price, i.current_stock
+		FROM (
+			SELECT item_id, submodel_id FROM arac.items WHERE submodel_id = ANY($1)
+			UNION
+			SELECT item_id, submodel_id FROM arac.compatibility WHERE submodel_id = ANY($1)
+		) fits
+		JOIN arac.items i ON fits.item_id = i.item_id
+		LEFT JOIN arac.categories c ON i.category_id = c.category_id
+		WHERE i.is_active = true
+		ORDER BY fits.submodel_id, i.current_stock > 0 DESC, i.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, submodelIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*vehiclemodels.VINItem
+	for rows.Next() {
+		item := &vehiclemodels.VINItem{}
+		err := rows.Scan(
+			&item.SubmodelID,
+			&item.ItemID,
+			&item.PartNumber,
+			&item.Description,
+			&item.CategoryName,
+			&item.SellPrice,
+			&item.CurrentStock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func scanVINManufacturer(row pgx.Row) (*vehiclemodels.VINManufacturer, error) {
+	manufacturer := &vehiclemodels.VINManufacturer{}
+	err := row.Scan(
+		&manufacturer.WMI,
+		&manufacturer.MakeID,
+		&manufacturer.Manufacturer,
+		&manufacturer.CreatedAt,
+		&manufacturer.UpdatedAt,
+		&manufacturer.MakeName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return manufacturer, nil
+}
@@ -1,6 +1,8 @@
 package vehicles
 
 import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles/handlers"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles/services"
@@ -19,29 +21,54 @@ func RegisterRoutes(api *echo.Group, database *db.Database) {
 	// Initialize handler
 	handler := handlers.NewVehicleHandler(service)
 
+	// Permissions
+	read := authmiddleware.RequirePermission(authmodels.PermCatalogRead)
+	write := authmiddleware.RequirePermission(authmodels.PermCatalogWrite)
+	seed := authmiddleware.RequirePermission(authmodels.PermCatalogSeed)
+	inventoryRead := authmiddleware.RequirePermission(authmodels.PermInventoryRead)
+
 	// Vehicle makes routes
 	makes := api.Group("/makes")
-	makes.GET("", handler.GetAllMakes)
-	makes.GET("/:id", handler.GetMakeByID)
-	makes.POST("", handler.CreateMake)
-	makes.PUT("/:id", handler.UpdateMake)
-	makes.DELETE("/:id", handler.DeleteMake)
-	makes.GET("/:makeId/models", handler.GetModelsByMake) // Get models for a specific make
+	makes.GET("", handler.GetAllMakes, read)
+	makes.GET("/:id", handler.GetMakeByID, read)
+	makes.POST("", handler.CreateMake, write)
+	makes.PUT("/:id", handler.UpdateMake, write)
+	makes.DELETE("/:id", handler.DeleteMake, write)
+	makes.GET("/:makeId/models", handler.GetModelsByMake, read) // Get models for a specific make
 
 	// Vehicle models routes
 	models := api.Group("/models")
-	models.GET("", handler.GetAllModels)
-	models.GET("/:id", handler.GetModelByID)
-	models.POST("", handler.CreateModel)
-	models.PUT("/:id", handler.UpdateModel)
-	models.DELETE("/:id", handler.DeleteModel)
-	models.GET("/:modelId/submodels", handler.GetSubmodelsByModel) // Get submodels for a specific model
+	models.GET("", handler.GetAllModels, read)
+	models.GET("/:id", handler.GetModelByID, read)
+	models.POST("", handler.CreateModel, write)
+	models.PUT("/:id", handler.UpdateModel, write)
+	models.DELETE("/:id", handler.DeleteModel, write)
+	models.GET("/:modelId/submodels", handler.GetSubmodelsByModel, read) // Get submodels for a specific model
+	models.GET("/:modelId/body-styles", handler.GetBodyStylesByModel, read)
 
 	// Vehicle submodels routes
 	submodels := api.Group("/submodels")
-	submodels.GET("", handler.GetAllSubmodels)
-	submodels.GET("/:id", handler.GetSubmodelByID)
-	submodels.POST("", handler.CreateSubmodel)
-	submodels.PUT("/:id", handler.UpdateSubmodel)
-	submodels.DELETE("/:id", handler.DeleteSubmodel)
+	submodels.GET("", handler.GetAllSubmodels, read)
+	submodels.GET("/:id", handler.GetSubmodelByID, read)
+	submodels.POST("", handler.CreateSubmodel, write)
+	submodels.PUT("/:id", handler.UpdateSubmodel, write)
+	submodels.DELETE("/:id", handler.DeleteSubmodel, write)
+
+	// Vehicle catalog routes
+	vehicles := api.Group("/vehicles")
+	vehicles.POST("/seed", handler.SeedVehicles, seed)
+
+	// VIN decoding, which lists the items that fit the candidate submodels
+	vehicles.GET("/vin/:vin", handler.DecodeVIN, read, inventoryRead)
+	vehicles.GET("/wmi", handler.GetVINManufacturers, read)
+	vehicles.PUT("/wmi/:wmi", handler.SaveVINManufacturer, write)
+	vehicles.DELETE("/wmi/:wmi", handler.DeleteVINManufacturer, write)
+
+	// Submodels staged from scraped listings, waiting for review
+	staging := vehicles.Group("/staging")
+	staging.GET("", handler.GetStagedSubmodels, read)
+	staging.POST("", handler.StageSubmodelListings, write)
+	staging.PUT("/:id", handler.UpdateStagedSubmodel, write)
+	staging.POST("/:id/approve", handler.ApproveStagedSubmodel, write)
+	staging.POST("/:id/reject", handler.RejectStagedSubmodel, write)
 }
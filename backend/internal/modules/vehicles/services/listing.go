@@ -0,0 +1,220 @@
+package services
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"golang.org/x/net/html"
+)
+
+// Levels of the category tree in scraped listings, taken from the cl2, cl3
+// and cl4 classes of its items
+const (
+	listingLevelMake    = 2
+	listingLevelModel   = 3
+	listingLevelVariant = 4
+)
+
+// listingEntry is one category of a scraped listing, such as
+// <li class="cl4"><a href="/audi-a1-1.4-tfsi" title="1.4 TFSI">...</a><span>(41)</span></li>
+type listingEntry struct {
+	level int
+	slug  string
+	name  string
+	count int
+}
+
+// fuelTokens maps the engine codes found in variant names to fuel types.
+// Codes are compared in lower case without hyphens.
+var fuelTokens = map[string]string{
+	"hybrid": vehiclemodels.FuelHybrid, "hibrit": vehiclemodels.FuelHybrid, "ehybrid": vehiclemodels.FuelHybrid,
+	"phev": vehiclemodels.FuelHybrid, "hev": vehiclemodels.FuelHybrid, "mhev": vehiclemodels.FuelHybrid,
+
+	"electric": vehiclemodels.FuelElectric, "elektrik": vehiclemodels.FuelElectric, "ev": vehiclemodels.FuelElectric,
+	"kwh": vehiclemodels.FuelElectric,
+
+	"lpg": vehiclemodels.FuelLPG,
+
+	"tdi": vehiclemodels.FuelDiesel, "crdi": vehiclemodels.FuelDiesel, "cdi": vehiclemodels.FuelDiesel,
+	"dci": vehiclemodels.FuelDiesel, "hdi": vehiclemodels.FuelDiesel, "bluehdi": vehiclemodels.FuelDiesel,
+	"tdci": vehiclemodels.FuelDiesel, "jtd": vehiclemodels.FuelDiesel, "jtdm": vehiclemodels.FuelDiesel,
+	"multijet": vehiclemodels.FuelDiesel, "crd": vehiclemodels.FuelDiesel, "d4d": vehiclemodels.FuelDiesel,
+	"sdi": vehiclemodels.FuelDiesel, "dti": vehiclemodels.FuelDiesel, "cdti": vehiclemodels.FuelDiesel,
+	"ddis": vehiclemodels.FuelDiesel, "idtec": vehiclemodels.FuelDiesel, "dtec": vehiclemodels.FuelDiesel,
+	"tdv6": vehiclemodels.FuelDiesel, "sdv6": vehiclemodels.FuelDiesel, "ecoblue": vehiclemodels.FuelDiesel,
+	"bluetec": vehiclemodels.FuelDiesel, "skyactivd": vehiclemodels.FuelDiesel, "dizel": vehiclemodels.FuelDiesel,
+	"diesel": vehiclemodels.FuelDiesel,
+
+	"tfsi": vehiclemodels.FuelPetrol, "tsi": vehiclemodels.FuelPetrol, "fsi": vehiclemodels.FuelPetrol,
+	"mpi": vehiclemodels.FuelPetrol, "gdi": vehiclemodels.FuelPetrol, "tgdi": vehiclemodels.FuelPetrol,
+	"vtec": vehiclemodels.FuelPetrol, "ivtec": vehiclemodels.FuelPetrol, "vti": vehiclemodels.FuelPetrol,
+	"thp": vehiclemodels.FuelPetrol, "tce": vehiclemodels.FuelPetrol, "ecoboost": vehiclemodels.FuelPetrol,
+	"tjet": vehiclemodels.FuelPetrol, "multiair": vehiclemodels.FuelPetrol, "puretech": vehiclemodels.FuelPetrol,
+	"skyactivg": vehiclemodels.FuelPetrol, "benzin": vehiclemodels.FuelPetrol, "petrol": vehiclemodels.FuelPetrol,
+	"gasoline": vehiclemodels.FuelPetrol,
+}
+
+// fuelPriority decides between codes of different fuels in one name, as in
+// "1.4 TFSI Hybrid"
+var fuelPriority = []string{
+	vehiclemodels.FuelHybrid,
+	vehiclemodels.FuelElectric,
+	vehiclemodels.FuelLPG,
+	vehiclemodels.FuelDiesel,
+	vehiclemodels.FuelPetrol,
+}
+
+var (
+	displacementPattern = regexp.MustCompile(`(?:^|\s)(\d{1,2}[.,]\d)(?:\s|$)`)
+	// BMW style model codes such as 320d and 520i
+	modelCodePattern = regexp.MustCompile(`^\d{3}([di])$`)
+	countPattern     = regexp.MustCompile(`\(([\d.]+)\)`)
+)
+
+// parseListing reads the category items of a scraped listing fragment
+func parseListing(r io.Reader) ([]*listingEntry, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*listingEntry
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "li" {
+			if entry := listingEntryOf(node); entry != nil {
+				entries = append(entries, entry)
+				return
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return entries, nil
+}
+
+func listingEntryOf(li *html.Node) *listingEntry {
+	entry := &listingEntry{}
+	for _, class := range strings.Fields(attribute(li, "class")) {
+		if level, err := strconv.Atoi(strings.TrimPrefix(class, "cl")); err == nil && strings.HasPrefix(class, "cl") {
+			entry.level = level
+		}
+	}
+	if entry.level == 0 {
+		return nil
+	}
+
+	for child := li.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		switch child.Data {
+		case "a":
+			entry.slug = strings.Trim(attribute(child, "href"), "/ ")
+			entry.name = strings.TrimSpace(attribute(child, "title"))
+			if entry.name == "" {
+				entry.name = strings.TrimSpace(textOf(child))
+			}
+		case "span":
+			if match := countPattern.FindStringSubmatch(textOf(child)); match != nil {
+				// Counts use dots for thousands, as in (5.908)
+				entry.count, _ = strconv.Atoi(strings.ReplaceAll(match[1], ".", ""))
+			}
+		}
+	}
+	if entry.slug == "" || entry.name == "" {
+		return nil
+	}
+
+	return entry
+}
+
+// inferDisplacement reads the engine size in litres from a variant name
+// such as "1.4 TFSI"
+func inferDisplacement(name string) *float64 {
+	match := displacementPattern.FindStringSubmatch(name)
+	if match == nil {
+		return nil
+	}
+	displacement, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+	if err != nil || displacement <= 0 {
+		return nil
+	}
+	return &displacement
+}
+
+// inferFuelType reads the fuel type from the engine codes in a variant name
+func inferFuelType(name string) *string {
+	found := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '/' || r == '(' || r == ')'
+	}) {
+		token = strings.ReplaceAll(token, "-", "")
+		if fuel, ok := fuelTokens[token]; ok {
+			found[fuel] = true
+		}
+		if match := modelCodePattern.FindStringSubmatch(token); match != nil {
+			if match[1] == "d" {
+				found[vehiclemodels.FuelDiesel] = true
+			} else {
+				found[vehiclemodels.FuelPetrol] = true
+			}
+		}
+	}
+
+	for _, fuel := range fuelPriority {
+		if found[fuel] {
+			fuel := fuel
+			return &fuel
+		}
+	}
+	return nil
+}
+
+// slugify turns a name into the form listing links use, e.g. "E-Tron GT"
+// into "e-tron-gt"
+func slugify(name string) string {
+	name = strings.NewReplacer(
+		"ç", "c", "Ç", "c", "ğ", "g", "Ğ", "g", "ı", "i", "İ", "i",
+		"ö", "o", "Ö", "o", "ş", "s", "Ş", "s", "ü", "u", "Ü", "u",
+	).Replace(name)
+
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(slug.String(), "-")
+}
+
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func textOf(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var text strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(textOf(child))
+	}
+	return text.String()
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+// listingFixture is cut from helper/marka.md, model.md and mm.md
+const listingFixture = `
+<div id="searchCategoryContainer" class="scroll-pane lazy-scroll"><ul>
+        <li class="cl2" data-categorybreadcrumbid="3549">
+                <a href="/audi" title="Audi"><h2 class="">Audi</h2></a>
+                <span class="">(15.431)</span>
+            </li>
+        <li class="cl3" data-categorybreadcrumbid="157656">
+                <a href="/audi-a1" title="A1"><h2 class="">A1</h2></a>
+                <span class="">(178)</span>
+            </li>
+        <li class="cl3" data-categorybreadcrumbid="23422">
+                <a href="/audi-a3" title="A3"><h2 class="">A3</h2></a>
+                <span class="">(5.908)</span>
+            </li>
+        <li class="cl4" data-categorybreadcrumbid="220434">
+                <a href="/audi-a1-1.0-tfsi" title="1.0 TFSI "><h2 class="">1.0 TFSI </h2></a>
+                <span class="">(1)</span>
+            </li>
+        <li class="cl4" data-categorybreadcrumbid="199104">
+                <a href="/audi-a1-1.4-tfsi" title="1.4 TFSI"><h2 class="">1.4 TFSI</h2></a>
+                <span class="">(41)</span>
+            </li>
+        <li class="cl4" data-categorybreadcrumbid="199892">
+                <a href="/audi-a1-1.6-tdi" title="1.6 TDI"><h2 class="">1.6 TDI</h2></a>
+                <span class="">(136)</span>
+            </li>
+        <li class="other"><a href="/not-a-category">Not a category</a></li>
+        </ul></div>`
+
+func TestParseListing(t *testing.T) {
+	entries, err := parseListing(strings.NewReader(listingFixture))
+	if err != nil {
+		t.Fatalf("parseListing: %v", err)
+	}
+
+	want := []listingEntry{
+		{level: listingLevelMake, slug: "audi", name: "Audi", count: 15431},
+		{level: listingLevelModel, slug: "audi-a1", name: "A1", count: 178},
+		{level: listingLevelModel, slug: "audi-a3", name: "A3", count: 5908},
+		{level: listingLevelVariant, slug: "audi-a1-1.0-tfsi", name: "1.0 TFSI", count: 1},
+		{level: listingLevelVariant, slug: "audi-a1-1.4-tfsi", name: "1.4 TFSI", count: 41},
+		{level: listingLevelVariant, slug: "audi-a1-1.6-tdi", name: "1.6 TDI", count: 136},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("parseListing found %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if *entry != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, *entry, want[i])
+		}
+	}
+}
+
+func TestInferDisplacement(t *testing.T) {
+	tests := []struct {
+		name string
+		want float64 // 0 when none can be read
+	}{
+		{"1.4 TFSI", 1.4},
+		{"1.0 TFSI ", 1.0},
+		{"1,6 TDI", 1.6},
+		{"2.0 TDI quattro", 2.0},
+		{"320d", 0},
+		{"e-tron", 0},
+		{"TFSI 1.4x", 0},
+	}
+
+	for _, tt := range tests {
+		got := inferDisplacement(tt.name)
+		switch {
+		case tt.want == 0 && got != nil:
+			t.Errorf("inferDisplacement(%q) = %v, want none", tt.name, *got)
+		case tt.want != 0 && got == nil:
+			t.Errorf("inferDisplacement(%q) = none, want %v", tt.name, tt.want)
+		case tt.want != 0 && *got != tt.want:
+			t.Errorf("inferDisplacement(%q) = %v, want %v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestInferFuelType(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // empty when none can be read
+	}{
+		{"1.4 TFSI", vehiclemodels.FuelPetrol},
+		{"1.6 TDI", vehiclemodels.FuelDiesel},
+		{"1.6 CRDi", vehiclemodels.FuelDiesel},
+		{"1.5 dCi", vehiclemodels.FuelDiesel},
+		{"1.3 Multijet", vehiclemodels.FuelDiesel},
+		{"1.6 D-4D", vehiclemodels.FuelDiesel},
+		// BMW model codes
+		{"320d", vehiclemodels.FuelDiesel},
+		{"520i", vehiclemodels.FuelPetrol},
+		{"320d xDrive", vehiclemodels.FuelDiesel},
+		// Hybrid wins over the engine code beside it
+		{"1.4 TFSI Hybrid", vehiclemodels.FuelHybrid},
+		{"1.4 TSI e-Hybrid", vehiclemodels.FuelHybrid},
+		{"1.4 LPG", vehiclemodels.FuelLPG},
+		{"A1", ""},
+		{"1.4", ""},
+		{"3200", ""},
+	}
+
+	for _, tt := range tests {
+		got := inferFuelType(tt.name)
+		switch {
+		case tt.want == "" && got != nil:
+			t.Errorf("inferFuelType(%q) = %q, want none", tt.name, *got)
+		case tt.want != "" && got == nil:
+			t.Errorf("inferFuelType(%q) = none, want %q", tt.name, tt.want)
+		case tt.want != "" && *got != tt.want:
+			t.Errorf("inferFuelType(%q) = %q, want %q", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"E-Tron GT", "e-tron-gt"},
+		{"1.4 TFSI", "1.4-tfsi"},
+		{"Şahin Doğan", "sahin-dogan"},
+		{"  A1 ", "a1"},
+	}
+
+	for _, tt := range tests {
+		if got := slugify(tt.name); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+)
+
+var (
+	ErrEmptySeedFile     = errors.New("vehicle catalog has no header row")
+	ErrMissingSeedColumn = errors.New("vehicle catalog must have Make and Model Name columns")
+)
+
+// Column headers of the vehicle catalog (Vehicle.csv)
+const (
+	seedColumnMake        = "make"
+	seedColumnModel       = "model name"
+	seedColumnBodyStyle   = "body style"
+	seedColumnVehicleType = "vehicle type"
+)
+
+// vehicleSeeder remembers what it has looked up or created, so every make,
+// model and body style is only looked up once per file
+type vehicleSeeder struct {
+	repo       repositories.VehicleRepository
+	result     *vehiclemodels.VehicleSeedResult
+	makes      map[string]*vehiclemodels.Make
+	models     map[string]*vehiclemodels.Model
+	bodyStyles map[string]*vehiclemodels.BodyStyle
+	// seen maps make, model and body style to the first row listing them
+	seen map[string]*vehiclemodels.VehicleSeedRow
+}
+
+// SeedVehicles loads makes, models and body styles from the vehicle catalog.
+// Body styles are kept with their model and do not become submodels, since
+// the catalog has no years or engines for them. It can be run again safely:
+// records that already exist, in any case, are reused and never changed.
+// The vehicle type is stored with a new body style and used to spot rows
+// that contradict each other.
+func (s *vehicleService) SeedVehicles(ctx context.Context, file io.Reader) (*vehiclemodels.VehicleSeedResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptySeedFile
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns[seedColumnMake]; !ok {
+		return nil, ErrMissingSeedColumn
+	}
+	if _, ok := columns[seedColumnModel]; !ok {
+		return nil, ErrMissingSeedColumn
+	}
+
+	seeder := &vehicleSeeder{
+		repo:       s.repo,
+		result:     &vehiclemodels.VehicleSeedResult{Rows: []*vehiclemodels.VehicleSeedRow{}},
+		makes:      make(map[string]*vehiclemodels.Make),
+		models:     make(map[string]*vehiclemodels.Model),
+		bodyStyles: make(map[string]*vehiclemodels.BodyStyle),
+		seen:       make(map[string]*vehiclemodels.VehicleSeedRow),
+	}
+
+	for i, record := range records[1:] {
+		row := &vehiclemodels.VehicleSeedRow{
+			Row:         i + 2, // 1-based, after the header
+			Make:        seedField(record, columns, seedColumnMake),
+			Model:       seedField(record, columns, seedColumnModel),
+			BodyStyle:   seedField(record, columns, seedColumnBodyStyle),
+			VehicleType: seedField(record, columns, seedColumnVehicleType),
+		}
+		if err := seeder.seedRow(ctx, row); err != nil {
+			return nil, fmt.Errorf("row %d: %w", row.Row, err)
+		}
+	}
+
+	return seeder.result, nil
+}
+
+// Helper functions
+func (s *vehicleSeeder) seedRow(ctx context.Context, row *vehiclemodels.VehicleSeedRow) error {
+	if row.Make == "" && row.Model == "" && row.BodyStyle == "" && row.VehicleType == "" {
+		return nil
+	}
+	if row.Make == "" || row.Model == "" {
+		s.report(row, vehiclemodels.SeedStatusSkipped, "make and model name are required")
+		return nil
+	}
+
+	key := seedKey(row.Make, row.Model, row.BodyStyle)
+	if first, ok := s.seen[key]; ok {
+		if !strings.EqualFold(first.VehicleType, row.VehicleType) {
+			s.report(row, vehiclemodels.SeedStatusConflicting,
+				fmt.Sprintf("row %d lists this vehicle as %q", first.Row, first.VehicleType))
+		} else {
+			s.report(row, vehiclemodels.SeedStatusSkipped, fmt.Sprintf("duplicate of row %d", first.Row))
+		}
+		return nil
+	}
+	s.seen[key] = row
+
+	created := false
+	var differences []string
+
+	vehicleMake, makeCreated, err := s.make(ctx, row.Make)
+	if err != nil {
+		return err
+	}
+	created = created || makeCreated
+	if vehicleMake.MakeName != row.Make {
+		differences = append(differences, fmt.Sprintf("make is stored as %q", vehicleMake.MakeName))
+	}
+
+	model, modelCreated, err := s.model(ctx, vehicleMake, row.Model)
+	if err != nil {
+		return err
+	}
+	created = created || modelCreated
+	if model.ModelName != row.Model {
+		differences = append(differences, fmt.Sprintf("model is stored as %q", model.ModelName))
+	}
+
+	if row.BodyStyle != "" {
+		bodyStyle, bodyStyleCreated, err := s.bodyStyle(ctx, model, row)
+		if err != nil {
+			return err
+		}
+		created = created || bodyStyleCreated
+		if bodyStyle.BodyStyle != row.BodyStyle {
+			differences = append(differences, fmt.Sprintf("body style is stored as %q", bodyStyle.BodyStyle))
+		}
+	}
+
+	switch {
+	case created:
+		s.result.Inserted++
+	case len(differences) > 0:
+		s.report(row, vehiclemodels.SeedStatusConflicting, strings.Join(differences, ", "))
+	default:
+		s.report(row, vehiclemodels.SeedStatusSkipped, "already exists")
+	}
+	return nil
+}
+
+func (s *vehicleSeeder) make(ctx context.Context, name string) (*vehiclemodels.Make, bool, error) {
+	key := strings.ToLower(name)
+	if vehicleMake, ok := s.makes[key]; ok {
+		return vehicleMake, false, nil
+	}
+
+	vehicleMake, created, err := findOrCreateMake(ctx, s.repo, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		s.result.MakesCreated++
+	}
+
+	s.makes[key] = vehicleMake
+	return vehicleMake, created, nil
+}
+
+func (s *vehicleSeeder) model(ctx context.Context, vehicleMake *vehiclemodels.Make, name string) (*vehiclemodels.Model, bool, error) {
+	key := fmt.Sprintf("%d|%s", vehicleMake.MakeID, strings.ToLower(name))
+	if model, ok := s.models[key]; ok {
+		return model, false, nil
+	}
+
+	model, created, err := findOrCreateModel(ctx, s.repo, vehicleMake, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		s.result.ModelsCreated++
+	}
+
+	s.models[key] = model
+	return model, created, nil
+}
+
+func (s *vehicleSeeder) bodyStyle(ctx context.Context, model *vehiclemodels.Model, row *vehiclemodels.VehicleSeedRow) (*vehiclemodels.BodyStyle, bool, error) {
+	key := fmt.Sprintf("%d|%s", model.ModelID, strings.ToLower(row.BodyStyle))
+	if bodyStyle, ok := s.bodyStyles[key]; ok {
+		return bodyStyle, false, nil
+	}
+
+	bodyStyle, err := s.repo.GetBodyStyleByName(ctx, model.ModelID, row.BodyStyle)
+	if err != nil {
+		return nil, false, err
+	}
+	created := false
+	if bodyStyle == nil {
+		bodyStyle = &vehiclemodels.BodyStyle{
+			ModelID:   model.ModelID,
+			BodyStyle: row.BodyStyle,
+		}
+		if row.VehicleType != "" {
+			vehicleType := row.VehicleType
+			bodyStyle.VehicleType = &vehicleType
+		}
+		if bodyStyle.BodyStyleID, err = s.repo.CreateBodyStyle(ctx, bodyStyle); err != nil {
+			return nil, false, err
+		}
+		s.result.BodyStylesCreated++
+		created = true
+	}
+
+	s.bodyStyles[key] = bodyStyle
+	return bodyStyle, created, nil
+}
+
+func (s *vehicleSeeder) report(row *vehiclemodels.VehicleSeedRow, status, reason string) {
+	switch status {
+	case vehiclemodels.SeedStatusSkipped:
+		s.result.Skipped++
+	case vehiclemodels.SeedStatusConflicting:
+		s.result.Conflicting++
+	}
+
+	reported := *row
+	reported.Status = status
+	reported.Reason = reason
+	s.result.Rows = append(s.result.Rows, &reported)
+}
+
+// findOrCreateMake returns the make with a name, ignoring case, creating it
+// if there is none
+func findOrCreateMake(ctx context.Context, repo repositories.VehicleRepository, name string) (*vehiclemodels.Make, bool, error) {
+	vehicleMake, err := repo.GetMakeByName(ctx, name)
+	if err != nil || vehicleMake != nil {
+		return vehicleMake, false, err
+	}
+
+	vehicleMake = &vehiclemodels.Make{MakeName: name}
+	if vehicleMake.MakeID, err = repo.CreateMake(ctx, vehicleMake); err != nil {
+		return nil, false, err
+	}
+	return vehicleMake, true, nil
+}
+
+// findOrCreateModel returns the model of a make with a name, ignoring case,
+// creating it if there is none
+func findOrCreateModel(ctx context.Context, repo repositories.VehicleRepository, vehicleMake *vehiclemodels.Make, name string) (*vehiclemodels.Model, bool, error) {
+	model, err := repo.GetModelByName(ctx, vehicleMake.MakeID, name)
+	if err != nil || model != nil {
+		return model, false, err
+	}
+
+	model = &vehiclemodels.Model{MakeID: vehicleMake.MakeID, ModelName: name, MakeName: vehicleMake.MakeName}
+	if model.ModelID, err = repo.CreateModel(ctx, model); err != nil {
+		return nil, false, err
+	}
+	return model, true, nil
+}
+
+func seedField(record []string, columns map[string]int, name string) string {
+	index, ok := columns[name]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func seedKey(parts ...string) string {
+	return strings.ToLower(strings.Join(parts, "|"))
+}
@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"errors"
+	"io"
 
 	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
 )
 
 var (
@@ -16,6 +18,9 @@ var (
 	ErrInvalidModelID   = errors.New("invalid model ID")
 )
 
+// SubmodelSorting lists the fields submodels can be sorted by
+var SubmodelSorting = repositories.SubmodelSorting
+
 type VehicleService interface {
 	// Make operations
 	GetAllMakes(ctx context.Context) ([]*vehiclemodels.Make, error)
@@ -31,14 +36,31 @@ type VehicleService interface {
 	CreateModel(ctx context.Context, model *vehiclemodels.Model) (int, error)
 	UpdateModel(ctx context.Context, model *vehiclemodels.Model) error
 	DeleteModel(ctx context.Context, id int) error
+	GetBodyStylesByModel(ctx context.Context, modelID int) ([]*vehiclemodels.BodyStyle, error)
 
 	// Submodel operations
-	GetAllSubmodels(ctx context.Context) ([]*vehiclemodels.Submodel, error)
+	GetAllSubmodels(ctx context.Context, page *pagination.Page) ([]*vehiclemodels.Submodel, error)
 	GetSubmodelsByModel(ctx context.Context, modelID int) ([]*vehiclemodels.Submodel, error)
 	GetSubmodelByID(ctx context.Context, id int) (*vehiclemodels.Submodel, error)
 	CreateSubmodel(ctx context.Context, submodel *vehiclemodels.Submodel) (int, error)
 	UpdateSubmodel(ctx context.Context, submodel *vehiclemodels.Submodel) error
 	DeleteSubmodel(ctx context.Context, id int) error
+
+	// Catalog operations
+	SeedVehicles(ctx context.Context, file io.Reader) (*vehiclemodels.VehicleSeedResult, error)
+
+	// Staged submodel operations
+	StageSubmodelListings(ctx context.Context, files []io.Reader) (*vehiclemodels.SubmodelStagingResult, error)
+	GetStagedSubmodels(ctx context.Context, status string) ([]*vehiclemodels.StagedSubmodel, error)
+	UpdateStagedSubmodel(ctx context.Context, submodel *vehiclemodels.StagedSubmodel) error
+	ApproveStagedSubmodel(ctx context.Context, id int) (*vehiclemodels.Submodel, error)
+	RejectStagedSubmodel(ctx context.Context, id int) error
+
+	// Vehicle identification operations
+	DecodeVIN(ctx context.Context, vin string) (*vehiclemodels.DecodedVIN, error)
+	GetVINManufacturers(ctx context.Context) ([]*vehiclemodels.VINManufacturer, error)
+	SaveVINManufacturer(ctx context.Context, manufacturer *vehiclemodels.VINManufacturer) error
+	DeleteVINManufacturer(ctx context.Context, wmi string) error
 }
 
 type vehicleService struct {
@@ -242,8 +264,26 @@ func (s *vehicleService) DeleteModel(ctx context.Context, id int) error {
 }
 
 // Submodel operations
-func (s *vehicleService) GetAllSubmodels(ctx context.Context) ([]*vehiclemodels.Submodel, error) {
-	return s.repo.GetAllSubmodels(ctx)
+func (s *vehicleService) GetAllSubmodels(ctx context.Context, page *pagination.Page) ([]*vehiclemodels.Submodel, error) {
+	return s.repo.GetAllSubmodels(ctx, page)
+}
+
+// GetBodyStylesByModel lists the body styles the vehicle catalog gives a
+// model
+func (s *vehicleService) GetBodyStylesByModel(ctx context.Context, modelID int) ([]*vehiclemodels.BodyStyle, error) {
+	if modelID <= 0 {
+		return nil, ErrInvalidModelID
+	}
+
+	model, err := s.repo.GetModelByID(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if model == nil {
+		return nil, ErrModelNotFound
+	}
+
+	return s.repo.GetBodyStylesByModel(ctx, modelID)
 }
 
 func (s *vehicleService) GetSubmodelsByModel(ctx context.Context, modelID int) ([]*vehiclemodels.Submodel, error) {
@@ -377,7 +417,7 @@ func (s *vehicleService) validateSubmodel(submodel *vehiclemodels.Submodel) erro
 	if submodel.FuelType == nil || *submodel.FuelType == "" {
 		return errors.New("fuel type is required")
 	}
-	if submodel.FuelType == nil || *submodel.TransmissionType == "" {
+	if submodel.TransmissionType == nil || *submodel.TransmissionType == "" {
 		return errors.New("transmission type is required")
 	}
 	if submodel.BodyType == nil || *submodel.BodyType == "" {
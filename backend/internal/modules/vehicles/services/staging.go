@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+var (
+	ErrNoListings             = errors.New("files contain no listing categories")
+	ErrStagedSubmodelNotFound = errors.New("staged submodel not found")
+	ErrStagedSubmodelReviewed = errors.New("staged submodel has already been reviewed")
+	ErrInvalidStagingStatus   = errors.New("status must be pending, approved or rejected")
+	ErrIncompleteSubmodel     = errors.New("staged submodel is incomplete")
+	ErrDuplicateSubmodel      = errors.New("submodel already exists")
+)
+
+// listingModel is a model a variant link can belong to
+type listingModel struct {
+	makeName  string
+	modelName string
+}
+
+// StageSubmodelListings reads the engine variants from scraped listing
+// fragments and queues them for review. Variants are matched to their make
+// and model by link, so "/audi-a1-1.4-tfsi" belongs to the model linked as
+// "/audi-a1". Makes and models come from the fragments and the catalog, and
+// catalog names win so approved variants land on existing records.
+func (s *vehicleService) StageSubmodelListings(ctx context.Context, files []io.Reader) (*vehiclemodels.SubmodelStagingResult, error) {
+	var entries []*listingEntry
+	for _, file := range files {
+		parsed, err := parseListing(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parsed...)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoListings
+	}
+
+	makes := make(map[string]string)
+	models := make(map[string]*listingModel)
+
+	catalogMakes, err := s.repo.GetAllMakes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, vehicleMake := range catalogMakes {
+		makes[slugify(vehicleMake.MakeName)] = vehicleMake.MakeName
+	}
+	catalogModels, err := s.repo.GetAllModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range catalogModels {
+		models[slugify(model.MakeName)+"-"+slugify(model.ModelName)] = &listingModel{model.MakeName, model.ModelName}
+	}
+
+	for _, entry := range entries {
+		if _, ok := makes[entry.slug]; !ok && entry.level == listingLevelMake {
+			makes[entry.slug] = entry.name
+		}
+	}
+	for _, entry := range entries {
+		if _, ok := models[entry.slug]; ok || entry.level != listingLevelModel {
+			continue
+		}
+		if makeSlug := longestSlugPrefix(entry.slug, makes); makeSlug != "" {
+			models[entry.slug] = &listingModel{makes[makeSlug], entry.name}
+		}
+	}
+
+	result := &vehiclemodels.SubmodelStagingResult{Unresolved: []string{}}
+	var staged []*vehiclemodels.StagedSubmodel
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.level != listingLevelVariant || seen[entry.slug] {
+			continue
+		}
+		seen[entry.slug] = true
+
+		model := models[longestSlugPrefix(entry.slug, models)]
+		if model == nil {
+			result.Unresolved = append(result.Unresolved, "/"+entry.slug)
+			continue
+		}
+
+		staged = append(staged, &vehiclemodels.StagedSubmodel{
+			SourceSlug:         entry.slug,
+			MakeName:           model.makeName,
+			ModelName:          model.modelName,
+			SubmodelName:       entry.name,
+			EngineType:         entry.name,
+			EngineDisplacement: inferDisplacement(entry.name),
+			FuelType:           inferFuelType(entry.name),
+			ListingCount:       entry.count,
+		})
+	}
+
+	if len(staged) > 0 {
+		created, err := s.repo.StageSubmodels(ctx, staged)
+		if err != nil {
+			return nil, err
+		}
+		result.Staged = created
+		result.Updated = len(staged) - created
+	}
+
+	return result, nil
+}
+
+func (s *vehicleService) GetStagedSubmodels(ctx context.Context, status string) ([]*vehiclemodels.StagedSubmodel, error) {
+	switch status {
+	case "", vehiclemodels.StagingStatusPending, vehiclemodels.StagingStatusApproved, vehiclemodels.StagingStatusRejected:
+	default:
+		return nil, ErrInvalidStagingStatus
+	}
+	return s.repo.GetStagedSubmodels(ctx, status)
+}
+
+// UpdateStagedSubmodel saves the values a reviewer filled in or corrected.
+// Only pending variants can be edited.
+func (s *vehicleService) UpdateStagedSubmodel(ctx context.Context, submodel *vehiclemodels.StagedSubmodel) error {
+	existing, err := s.pendingStagedSubmodel(ctx, submodel.StagedID)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(submodel.MakeName) == "" || strings.TrimSpace(submodel.ModelName) == "" {
+		return fmt.Errorf("%w: make and model name are required", ErrIncompleteSubmodel)
+	}
+	if strings.TrimSpace(submodel.SubmodelName) == "" {
+		return fmt.Errorf("%w: submodel name is required", ErrIncompleteSubmodel)
+	}
+
+	submodel.SourceSlug = existing.SourceSlug
+	submodel.ListingCount = existing.ListingCount
+	submodel.Status = existing.Status
+	submodel.SubmodelID = existing.SubmodelID
+	submodel.CreatedAt = existing.CreatedAt
+
+	return s.repo.UpdateStagedSubmodel(ctx, submodel)
+}
+
+// ApproveStagedSubmodel creates the submodel a staged variant describes,
+// with its make and model if they do not exist yet. It must pass the same
+// checks as a submodel entered by hand, so reviewers fill in what the
+// listing did not have, such as years and body type, first.
+func (s *vehicleService) ApproveStagedSubmodel(ctx context.Context, id int) (*vehiclemodels.Submodel, error) {
+	staged, err := s.pendingStagedSubmodel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	submodel := &vehiclemodels.Submodel{
+		SubmodelName:       staged.SubmodelName,
+		YearTo:             staged.YearTo,
+		EngineType:         staged.EngineType,
+		EngineDisplacement: staged.EngineDisplacement,
+		FuelType:           staged.FuelType,
+		TransmissionType:   staged.TransmissionType,
+		BodyType:           staged.BodyType,
+	}
+	if staged.YearFrom != nil {
+		submodel.YearFrom = *staged.YearFrom
+	}
+	if err := s.validateSubmodel(submodel); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompleteSubmodel, err)
+	}
+	if submodel.YearTo != nil && *submodel.YearTo < submodel.YearFrom {
+		return nil, fmt.Errorf("%w: end year cannot be earlier than start year", ErrIncompleteSubmodel)
+	}
+
+	vehicleMake, _, err := findOrCreateMake(ctx, s.repo, staged.MakeName)
+	if err != nil {
+		return nil, err
+	}
+	model, _, err := findOrCreateModel(ctx, s.repo, vehicleMake, staged.ModelName)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetSubmodelsByModel(ctx, model.ModelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if strings.EqualFold(other.SubmodelName, submodel.SubmodelName) && other.YearFrom == submodel.YearFrom {
+			return nil, ErrDuplicateSubmodel
+		}
+	}
+
+	submodel.ModelID = model.ModelID
+	submodel.SubmodelID, err = s.CreateSubmodel(ctx, submodel)
+	if err != nil {
+		return nil, err
+	}
+
+	staged.Status = vehiclemodels.StagingStatusApproved
+	staged.SubmodelID = &submodel.SubmodelID
+	if err := s.repo.UpdateStagedSubmodel(ctx, staged); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSubmodelByID(ctx, submodel.SubmodelID)
+}
+
+// RejectStagedSubmodel keeps a staged variant out of the catalog. It stays
+// staged so the same variant is not queued again.
+func (s *vehicleService) RejectStagedSubmodel(ctx context.Context, id int) error {
+	staged, err := s.pendingStagedSubmodel(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	staged.Status = vehiclemodels.StagingStatusRejected
+	return s.repo.UpdateStagedSubmodel(ctx, staged)
+}
+
+// Helper functions
+func (s *vehicleService) pendingStagedSubmodel(ctx context.Context, id int) (*vehiclemodels.StagedSubmodel, error) {
+	staged, err := s.repo.GetStagedSubmodelByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if staged == nil {
+		return nil, ErrStagedSubmodelNotFound
+	}
+	if staged.Status != vehiclemodels.StagingStatusPending {
+		return nil, ErrStagedSubmodelReviewed
+	}
+	return staged, nil
+}
+
+// longestSlugPrefix finds the longest link in links that slug extends, as
+// "audi-a1" is extended by "audi-a1-1.4-tfsi"
+func longestSlugPrefix[T any](slug string, links map[string]T) string {
+	longest := ""
+	for link := range links {
+		if len(link) > len(longest) && strings.HasPrefix(slug, link+"-") {
+			longest = link
+		}
+	}
+	return longest
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+var (
+	ErrInvalidVIN = errors.New("VIN must be 17 letters and digits, without I, O or Q")
+	ErrInvalidWMI = errors.New("WMI must be 3 letters and digits, without I, O or Q")
+
+	ErrVINManufacturerNotFound = errors.New("VIN manufacturer not found")
+)
+
+// vinYearCodes are the model year codes of the tenth VIN character, in
+// order from 1980. They repeat every 30 years.
+const vinYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// vinTransliteration gives the value of every VIN character for the check
+// digit
+var vinTransliteration = map[rune]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+// vinWeights are the check digit weights of the VIN positions
+var vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+// Vehicle identification operations
+
+// DecodeVIN reads the manufacturer, region and model year from a VIN and
+// lists the submodels of its make, with the items that fit them. The model
+// year only narrows the candidates when it is reliable; otherwise every
+// submodel of the make is listed, nearest to the model year first. A VIN
+// whose manufacturer is not known is still decoded, without candidates.
+func (s *vehicleService) DecodeVIN(ctx context.Context, vin string) (*vehiclemodels.DecodedVIN, error) {
+	decoded, err := decodeVIN(vin, time.Now().Year())
+	if err != nil {
+		return nil, err
+	}
+	decoded.Candidates = []*vehiclemodels.VINCandidate{}
+
+	decoded.Make, err = s.vinMake(ctx, decoded)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Make == nil {
+		return decoded, nil
+	}
+
+	var year *int
+	if decoded.ModelYearReliable {
+		year = decoded.ModelYear
+	}
+	submodels, err := s.repo.GetSubmodelsByMakeAndYear(ctx, decoded.Make.MakeID, year)
+	if err != nil {
+		return nil, err
+	}
+	if len(submodels) == 0 && year != nil {
+		// The catalog may not have the years right, so fall back to all
+		submodels, err = s.repo.GetSubmodelsByMakeAndYear(ctx, decoded.Make.MakeID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(submodels) == 0 {
+		return decoded, nil
+	}
+	rankByModelYear(submodels, decoded.ModelYear)
+
+	ids := make([]int, len(submodels))
+	candidates := map[int]*vehiclemodels.VINCandidate{}
+	for i, submodel := range submodels {
+		ids[i] = submodel.SubmodelID
+		candidate := &vehiclemodels.VINCandidate{Submodel: submodel, Items: []*vehiclemodels.VINItem{}}
+		candidates[submodel.SubmodelID] = candidate
+		decoded.Candidates = append(decoded.Candidates, candidate)
+	}
+
+	items, err := s.repo.GetItemsForSubmodels(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		candidate := candidates[item.SubmodelID]
+		candidate.Items = append(candidate.Items, item)
+	}
+
+	return decoded, nil
+}
+
+func (s *vehicleService) GetVINManufacturers(ctx context.Context) ([]*vehiclemodels.VINManufacturer, error) {
+	return s.repo.GetVINManufacturers(ctx)
+}
+
+// SaveVINManufacturer adds a manufacturer identifier or points it at
+// another make
+func (s *vehicleService) SaveVINManufacturer(ctx context.Context, manufacturer *vehiclemodels.VINManufacturer) error {
+	manufacturer.WMI = strings.ToUpper(strings.TrimSpace(manufacturer.WMI))
+	if len(manufacturer.WMI) != 3 || !validVINCharacters(manufacturer.WMI) {
+		return ErrInvalidWMI
+	}
+	if manufacturer.MakeID <= 0 {
+		return ErrInvalidMakeID
+	}
+
+	existing, err := s.repo.GetMakeByID(ctx, manufacturer.MakeID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrMakeNotFound
+	}
+	manufacturer.MakeName = existing.MakeName
+
+	return s.repo.SaveVINManufacturer(ctx, manufacturer)
+}
+
+func (s *vehicleService) DeleteVINManufacturer(ctx context.Context, wmi string) error {
+	wmi = strings.ToUpper(strings.TrimSpace(wmi))
+
+	existing, err := s.repo.GetVINManufacturer(ctx, wmi)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrVINManufacturerNotFound
+	}
+
+	return s.repo.DeleteVINManufacturer(ctx, wmi)
+}
+
+// Helper functions
+
+// vinMake finds the make of a VIN, first in the local table of manufacturer
+// identifiers, then among the known ones by make name
+func (s *vehicleService) vinMake(ctx context.Context, decoded *vehiclemodels.DecodedVIN) (*vehiclemodels.Make, error) {
+	manufacturer, err := s.repo.GetVINManufacturer(ctx, decoded.WMI)
+	if err != nil {
+		return nil, err
+	}
+	if manufacturer != nil {
+		decoded.Manufacturer = manufacturer.Manufacturer
+		return s.repo.GetMakeByID(ctx, manufacturer.MakeID)
+	}
+
+	known, ok := knownManufacturers[decoded.WMI]
+	if !ok {
+		return nil, nil
+	}
+	decoded.Manufacturer = &known.name
+
+	for _, name := range known.makes {
+		vehicleMake, err := s.repo.GetMakeByName(ctx, name)
+		if err != nil || vehicleMake != nil {
+			return vehicleMake, err
+		}
+	}
+	return nil, nil
+}
+
+// decodeVIN splits a VIN into its sections and reads what they tell without
+// looking anything up. Of the two years a model year code can stand for, the
+// later one that is not after next year is taken.
+func decodeVIN(vin string, currentYear int) (*vehiclemodels.DecodedVIN, error) {
+	vin = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(vin))
+	if len(vin) != 17 || !validVINCharacters(vin) {
+		return nil, ErrInvalidVIN
+	}
+
+	decoded := &vehiclemodels.DecodedVIN{
+		VIN:             vin,
+		WMI:             vin[:3],
+		VDS:             vin[3:9],
+		VIS:             vin[9:],
+		Region:          vinRegion(vin[0]),
+		CheckDigitValid: vinCheckDigit(vin) == vin[8],
+	}
+
+	if index := strings.IndexByte(vinYearCodes, vin[9]); index >= 0 {
+		year := 1980 + index
+		for year+30 <= currentYear+1 {
+			year += 30
+		}
+		decoded.ModelYear = &year
+		// European makers need neither a check digit nor a model year
+		decoded.ModelYearReliable = decoded.CheckDigitValid && decoded.Region != "Europe"
+	}
+
+	return decoded, nil
+}
+
+// validVINCharacters reports whether text only has the letters and digits a
+// VIN may have. I, O and Q are left out so they are not read as 1 and 0.
+func validVINCharacters(text string) bool {
+	for _, r := range text {
+		if !(r >= '0' && r <= '9' || r >= 'A' && r <= 'Z') || r == 'I' || r == 'O' || r == 'Q' {
+			return false
+		}
+	}
+	return true
+}
+
+// vinCheckDigit computes the ninth character of a VIN from the others
+func vinCheckDigit(vin string) byte {
+	sum := 0
+	for i, r := range vin {
+		value, ok := vinTransliteration[r]
+		if !ok {
+			value = int(r - '0')
+		}
+		sum += value * vinWeights[i]
+	}
+
+	remainder := sum % 11
+	if remainder == 10 {
+		return 'X'
+	}
+	return byte('0' + remainder)
+}
+
+// vinRegion names the part of the world the first VIN character stands for
+func vinRegion(code byte) string {
+	switch {
+	case code >= 'A' && code <= 'H':
+		return "Africa"
+	case code >= 'J' && code <= 'R':
+		return "Asia"
+	case code >= 'S' && code <= 'Z':
+		return "Europe"
+	case code >= '1' && code <= '5':
+		return "North America"
+	case code == '6' || code == '7':
+		return "Oceania"
+	default:
+		return "South America"
+	}
+}
+
+// rankByModelYear orders submodels by how many years they are from a model
+// year, keeping their order otherwise. Submodels whose years are not known
+// go last.
+func rankByModelYear(submodels []*vehiclemodels.Submodel, year *int) {
+	if year == nil {
+		return
+	}
+	sort.SliceStable(submodels, func(i, j int) bool {
+		return modelYearDistance(submodels[i], *year) < modelYearDistance(submodels[j], *year)
+	})
+}
+
+// modelYearDistance is how many years a submodel was built before or after
+// a year, 0 when it was built in it
+func modelYearDistance(submodel *vehiclemodels.Submodel, year int) int {
+	switch {
+	case submodel.YearFrom <= 0:
+		return math.MaxInt
+	case year < submodel.YearFrom:
+		return submodel.YearFrom - year
+	case submodel.YearTo != nil && year > *submodel.YearTo:
+		return year - *submodel.YearTo
+	default:
+		return 0
+	}
+}
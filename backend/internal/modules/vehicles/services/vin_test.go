@@ -0,0 +1,260 @@
+package services
+
+import (
+	"testing"
+
+	vehiclemodels "github.com/hsrvms/autoparts/internal/modules/vehicles/models"
+)
+
+func TestDecodeVIN(t *testing.T) {
+	tests := []struct {
+		name            string
+		vin             string
+		wantVIN         string
+		wantRegion      string
+		wantYear        int // 0 when the year code is not one
+		wantCheckDigit  bool
+		wantYearTrusted bool
+	}{
+		{
+			name:            "North American with X check digit",
+			vin:             "1M8GDM9AXKP042788",
+			wantVIN:         "1M8GDM9AXKP042788",
+			wantRegion:      "North America",
+			wantYear:        2019,
+			wantCheckDigit:  true,
+			wantYearTrusted: true,
+		},
+		{
+			name:            "North American with digit year code",
+			vin:             "1HGCM82633A004352",
+			wantVIN:         "1HGCM82633A004352",
+			wantRegion:      "North America",
+			wantYear:        2003,
+			wantCheckDigit:  true,
+			wantYearTrusted: true,
+		},
+		{
+			name:            "lower case with spaces and dashes",
+			vin:             "1hgcm826-33a 004352",
+			wantVIN:         "1HGCM82633A004352",
+			wantRegion:      "North America",
+			wantYear:        2003,
+			wantCheckDigit:  true,
+			wantYearTrusted: true,
+		},
+		{
+			name:            "wrong check digit",
+			vin:             "1HGCM82643A004352",
+			wantVIN:         "1HGCM82643A004352",
+			wantRegion:      "North America",
+			wantYear:        2003,
+			wantCheckDigit:  false,
+			wantYearTrusted: false,
+		},
+		{
+			name:            "European with check digit is still not trusted",
+			vin:             "WDD2050421F123456",
+			wantVIN:         "WDD2050421F123456",
+			wantRegion:      "Europe",
+			wantYear:        2001,
+			wantCheckDigit:  true,
+			wantYearTrusted: false,
+		},
+		{
+			name:            "European filler instead of a check digit",
+			vin:             "WVWZZZ1JZXW000001",
+			wantVIN:         "WVWZZZ1JZXW000001",
+			wantRegion:      "Europe",
+			wantYear:        1999,
+			wantCheckDigit:  false,
+			wantYearTrusted: false,
+		},
+		{
+			name:            "Asian",
+			vin:             "JTDKB20U233123456",
+			wantVIN:         "JTDKB20U233123456",
+			wantRegion:      "Asia",
+			wantYear:        2003,
+			wantCheckDigit:  false,
+			wantYearTrusted: false,
+		},
+		{
+			name:           "Z is not a year code",
+			vin:            "WVWZZZ1JZZW000001",
+			wantVIN:        "WVWZZZ1JZZW000001",
+			wantRegion:     "Europe",
+			wantCheckDigit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeVIN(tt.vin, 2026)
+			if err != nil {
+				t.Fatalf("decodeVIN(%q): %v", tt.vin, err)
+			}
+			if decoded.VIN != tt.wantVIN {
+				t.Errorf("VIN = %q, want %q", decoded.VIN, tt.wantVIN)
+			}
+			if decoded.WMI != tt.wantVIN[:3] || decoded.VDS != tt.wantVIN[3:9] || decoded.VIS != tt.wantVIN[9:] {
+				t.Errorf("sections = %q %q %q, want %q %q %q",
+					decoded.WMI, decoded.VDS, decoded.VIS, tt.wantVIN[:3], tt.wantVIN[3:9], tt.wantVIN[9:])
+			}
+			if decoded.Region != tt.wantRegion {
+				t.Errorf("Region = %q, want %q", decoded.Region, tt.wantRegion)
+			}
+			if decoded.CheckDigitValid != tt.wantCheckDigit {
+				t.Errorf("CheckDigitValid = %v, want %v", decoded.CheckDigitValid, tt.wantCheckDigit)
+			}
+			if decoded.ModelYearReliable != tt.wantYearTrusted {
+				t.Errorf("ModelYearReliable = %v, want %v", decoded.ModelYearReliable, tt.wantYearTrusted)
+			}
+
+			switch {
+			case tt.wantYear == 0 && decoded.ModelYear != nil:
+				t.Errorf("ModelYear = %d, want none", *decoded.ModelYear)
+			case tt.wantYear != 0 && decoded.ModelYear == nil:
+				t.Errorf("ModelYear = none, want %d", tt.wantYear)
+			case tt.wantYear != 0 && *decoded.ModelYear != tt.wantYear:
+				t.Errorf("ModelYear = %d, want %d", *decoded.ModelYear, tt.wantYear)
+			}
+		})
+	}
+}
+
+func TestDecodeVINRejects(t *testing.T) {
+	tests := []string{
+		"",
+		"1HGCM82633A00435",   // 16 characters
+		"1HGCM82633A0043521", // 18 characters
+		"1HGCM82633A00435I",  // I looks like 1
+		"1HGCM82633A00435O",  // O looks like 0
+		"QHGCM82633A004352",  // Q looks like 0
+		"1HGCM82633A00435_",
+	}
+
+	for _, vin := range tests {
+		if _, err := decodeVIN(vin, 2026); err != ErrInvalidVIN {
+			t.Errorf("decodeVIN(%q) error = %v, want %v", vin, err, ErrInvalidVIN)
+		}
+	}
+}
+
+func TestDecodeVINYearCycle(t *testing.T) {
+	tests := []struct {
+		code        byte
+		currentYear int
+		want        int
+	}{
+		// Y was last used for 2000; A has come round to 2010
+		{'Y', 2026, 2000},
+		{'A', 2026, 2010},
+		{'1', 2026, 2001},
+		{'9', 2026, 2009},
+		// Codes for next year's models are already in use
+		{'A', 2009, 2010},
+		{'A', 2008, 1980},
+		{'V', 2026, 2027},
+		{'V', 2025, 1997},
+		{'W', 2026, 1998},
+		{'S', 2026, 2025},
+	}
+
+	for _, tt := range tests {
+		vin := "1HGCM8263" + string(tt.code) + "A004352"
+		decoded, err := decodeVIN(vin, tt.currentYear)
+		if err != nil {
+			t.Fatalf("decodeVIN(%q): %v", vin, err)
+		}
+		if decoded.ModelYear == nil {
+			t.Errorf("year code %c in %d: ModelYear = none, want %d", tt.code, tt.currentYear, tt.want)
+		} else if *decoded.ModelYear != tt.want {
+			t.Errorf("year code %c in %d: ModelYear = %d, want %d", tt.code, tt.currentYear, *decoded.ModelYear, tt.want)
+		}
+	}
+}
+
+func TestVINCheckDigit(t *testing.T) {
+	tests := []struct {
+		vin  string
+		want byte
+	}{
+		{"1M8GDM9AXKP042788", 'X'},
+		{"1HGCM82633A004352", '3'},
+		{"11111111111111111", '1'},
+		{"WDD2050421F123456", '2'},
+		// The ninth character itself does not count
+		{"1HGCM826Z3A004352", '3'},
+	}
+
+	for _, tt := range tests {
+		if got := vinCheckDigit(tt.vin); got != tt.want {
+			t.Errorf("vinCheckDigit(%q) = %c, want %c", tt.vin, got, tt.want)
+		}
+	}
+}
+
+func TestVINRegion(t *testing.T) {
+	tests := []struct {
+		code byte
+		want string
+	}{
+		{'A', "Africa"},
+		{'H', "Africa"},
+		{'J', "Asia"},
+		{'N', "Asia"},
+		{'R', "Asia"},
+		{'S', "Europe"},
+		{'W', "Europe"},
+		{'Z', "Europe"},
+		{'1', "North America"},
+		{'5', "North America"},
+		{'6', "Oceania"},
+		{'7', "Oceania"},
+		{'8', "South America"},
+		{'9', "South America"},
+	}
+
+	for _, tt := range tests {
+		if got := vinRegion(tt.code); got != tt.want {
+			t.Errorf("vinRegion(%c) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestRankByModelYear(t *testing.T) {
+	year := func(y int) *int { return &y }
+	submodels := []*vehiclemodels.Submodel{
+		{SubmodelID: 1, YearFrom: 0},
+		{SubmodelID: 2, YearFrom: 2015, YearTo: year(2018)},
+		{SubmodelID: 3, YearFrom: 2008, YearTo: year(2012)},
+		{SubmodelID: 4, YearFrom: 2012},
+		{SubmodelID: 5, YearFrom: 2010, YearTo: year(2014)},
+	}
+
+	rankByModelYear(submodels, year(2013))
+
+	want := []int{4, 5, 3, 2, 1}
+	for i, submodel := range submodels {
+		if submodel.SubmodelID != want[i] {
+			t.Fatalf("order = %v, want %v", submodelIDs(submodels), want)
+		}
+	}
+
+	// Without a year the order is kept
+	rankByModelYear(submodels, nil)
+	for i, submodel := range submodels {
+		if submodel.SubmodelID != want[i] {
+			t.Fatalf("order without year = %v, want %v", submodelIDs(submodels), want)
+		}
+	}
+}
+
+func submodelIDs(submodels []*vehiclemodels.Submodel) []int {
+	ids := make([]int, len(submodels))
+	for i, submodel := range submodels {
+		ids[i] = submodel.SubmodelID
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package services
+
+// knownManufacturer is a world manufacturer identifier everybody uses, with
+// the names its make may have in the catalog
+type knownManufacturer struct {
+	name  string
+	makes []string
+}
+
+// knownManufacturers are looked up by make name when a VIN is decoded, so
+// they work however the makes came into the catalog. Entries in the local
+// table come first.
+var knownManufacturers = map[string]knownManufacturer{
+	"WVW": {"Volkswagen", []string{"Volkswagen"}},
+	"WV1": {"Volkswagen Commercial Vehicles", []string{"Volkswagen"}},
+	"WV2": {"Volkswagen Commercial Vehicles", []string{"Volkswagen"}},
+	"WAU": {"Audi", []string{"Audi"}},
+	"WUA": {"Audi Sport", []string{"Audi"}},
+	"WBA": {"BMW", []string{"BMW"}},
+	"WBS": {"BMW M", []string{"BMW"}},
+	"WMW": {"MINI", []string{"MINI"}},
+	"WDB": {"Mercedes-Benz", []string{"Mercedes-Benz", "Mercedes"}},
+	"WDD": {"Mercedes-Benz", []string{"Mercedes-Benz", "Mercedes"}},
+	"W1K": {"Mercedes-Benz", []string{"Mercedes-Benz", "Mercedes"}},
+	"WP0": {"Porsche", []string{"Porsche"}},
+	"WF0": {"Ford Germany", []string{"Ford"}},
+	"NM0": {"Ford Otosan", []string{"Ford"}},
+	"1FA": {"Ford USA", []string{"Ford"}},
+	"W0L": {"Opel", []string{"Opel"}},
+	"W0V": {"Opel", []string{"Opel"}},
+	"VF1": {"Renault", []string{"Renault"}},
+	"VF3": {"Peugeot", []string{"Peugeot"}},
+	"VR3": {"Peugeot", []string{"Peugeot"}},
+	"VF7": {"Citroen", []string{"Citroen", "Citroën"}},
+	"VR7": {"Citroen", []string{"Citroen", "Citroën"}},
+	"UU1": {"Dacia", []string{"Dacia"}},
+	"ZFA": {"Fiat", []string{"Fiat"}},
+	"NM4": {"Tofas", []string{"Fiat"}},
+	"ZAR": {"Alfa Romeo", []string{"Alfa Romeo", "Alfa"}},
+	"TMB": {"Skoda", []string{"Skoda", "Škoda"}},
+	"VSS": {"SEAT", []string{"SEAT"}},
+	"NMT": {"Toyota Turkey", []string{"Toyota"}},
+	"JTD": {"Toyota", []string{"Toyota"}},
+	"SB1": {"Toyota UK", []string{"Toyota"}},
+	"VNK": {"Toyota France", []string{"Toyota"}},
+	"JHM": {"Honda", []string{"Honda"}},
+	"NLA": {"Honda Turkey", []string{"Honda"}},
+	"NLH": {"Hyundai Assan", []string{"Hyundai"}},
+	"KMH": {"Hyundai", []string{"Hyundai"}},
+	"TMA": {"Hyundai Czech", []string{"Hyundai"}},
+	"KNA": {"Kia", []string{"Kia"}},
+	"U5Y": {"Kia Slovakia", []string{"Kia"}},
+	"JN1": {"Nissan", []string{"Nissan"}},
+	"SJN": {"Nissan UK", []string{"Nissan"}},
+	"VSK": {"Nissan Spain", []string{"Nissan"}},
+	"JMZ": {"Mazda", []string{"Mazda"}},
+	"JSA": {"Suzuki", []string{"Suzuki"}},
+	"TSM": {"Suzuki Hungary", []string{"Suzuki"}},
+	"JMB": {"Mitsubishi", []string{"Mitsubishi"}},
+	"YV1": {"Volvo", []string{"Volvo"}},
+	"SAL": {"Land Rover", []string{"Land Rover"}},
+	"SAJ": {"Jaguar", []string{"Jaguar"}},
+	"ZAM": {"Maserati", []string{"Maserati"}},
+	"5YJ": {"Tesla", []string{"Tesla"}},
+	"LRW": {"Tesla China", []string{"Tesla"}},
+	"JF1": {"Subaru", []string{"Subaru"}},
+	"1C4": {"Jeep", []string{"Jeep"}},
+}
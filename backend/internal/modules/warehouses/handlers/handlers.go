@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseHandler struct {
+	service services.WarehouseService
+}
+
+func NewWarehouseHandler(service services.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{
+		service: service,
+	}
+}
+
+// GetWarehouses handles listing of warehouses, the inactive ones only when
+// asked for
+func (h *WarehouseHandler) GetWarehouses(c echo.Context) error {
+	includeInactive, _ := strconv.ParseBool(c.QueryParam("include_inactive"))
+
+	ctx := c.Request().Context()
+	warehouses, err := h.service.GetWarehouses(ctx, includeInactive)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, warehouses)
+}
+
+// GetWarehouseByID handles retrieval of a single warehouse
+func (h *WarehouseHandler) GetWarehouseByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	warehouse, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, warehouse)
+}
+
+// CreateWarehouse handles creation of a new warehouse
+func (h *WarehouseHandler) CreateWarehouse(c echo.Context) error {
+	warehouse := &warehousemodels.Warehouse{IsActive: true}
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateWarehouse(ctx, warehouse)
+	if err != nil {
+		switch err {
+		case services.ErrCodeRequired, services.ErrNameRequired,
+			services.ErrInvalidWarehouseType, services.ErrInactiveDefault:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrDuplicateCode:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	created, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateWarehouse handles updating an existing warehouse
+func (h *WarehouseHandler) UpdateWarehouse(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	warehouse := &warehousemodels.Warehouse{IsActive: true}
+	if err := c.Bind(warehouse); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	warehouse.WarehouseID = id
+
+	ctx := c.Request().Context()
+	err = h.service.UpdateWarehouse(ctx, warehouse)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID, services.ErrCodeRequired,
+			services.ErrNameRequired, services.ErrInvalidWarehouseType,
+			services.ErrInactiveDefault:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrDuplicateCode, services.ErrDefaultWarehouse:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	updated, err := h.service.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteWarehouse handles deletion of a warehouse that was never used
+func (h *WarehouseHandler) DeleteWarehouse(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	ctx := c.Request().Context()
+	err = h.service.DeleteWarehouse(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		case services.ErrDefaultWarehouse, services.ErrWarehouseInUse:
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWarehouseStock handles listing of the stock held in a warehouse
+func (h *WarehouseHandler) GetWarehouseStock(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	page, err := pagination.FromRequest(c, services.StockSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := &warehousemodels.StockFilter{WarehouseID: id}
+
+	// Parse query parameters
+	if search := c.QueryParam("search"); search != "" {
+		filter.Search = &search
+	}
+
+	if inStock, err := strconv.ParseBool(c.QueryParam("in_stock")); err == nil {
+		filter.InStock = inStock
+	}
+
+	if lowStock, err := strconv.ParseBool(c.QueryParam("low_stock")); err == nil {
+		filter.LowStock = lowStock
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetWarehouseStock(ctx, filter, page)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrWarehouseNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return pagination.Respond(c, page, stock)
+}
+
+// GetItemStock handles retrieval of the stock of an item in each warehouse
+func (h *WarehouseHandler) GetItemStock(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.GetItemStock(ctx, itemID)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidItemID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+// UpdateStockSettings handles setting the minimum and location of an item
+// in a warehouse
+func (h *WarehouseHandler) UpdateStockSettings(c echo.Context) error {
+	warehouseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid warehouse ID")
+	}
+
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid item ID")
+	}
+
+	settings := new(warehousemodels.StockSettings)
+	if err := c.Bind(settings); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	stock, err := h.service.UpdateStockSettings(ctx, warehouseID, itemID, settings)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidWarehouseID, services.ErrInvalidItemID,
+			services.ErrInvalidMinimumStock:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrWarehouseNotFound, services.ErrItemNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, stock)
+}
+
+func currentUserID(c echo.Context) *int {
+	if user := authmiddleware.CurrentUser(c); user != nil {
+		return &user.UserID
+	}
+	return nil
+}
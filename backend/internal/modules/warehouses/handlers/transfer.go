@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/labstack/echo/v4"
+)
+
+// GetTransfers handles listing of stock transfers
+func (h *WarehouseHandler) GetTransfers(c echo.Context) error {
+	page, err := pagination.FromRequest(c, services.TransferSorting)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	filter := &warehousemodels.TransferFilter{}
+
+	// Parse query parameters
+	if warehouseID := c.QueryParam("warehouse_id"); warehouseID != "" {
+		id, err := strconv.Atoi(warehouseID)
+		if err == nil {
+			filter.WarehouseID = &id
+		}
+	}
+
+	if fromWarehouseID := c.QueryParam("from_warehouse_id"); fromWarehouseID != "" {
+		id, err := strconv.Atoi(fromWarehouseID)
+		if err == nil {
+			filter.FromWarehouseID = &id
+		}
+	}
+
+	if toWarehouseID := c.QueryParam("to_warehouse_id"); toWarehouseID != "" {
+		id, err := strconv.Atoi(toWarehouseID)
+		if err == nil {
+			filter.ToWarehouseID = &id
+		}
+	}
+
+	if itemID := c.QueryParam("item_id"); itemID != "" {
+		id, err := strconv.Atoi(itemID)
+		if err == nil {
+			filter.ItemID = &id
+		}
+	}
+
+	if status := c.QueryParam("status"); status != "" {
+		filter.Status = &status
+	}
+
+	ctx := c.Request().Context()
+	transfers, err := h.service.GetTransfers(ctx, filter, page)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidStatus:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return pagination.Respond(c, page, transfers)
+}
+
+// GetTransferByID handles retrieval of a single stock transfer
+func (h *WarehouseHandler) GetTransferByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidTransferID:
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		case services.ErrTransferNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// CreateTransfer handles creation of a draft stock transfer
+func (h *WarehouseHandler) CreateTransfer(c echo.Context) error {
+	transfer := new(warehousemodels.StockTransfer)
+	if err := c.Bind(transfer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	transfer.CreatedByUserID = currentUserID(c)
+
+	ctx := c.Request().Context()
+	id, err := h.service.CreateTransfer(ctx, transfer)
+	if err != nil {
+		return transferError(err)
+	}
+
+	created, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateTransfer handles changing a draft stock transfer
+func (h *WarehouseHandler) UpdateTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	transfer := new(warehousemodels.StockTransfer)
+	if err := c.Bind(transfer); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	transfer.TransferID = id
+
+	ctx := c.Request().Context()
+	if err := h.service.UpdateTransfer(ctx, transfer); err != nil {
+		return transferError(err)
+	}
+
+	updated, err := h.service.GetTransferByID(ctx, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, updated)
+}
+
+// ShipTransfer handles sending a draft transfer, which takes its stock out
+// of the source warehouse
+func (h *WarehouseHandler) ShipTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.ShipTransfer(ctx, id, currentUserID(c))
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// ReceiveTransfer handles taking in a transfer at its destination
+func (h *WarehouseHandler) ReceiveTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.ReceiveTransfer(ctx, id, currentUserID(c))
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// CancelTransfer handles cancelling a transfer before it is received
+func (h *WarehouseHandler) CancelTransfer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid transfer ID")
+	}
+
+	ctx := c.Request().Context()
+	transfer, err := h.service.CancelTransfer(ctx, id)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(http.StatusOK, transfer)
+}
+
+// transferError maps the errors of changing a transfer to HTTP errors
+func transferError(err error) error {
+	switch err {
+	case services.ErrInvalidTransferID, services.ErrInvalidWarehouseID,
+		services.ErrInvalidItemID, services.ErrInvalidQuantity,
+		services.ErrSameWarehouse, services.ErrEmptyTransfer,
+		services.ErrDuplicateTransferItem:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case services.ErrTransferNotFound, services.ErrWarehouseNotFound,
+		services.ErrItemNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case services.ErrTransferNotDraft, services.ErrTransferNotShipped,
+		services.ErrTransferClosed, services.ErrDuplicateTransferNo,
+		services.ErrInactiveWarehouse:
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case services.ErrInsufficientStock:
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
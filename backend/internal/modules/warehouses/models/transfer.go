@@ -0,0 +1,59 @@
+package warehousemodels
+
+import "time"
+
+const (
+	TransferStatusDraft     = "draft"
+	TransferStatusInTransit = "in-transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+// StockTransfer moves stock from one warehouse to another. Shipping takes
+// the lines out of the source and receiving puts them into the destination;
+// in between the stock is in transit and on neither shelf.
+type StockTransfer struct {
+	TransferID       int                  `json:"transfer_id" db:"transfer_id"`
+	TransferNumber   string               `json:"transfer_number" db:"transfer_number"`
+	FromWarehouseID  int                  `json:"from_warehouse_id" db:"from_warehouse_id"`
+	ToWarehouseID    int                  `json:"to_warehouse_id" db:"to_warehouse_id"`
+	Status           string               `json:"status" db:"status"`
+	CreatedByUserID  *int                 `json:"created_by_user_id,omitempty" db:"created_by_user_id"`
+	ShippedByUserID  *int                 `json:"shipped_by_user_id,omitempty" db:"shipped_by_user_id"`
+	ReceivedByUserID *int                 `json:"received_by_user_id,omitempty" db:"received_by_user_id"`
+	ShippedAt        *time.Time           `json:"shipped_at,omitempty" db:"shipped_at"`
+	ReceivedAt       *time.Time           `json:"received_at,omitempty" db:"received_at"`
+	Notes            *string              `json:"notes,omitempty" db:"notes"`
+	Lines            []*StockTransferLine `json:"lines"`
+	CreatedAt        time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	FromWarehouseName string `json:"from_warehouse_name,omitempty" db:"from_warehouse_name"`
+	ToWarehouseName   string `json:"to_warehouse_name,omitempty" db:"to_warehouse_name"`
+	TotalQuantity     int    `json:"total_quantity"`
+}
+
+// StockTransferLine is a single item moved by a transfer
+type StockTransferLine struct {
+	LineID     int       `json:"line_id" db:"line_id"`
+	TransferID int       `json:"transfer_id" db:"transfer_id"`
+	ItemID     int       `json:"item_id" db:"item_id"`
+	Quantity   int       `json:"quantity" db:"quantity"`
+	Notes      *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	ItemPartNumber  string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription *string `json:"item_description,omitempty" db:"item_description"`
+}
+
+type TransferFilter struct {
+	// WarehouseID matches transfers leaving or arriving at a warehouse
+	WarehouseID     *int    `query:"warehouse_id"`
+	FromWarehouseID *int    `query:"from_warehouse_id"`
+	ToWarehouseID   *int    `query:"to_warehouse_id"`
+	Status          *string `query:"status"`
+	ItemID          *int    `query:"item_id"`
+}
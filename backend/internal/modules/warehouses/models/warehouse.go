@@ -0,0 +1,68 @@
+package warehousemodels
+
+import "time"
+
+const (
+	WarehouseTypeShop      = "shop"
+	WarehouseTypeWarehouse = "warehouse"
+)
+
+// Warehouse is a shop or warehouse that holds stock. The default one takes
+// the stock of anything that does not name a warehouse.
+type Warehouse struct {
+	WarehouseID   int       `json:"warehouse_id" db:"warehouse_id"`
+	Code          string    `json:"code" db:"code"`
+	Name          string    `json:"name" db:"name"`
+	WarehouseType string    `json:"warehouse_type" db:"warehouse_type"`
+	Address       *string   `json:"address,omitempty" db:"address"`
+	IsDefault     bool      `json:"is_default" db:"is_default"`
+	IsActive      bool      `json:"is_active" db:"is_active"`
+	Notes         *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WarehouseStock is the stock of an item in one warehouse and where it sits
+// there. A MinimumStock left empty falls back to the item's.
+type WarehouseStock struct {
+	WarehouseID      int       `json:"warehouse_id" db:"warehouse_id"`
+	ItemID           int       `json:"item_id" db:"item_id"`
+	Quantity         int       `json:"quantity" db:"quantity"`
+	MinimumStock     *int      `json:"minimum_stock" db:"minimum_stock"`
+	LocationFloor    *string   `json:"location_floor,omitempty" db:"location_floor"`
+	LocationCorridor *string   `json:"location_corridor,omitempty" db:"location_corridor"`
+	LocationAisle    *string   `json:"location_aisle,omitempty" db:"location_aisle"`
+	LocationShelf    *string   `json:"location_shelf,omitempty" db:"location_shelf"`
+	LocationBin      *string   `json:"location_bin,omitempty" db:"location_bin"`
+	BinID            *int      `json:"bin_id,omitempty" db:"bin_id"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+
+	// Additional fields for API responses
+	WarehouseCode   string  `json:"warehouse_code,omitempty" db:"warehouse_code"`
+	WarehouseName   string  `json:"warehouse_name,omitempty" db:"warehouse_name"`
+	ItemPartNumber  string  `json:"item_part_number,omitempty" db:"item_part_number"`
+	ItemDescription *string `json:"item_description,omitempty" db:"item_description"`
+	// EffectiveMinimum is the minimum the warehouse is checked against for
+	// low stock
+	EffectiveMinimum int `json:"effective_minimum" db:"effective_minimum"`
+	// InTransit is what has been shipped to the warehouse and not yet
+	// received
+	InTransit int `json:"in_transit" db:"in_transit"`
+}
+
+// StockSettings is the body of PUT /warehouses/:id/stock/:itemId
+type StockSettings struct {
+	MinimumStock     *int    `json:"minimum_stock"`
+	LocationFloor    *string `json:"location_floor"`
+	LocationCorridor *string `json:"location_corridor"`
+	LocationAisle    *string `json:"location_aisle"`
+	LocationShelf    *string `json:"location_shelf"`
+	LocationBin      *string `json:"location_bin"`
+}
+
+type StockFilter struct {
+	WarehouseID int     `query:"-"`
+	Search      *string `query:"search"`
+	InStock     bool    `query:"in_stock"`
+	LowStock    bool    `query:"low_stock"`
+}
@@ -0,0 +1,421 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint failure
+const uniqueViolation = "23505"
+
+// foreignKeyViolation is the Postgres error code for a missing or still
+// referenced row
+const foreignKeyViolation = "23503"
+
+// StockSorting lists the fields the stock of a warehouse can be sorted by
+var StockSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":          {Column: "ws.item_id", Type: "integer"},
+		"part_number": {Column: "i.part_number", Type: "text"},
+		"quantity":    {Column: "ws.quantity", Type: "integer"},
+	},
+	Default:      "part_number",
+	IDColumn:     "ws.item_id",
+	DefaultLimit: pagination.DefaultLimit,
+}
+
+const warehouseColumns = `
+        warehouse_id, code, name, warehouse_type, address,
+        is_default, is_active, notes, created_at, updated_at
+    `
+
+const stockSelectQuery = `
+        SELECT
+            ws.warehouse_id, ws.item_id, ws.quantity, ws.minimum_stock,
+            ws.location_floor, ws.location_corridor, ws.location_aisle,
+            ws.location_shelf, ws.location_bin, ws.bin_id, ws.updated_at,
+            w.code, w.name, i.part_number, i.description,
+            COALESCE(ws.minimum_stock, i.minimum_stock) AS effective_minimum,
+            COALESCE((
+                SELECT SUM(l.quantity)
+                FROM stock_transfer_lines l
+                JOIN stock_transfers t ON t.transfer_id = l.transfer_id
+                WHERE t.status = 'in-transit'
+                    AND t.to_warehouse_id = ws.warehouse_id
+                    AND l.item_id = ws.item_id
+            ), 0) AS in_transit
+        FROM warehouse_stock ws
+        JOIN warehouses w ON w.warehouse_id = ws.warehouse_id
+        JOIN items i ON i.item_id = ws.item_id
+    `
+
+type PostgresWarehouseRepository struct {
+	db        *db.Database
+	movements stockmovementrepositories.StockMovementRepository
+}
+
+func NewPostgresWarehouseRepository(db *db.Database, movements stockmovementrepositories.StockMovementRepository) WarehouseRepository {
+	return &PostgresWarehouseRepository{
+		db:        db,
+		movements: movements,
+	}
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouses(ctx context.Context, includeInactive bool) ([]*warehousemodels.Warehouse, error) {
+	query := `SELECT` + warehouseColumns + `
+        FROM warehouses
+        WHERE is_active OR $1
+        ORDER BY is_default DESC, name
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []*warehousemodels.Warehouse
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error) {
+	query := `SELECT` + warehouseColumns + `FROM warehouses WHERE warehouse_id = $1`
+
+	warehouse, err := scanWarehouse(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *PostgresWarehouseRepository) CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if warehouse.IsDefault {
+		if _, err = tx.Exec(ctx, `UPDATE warehouses SET is_default = false WHERE is_default`); err != nil {
+			return 0, err
+		}
+	}
+
+	query := `
+        INSERT INTO warehouses (
+            code, name, warehouse_type, address, is_default, is_active, notes
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING warehouse_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.WarehouseType,
+		warehouse.Address,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err, "unique_warehouse_code") {
+			return 0, ErrDuplicateCode
+		}
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *PostgresWarehouseRepository) UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if warehouse.IsDefault {
+		_, err = tx.Exec(ctx, `
+            UPDATE warehouses SET is_default = false
+            WHERE is_default AND warehouse_id <> $1
+        `, warehouse.WarehouseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+        UPDATE warehouses
+        SET code = $1, name = $2, warehouse_type = $3, address = $4,
+            is_default = $5, is_active = $6, notes = $7
+        WHERE warehouse_id = $8
+    `
+
+	result, err := tx.Exec(
+		ctx, query,
+		warehouse.Code,
+		warehouse.Name,
+		warehouse.WarehouseType,
+		warehouse.Address,
+		warehouse.IsDefault,
+		warehouse.IsActive,
+		warehouse.Notes,
+		warehouse.WarehouseID,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "unique_warehouse_code") {
+			return ErrDuplicateCode
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrWarehouseNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteWarehouse removes a warehouse that holds no stock and has never been
+// used. Empty stock rows only record where items would sit, so they go with
+// it; anything else that references the warehouse keeps it.
+func (r *PostgresWarehouseRepository) DeleteWarehouse(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM warehouse_stock WHERE warehouse_id = $1 AND quantity = 0`, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM warehouses WHERE warehouse_id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return ErrWarehouseInUse
+		}
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrWarehouseNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWarehouseRepository) GetWarehouseStock(ctx context.Context, filter *warehousemodels.StockFilter, page *pagination.Page) ([]*warehousemodels.WarehouseStock, error) {
+	query := stockSelectQuery + " WHERE ws.warehouse_id = $1"
+	params := []interface{}{filter.WarehouseID}
+	paramCount := 2
+
+	var conditions []string
+
+	if filter.Search != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(i.part_number ILIKE $%d OR i.description ILIKE $%d)",
+			paramCount, paramCount,
+		))
+		params = append(params, "%"+*filter.Search+"%")
+		paramCount++
+	}
+
+	if filter.InStock {
+		conditions = append(conditions, "ws.quantity > 0")
+	}
+
+	if filter.LowStock {
+		conditions = append(conditions, "i.is_active AND ws.quantity <= COALESCE(ws.minimum_stock, i.minimum_stock)")
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), params...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	query, params = StockSorting.Apply(query, params, page)
+	stock, err := r.queryStock(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	if page != nil && len(stock) > 0 {
+		last := stock[len(stock)-1]
+		page.SetNextCursor(len(stock), stockSortValue(last, page.Sort), last.ItemID)
+	}
+
+	return stock, nil
+}
+
+func (r *PostgresWarehouseRepository) GetItemStock(ctx context.Context, itemID int) ([]*warehousemodels.WarehouseStock, error) {
+	query := stockSelectQuery + `
+        WHERE ws.item_id = $1
+        ORDER BY w.is_default DESC, w.name
+    `
+
+	return r.queryStock(ctx, query, itemID)
+}
+
+// UpdateStockSettings sets the minimum and location of an item in a
+// warehouse, creating its stock row when the item has never been there.
+// Typing a different location takes the item out of its bin.
+func (r *PostgresWarehouseRepository) UpdateStockSettings(ctx context.Context, warehouseID, itemID int, settings *warehousemodels.StockSettings) error {
+	query := `
+        INSERT INTO warehouse_stock (
+            warehouse_id, item_id, minimum_stock,
+            location_floor, location_corridor, location_aisle, location_shelf, location_bin
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        ON CONFLICT (warehouse_id, item_id) DO UPDATE
+        SET minimum_stock = EXCLUDED.minimum_stock,
+            location_floor = EXCLUDED.location_floor,
+            location_corridor = EXCLUDED.location_corridor,
+            location_aisle = EXCLUDED.location_aisle,
+            location_shelf = EXCLUDED.location_shelf,
+            location_bin = EXCLUDED.location_bin,
+            bin_id = CASE
+                WHEN ROW(warehouse_stock.location_floor, warehouse_stock.location_corridor,
+                    warehouse_stock.location_aisle, warehouse_stock.location_shelf,
+                    warehouse_stock.location_bin)
+                    IS NOT DISTINCT FROM ROW(EXCLUDED.location_floor, EXCLUDED.location_corridor,
+                    EXCLUDED.location_aisle, EXCLUDED.location_shelf, EXCLUDED.location_bin)
+                THEN warehouse_stock.bin_id
+            END
+    `
+
+	_, err := r.db.Pool.Exec(
+		ctx, query,
+		warehouseID,
+		itemID,
+		settings.MinimumStock,
+		settings.LocationFloor,
+		settings.LocationCorridor,
+		settings.LocationAisle,
+		settings.LocationShelf,
+		settings.LocationBin,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			if pgErr.ConstraintName == "warehouse_stock_item_id_fkey" {
+				return ErrItemNotFound
+			}
+			return ErrWarehouseNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresWarehouseRepository) queryStock(ctx context.Context, query string, params ...interface{}) ([]*warehousemodels.WarehouseStock, error) {
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stock []*warehousemodels.WarehouseStock
+	for rows.Next() {
+		s := &warehousemodels.WarehouseStock{}
+		err := rows.Scan(
+			&s.WarehouseID,
+			&s.ItemID,
+			&s.Quantity,
+			&s.MinimumStock,
+			&s.LocationFloor,
+			&s.LocationCorridor,
+			&s.LocationAisle,
+			&s.LocationShelf,
+			&s.LocationBin,
+			&s.BinID,
+			&s.UpdatedAt,
+			&s.WarehouseCode,
+			&s.WarehouseName,
+			&s.ItemPartNumber,
+			&s.ItemDescription,
+			&s.EffectiveMinimum,
+			&s.InTransit,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+
+	return stock, rows.Err()
+}
+
+func scanWarehouse(row pgx.Row) (*warehousemodels.Warehouse, error) {
+	warehouse := &warehousemodels.Warehouse{}
+	err := row.Scan(
+		&warehouse.WarehouseID,
+		&warehouse.Code,
+		&warehouse.Name,
+		&warehouse.WarehouseType,
+		&warehouse.Address,
+		&warehouse.IsDefault,
+		&warehouse.IsActive,
+		&warehouse.Notes,
+		&warehouse.CreatedAt,
+		&warehouse.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// stockSortValue returns the value of the sort field of a stock row for
+// its cursor
+func stockSortValue(stock *warehousemodels.WarehouseStock, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(stock.ItemID)
+	case "quantity":
+		return strconv.Itoa(stock.Quantity)
+	default:
+		return stock.ItemPartNumber
+	}
+}
+
+// isUniqueViolation reports whether err broke the named unique constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+)
+
+var (
+	ErrWarehouseNotFound   = errors.New("warehouse not found")
+	ErrDuplicateCode       = errors.New("warehouse code already exists")
+	ErrWarehouseInUse      = errors.New("warehouse holds stock or has documents and cannot be deleted")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrInsufficientStock   = errors.New("insufficient stock in the source warehouse")
+	ErrTransferNotFound    = errors.New("transfer not found")
+	ErrTransferNotDraft    = errors.New("only draft transfers can be changed or shipped")
+	ErrTransferNotShipped  = errors.New("only transfers in transit can be received")
+	ErrTransferClosed      = errors.New("transfer is already received or cancelled")
+	ErrDuplicateTransferNo = errors.New("transfer number already exists")
+)
+
+type WarehouseRepository interface {
+	// Warehouse operations
+	GetWarehouses(ctx context.Context, includeInactive bool) ([]*warehousemodels.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error)
+	UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error
+	DeleteWarehouse(ctx context.Context, id int) error
+
+	// Stock operations
+	GetWarehouseStock(ctx context.Context, filter *warehousemodels.StockFilter, page *pagination.Page) ([]*warehousemodels.WarehouseStock, error)
+	GetItemStock(ctx context.Context, itemID int) ([]*warehousemodels.WarehouseStock, error)
+	UpdateStockSettings(ctx context.Context, warehouseID, itemID int, settings *warehousemodels.StockSettings) error
+
+	// Transfer operations
+	GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter, page *pagination.Page) ([]*warehousemodels.StockTransfer, error)
+	GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+	CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error)
+	UpdateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) error
+	// ShipTransfer takes the lines of a draft transfer out of its source
+	// warehouse, ReceiveTransfer puts them into the destination, and
+	// CancelTransfer returns stock in transit to the source.
+	ShipTransfer(ctx context.Context, id int, userID *int) error
+	ReceiveTransfer(ctx context.Context, id int, userID *int) error
+	CancelTransfer(ctx context.Context, id int) error
+}
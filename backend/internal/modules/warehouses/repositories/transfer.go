@@ -0,0 +1,530 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	stockmovementmodels "github.com/hsrvms/autoparts/internal/modules/stockmovements/models"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// TransferSorting lists the fields transfers can be sorted by
+var TransferSorting = &pagination.Sorting{
+	Fields: map[string]pagination.Field{
+		"id":              {Column: "t.transfer_id", Type: "integer"},
+		"date":            {Column: "t.created_at", Type: "timestamptz"},
+		"status":          {Column: "t.status", Type: "text"},
+		"transfer_number": {Column: "t.transfer_number", Type: "text"},
+	},
+	Default:      "-date",
+	IDColumn:     "t.transfer_id",
+	DefaultLimit: pagination.DefaultLimit,
+}
+
+const transferSelectQuery = `
+        SELECT
+            t.transfer_id, t.transfer_number, t.from_warehouse_id, t.to_warehouse_id,
+            t.status, t.created_by_user_id, t.shipped_by_user_id, t.received_by_user_id,
+            t.shipped_at, t.received_at, t.notes, t.created_at, t.updated_at,
+            f.name AS from_warehouse_name, d.name AS to_warehouse_name
+        FROM stock_transfers t
+        JOIN warehouses f ON f.warehouse_id = t.from_warehouse_id
+        JOIN warehouses d ON d.warehouse_id = t.to_warehouse_id
+    `
+
+func (r *PostgresWarehouseRepository) GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter, page *pagination.Page) ([]*warehousemodels.StockTransfer, error) {
+	query := transferSelectQuery + " WHERE 1=1"
+
+	var conditions []string
+	var params []interface{}
+	paramCount := 1
+
+	if filter != nil {
+		if filter.WarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf(
+				"(t.from_warehouse_id = $%d OR t.to_warehouse_id = $%d)",
+				paramCount, paramCount,
+			))
+			params = append(params, *filter.WarehouseID)
+			paramCount++
+		}
+
+		if filter.FromWarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.from_warehouse_id = $%d", paramCount))
+			params = append(params, *filter.FromWarehouseID)
+			paramCount++
+		}
+
+		if filter.ToWarehouseID != nil {
+			conditions = append(conditions, fmt.Sprintf("t.to_warehouse_id = $%d", paramCount))
+			params = append(params, *filter.ToWarehouseID)
+			paramCount++
+		}
+
+		if filter.Status != nil {
+			conditions = append(conditions, fmt.Sprintf("t.status = $%d", paramCount))
+			params = append(params, *filter.Status)
+			paramCount++
+		}
+
+		if filter.ItemID != nil {
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM stock_transfer_lines l WHERE l.transfer_id = t.transfer_id AND l.item_id = $%d)",
+				paramCount,
+			))
+			params = append(params, *filter.ItemID)
+			paramCount++
+		}
+	}
+
+	if len(conditions) > 0 {
+		query += " AND " + strings.Join(conditions, " AND ")
+	}
+
+	if page != nil {
+		if err := r.db.Pool.QueryRow(ctx, pagination.CountQuery(query), params...).Scan(&page.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	query, params = TransferSorting.Apply(query, params, page)
+	rows, err := r.db.Pool.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*warehousemodels.StockTransfer
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = r.loadTransferLines(ctx, transfers); err != nil {
+		return nil, err
+	}
+
+	if page != nil && len(transfers) > 0 {
+		last := transfers[len(transfers)-1]
+		page.SetNextCursor(len(transfers), transferSortValue(last, page.Sort), last.TransferID)
+	}
+
+	return transfers, nil
+}
+
+func (r *PostgresWarehouseRepository) GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	query := transferSelectQuery + " WHERE t.transfer_id = $1"
+
+	transfer, err := scanTransfer(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err = r.loadTransferLines(ctx, []*warehousemodels.StockTransfer{transfer}); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (r *PostgresWarehouseRepository) CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Transfers are numbered like T20240115-000042 unless a number is given
+	query := `
+        INSERT INTO stock_transfers (
+            transfer_number, from_warehouse_id, to_warehouse_id, status,
+            created_by_user_id, notes
+        ) VALUES (
+            COALESCE(NULLIF($1, ''), 'T' || to_char(CURRENT_DATE, 'YYYYMMDD') || '-' ||
+                lpad(nextval('stock_transfer_number_seq')::text, 6, '0')),
+            $2, $3, $4, $5, $6
+        )
+        RETURNING transfer_id
+    `
+
+	var id int
+	err = tx.QueryRow(
+		ctx, query,
+		transfer.TransferNumber,
+		transfer.FromWarehouseID,
+		transfer.ToWarehouseID,
+		warehousemodels.TransferStatusDraft,
+		transfer.CreatedByUserID,
+		transfer.Notes,
+	).Scan(&id)
+	if err != nil {
+		return 0, transferError(err)
+	}
+
+	if err = insertTransferLines(ctx, tx, id, transfer.Lines); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateTransfer replaces the header and lines of a draft transfer
+func (r *PostgresWarehouseRepository) UpdateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, _, _, err = lockTransfer(ctx, tx, transfer.TransferID, warehousemodels.TransferStatusDraft); err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE stock_transfers
+        SET transfer_number = $1, from_warehouse_id = $2, to_warehouse_id = $3, notes = $4
+        WHERE transfer_id = $5
+    `
+
+	_, err = tx.Exec(
+		ctx, query,
+		transfer.TransferNumber,
+		transfer.FromWarehouseID,
+		transfer.ToWarehouseID,
+		transfer.Notes,
+		transfer.TransferID,
+	)
+	if err != nil {
+		return transferError(err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM stock_transfer_lines WHERE transfer_id = $1`, transfer.TransferID); err != nil {
+		return err
+	}
+
+	if err = insertTransferLines(ctx, tx, transfer.TransferID, transfer.Lines); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWarehouseRepository) ShipTransfer(ctx context.Context, id int, userID *int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, fromWarehouseID, _, err := lockTransfer(ctx, tx, id, warehousemodels.TransferStatusDraft)
+	if err != nil {
+		return err
+	}
+
+	err = r.moveTransferStock(ctx, tx, id, fromWarehouseID, stockmovementmodels.MovementTypeOut, "Shipped on transfer")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE stock_transfers
+        SET status = $2, shipped_by_user_id = $3, shipped_at = CURRENT_TIMESTAMP
+        WHERE transfer_id = $1
+    `, id, warehousemodels.TransferStatusInTransit, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWarehouseRepository) ReceiveTransfer(ctx context.Context, id int, userID *int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, _, toWarehouseID, err := lockTransfer(ctx, tx, id, warehousemodels.TransferStatusInTransit)
+	if err != nil {
+		return err
+	}
+
+	err = r.moveTransferStock(ctx, tx, id, toWarehouseID, stockmovementmodels.MovementTypeIn, "Received on transfer")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE stock_transfers
+        SET status = $2, received_by_user_id = $3, received_at = CURRENT_TIMESTAMP
+        WHERE transfer_id = $1
+    `, id, warehousemodels.TransferStatusReceived, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CancelTransfer cancels a draft transfer, or one in transit by putting its
+// stock back into the source warehouse
+func (r *PostgresWarehouseRepository) CancelTransfer(ctx context.Context, id int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, fromWarehouseID, _, err := lockTransfer(ctx, tx, id, "")
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case warehousemodels.TransferStatusDraft:
+	case warehousemodels.TransferStatusInTransit:
+		err = r.moveTransferStock(ctx, tx, id, fromWarehouseID, stockmovementmodels.MovementTypeIn, "Returned on cancelled transfer")
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrTransferClosed
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE stock_transfers SET status = $2 WHERE transfer_id = $1`,
+		id, warehousemodels.TransferStatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// moveTransferStock books the lines of a transfer in or out of a warehouse.
+// Lines are taken in item order so concurrent transfers lock items in the
+// same order.
+func (r *PostgresWarehouseRepository) moveTransferStock(ctx context.Context, tx pgx.Tx, transferID, warehouseID int, movementType, notes string) error {
+	rows, err := tx.Query(ctx, `
+        SELECT item_id, quantity FROM stock_transfer_lines
+        WHERE transfer_id = $1
+        ORDER BY item_id
+    `, transferID)
+	if err != nil {
+		return err
+	}
+
+	var lines []*warehousemodels.StockTransferLine
+	for rows.Next() {
+		line := &warehousemodels.StockTransferLine{}
+		if err := rows.Scan(&line.ItemID, &line.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		lines = append(lines, line)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	referenceType := stockmovementmodels.ReferenceTypeTransfer
+	for _, line := range lines {
+		err := r.movements.RecordTx(ctx, tx, &stockmovementmodels.StockMovement{
+			ItemID:        line.ItemID,
+			WarehouseID:   warehouseID,
+			MovementType:  movementType,
+			Quantity:      line.Quantity,
+			ReferenceID:   &transferID,
+			ReferenceType: &referenceType,
+			Notes:         &notes,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, stockmovementrepositories.ErrInsufficientStock):
+				return ErrInsufficientStock
+			case errors.Is(err, stockmovementrepositories.ErrItemNotFound):
+				return ErrItemNotFound
+			case errors.Is(err, stockmovementrepositories.ErrWarehouseNotFound):
+				return ErrWarehouseNotFound
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresWarehouseRepository) loadTransferLines(ctx context.Context, transfers []*warehousemodels.StockTransfer) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(transfers))
+	byID := make(map[int]*warehousemodels.StockTransfer, len(transfers))
+	for i, transfer := range transfers {
+		ids[i] = transfer.TransferID
+		byID[transfer.TransferID] = transfer
+		transfer.Lines = []*warehousemodels.StockTransferLine{}
+	}
+
+	query := `
+        SELECT
+            l.line_id, l.transfer_id, l.item_id, l.quantity, l.notes,
+            l.created_at, l.updated_at, i.part_number, i.description
+        FROM stock_transfer_lines l
+        JOIN items i ON i.item_id = l.item_id
+        WHERE l.transfer_id = ANY($1)
+        ORDER BY l.line_id
+    `
+
+	rows, err := r.db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := &warehousemodels.StockTransferLine{}
+		err := rows.Scan(
+			&line.LineID,
+			&line.TransferID,
+			&line.ItemID,
+			&line.Quantity,
+			&line.Notes,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.ItemPartNumber,
+			&line.ItemDescription,
+		)
+		if err != nil {
+			return err
+		}
+
+		transfer := byID[line.TransferID]
+		transfer.Lines = append(transfer.Lines, line)
+		transfer.TotalQuantity += line.Quantity
+	}
+
+	return rows.Err()
+}
+
+// lockTransfer locks a transfer and returns its status and warehouses. When
+// status is given, a transfer in any other status is refused.
+func lockTransfer(ctx context.Context, tx pgx.Tx, id int, status string) (string, int, int, error) {
+	var current string
+	var fromWarehouseID, toWarehouseID int
+	err := tx.QueryRow(ctx, `
+        SELECT status, from_warehouse_id, to_warehouse_id
+        FROM stock_transfers
+        WHERE transfer_id = $1
+        FOR UPDATE
+    `, id).Scan(&current, &fromWarehouseID, &toWarehouseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", 0, 0, ErrTransferNotFound
+		}
+		return "", 0, 0, err
+	}
+
+	if status != "" && current != status {
+		if status == warehousemodels.TransferStatusDraft {
+			return "", 0, 0, ErrTransferNotDraft
+		}
+		return "", 0, 0, ErrTransferNotShipped
+	}
+
+	return current, fromWarehouseID, toWarehouseID, nil
+}
+
+func insertTransferLines(ctx context.Context, tx pgx.Tx, transferID int, lines []*warehousemodels.StockTransferLine) error {
+	for _, line := range lines {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO stock_transfer_lines (transfer_id, item_id, quantity, notes)
+            VALUES ($1, $2, $3, $4)
+        `, transferID, line.ItemID, line.Quantity, line.Notes)
+		if err != nil {
+			return transferError(err)
+		}
+	}
+	return nil
+}
+
+// transferError maps the constraint failures of writing a transfer to the
+// errors callers understand
+func transferError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch {
+	case pgErr.Code == uniqueViolation && pgErr.ConstraintName == "stock_transfers_transfer_number_key":
+		return ErrDuplicateTransferNo
+	case pgErr.Code == foreignKeyViolation && pgErr.ConstraintName == "stock_transfer_lines_item_id_fkey":
+		return ErrItemNotFound
+	case pgErr.Code == foreignKeyViolation && strings.HasSuffix(pgErr.ConstraintName, "warehouse_id_fkey"):
+		return ErrWarehouseNotFound
+	}
+	return err
+}
+
+func scanTransfer(row pgx.Row) (*warehousemodels.StockTransfer, error) {
+	transfer := &warehousemodels.StockTransfer{}
+	err := row.Scan(
+		&transfer.TransferID,
+		&transfer.TransferNumber,
+		&transfer.FromWarehouseID,
+		&transfer.ToWarehouseID,
+		&transfer.Status,
+		&transfer.CreatedByUserID,
+		&transfer.ShippedByUserID,
+		&transfer.ReceivedByUserID,
+		&transfer.ShippedAt,
+		&transfer.ReceivedAt,
+		&transfer.Notes,
+		&transfer.CreatedAt,
+		&transfer.UpdatedAt,
+		&transfer.FromWarehouseName,
+		&transfer.ToWarehouseName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// transferSortValue returns the value of the sort field of a transfer for
+// its cursor
+func transferSortValue(transfer *warehousemodels.StockTransfer, sort string) string {
+	switch sort {
+	case "id":
+		return strconv.Itoa(transfer.TransferID)
+	case "status":
+		return transfer.Status
+	case "transfer_number":
+		return transfer.TransferNumber
+	default:
+		return pagination.Time(transfer.CreatedAt)
+	}
+}
@@ -0,0 +1,51 @@
+package warehouses
+
+import (
+	authmiddleware "github.com/hsrvms/autoparts/internal/modules/auth/middleware"
+	authmodels "github.com/hsrvms/autoparts/internal/modules/auth/models"
+	stockmovementrepositories "github.com/hsrvms/autoparts/internal/modules/stockmovements/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/handlers"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/repositories"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/services"
+	"github.com/hsrvms/autoparts/pkg/db"
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterRoutes(api *echo.Group, database *db.Database) {
+	// Initialize repositories
+	movementRepo := stockmovementrepositories.NewPostgresStockMovementRepository(database)
+	repo := repositories.NewPostgresWarehouseRepository(database, movementRepo)
+
+	// Initialize service
+	service := services.NewWarehouseService(repo)
+
+	// Initialize handler
+	handler := handlers.NewWarehouseHandler(service)
+
+	// Permissions
+	read := authmiddleware.RequirePermission(authmodels.PermStockRead)
+	write := authmiddleware.RequirePermission(authmodels.PermWarehousesWrite)
+	adjust := authmiddleware.RequirePermission(authmodels.PermStockAdjust)
+	transfer := authmiddleware.RequirePermission(authmodels.PermTransfersWrite)
+
+	// Register routes
+	warehouses := api.Group("/warehouses")
+	warehouses.GET("", handler.GetWarehouses, read)
+	warehouses.GET("/:id", handler.GetWarehouseByID, read)
+	warehouses.POST("", handler.CreateWarehouse, write)
+	warehouses.PUT("/:id", handler.UpdateWarehouse, write)
+	warehouses.DELETE("/:id", handler.DeleteWarehouse, write)
+	warehouses.GET("/:id/stock", handler.GetWarehouseStock, read)
+	warehouses.PUT("/:id/stock/:itemId", handler.UpdateStockSettings, adjust)
+
+	api.GET("/items/:id/stock", handler.GetItemStock, read)
+
+	transfers := api.Group("/transfers")
+	transfers.GET("", handler.GetTransfers, read)
+	transfers.GET("/:id", handler.GetTransferByID, read)
+	transfers.POST("", handler.CreateTransfer, transfer)
+	transfers.PUT("/:id", handler.UpdateTransfer, transfer)
+	transfers.POST("/:id/ship", handler.ShipTransfer, transfer)
+	transfers.POST("/:id/receive", handler.ReceiveTransfer, transfer)
+	transfers.POST("/:id/cancel", handler.CancelTransfer, transfer)
+}
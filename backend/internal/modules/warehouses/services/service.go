@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses/repositories"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+)
+
+var (
+	ErrWarehouseNotFound     = repositories.ErrWarehouseNotFound
+	ErrDuplicateCode         = repositories.ErrDuplicateCode
+	ErrWarehouseInUse        = repositories.ErrWarehouseInUse
+	ErrItemNotFound          = repositories.ErrItemNotFound
+	ErrInsufficientStock     = repositories.ErrInsufficientStock
+	ErrTransferNotFound      = repositories.ErrTransferNotFound
+	ErrTransferNotDraft      = repositories.ErrTransferNotDraft
+	ErrTransferNotShipped    = repositories.ErrTransferNotShipped
+	ErrTransferClosed        = repositories.ErrTransferClosed
+	ErrDuplicateTransferNo   = repositories.ErrDuplicateTransferNo
+	ErrInvalidWarehouseID    = errors.New("invalid warehouse ID")
+	ErrInvalidItemID         = errors.New("invalid item ID")
+	ErrCodeRequired          = errors.New("warehouse code is required")
+	ErrNameRequired          = errors.New("warehouse name is required")
+	ErrInvalidWarehouseType  = errors.New("warehouse type must be 'shop' or 'warehouse'")
+	ErrDefaultWarehouse      = errors.New("the default warehouse cannot be deleted or deactivated; make another warehouse the default first")
+	ErrInactiveDefault       = errors.New("an inactive warehouse cannot be the default")
+	ErrInactiveWarehouse     = errors.New("warehouse is not active")
+	ErrInvalidMinimumStock   = errors.New("minimum stock cannot be negative")
+	ErrInvalidTransferID     = errors.New("invalid transfer ID")
+	ErrInvalidStatus         = errors.New("invalid transfer status")
+	ErrSameWarehouse         = errors.New("transfer source and destination must be different warehouses")
+	ErrEmptyTransfer         = errors.New("transfer must contain at least one item")
+	ErrInvalidQuantity       = errors.New("quantity must be greater than zero")
+	ErrDuplicateTransferItem = errors.New("item appears more than once on the transfer")
+)
+
+// StockSorting lists the fields the stock of a warehouse can be sorted by
+var StockSorting = repositories.StockSorting
+
+// TransferSorting lists the fields transfers can be sorted by
+var TransferSorting = repositories.TransferSorting
+
+type WarehouseService interface {
+	// Warehouse operations
+	GetWarehouses(ctx context.Context, includeInactive bool) ([]*warehousemodels.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error)
+	CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error)
+	UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error
+	DeleteWarehouse(ctx context.Context, id int) error
+
+	// Stock operations
+	GetWarehouseStock(ctx context.Context, filter *warehousemodels.StockFilter, page *pagination.Page) ([]*warehousemodels.WarehouseStock, error)
+	GetItemStock(ctx context.Context, itemID int) ([]*warehousemodels.WarehouseStock, error)
+	UpdateStockSettings(ctx context.Context, warehouseID, itemID int, settings *warehousemodels.StockSettings) (*warehousemodels.WarehouseStock, error)
+
+	// Transfer operations
+	GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter, page *pagination.Page) ([]*warehousemodels.StockTransfer, error)
+	GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+	CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error)
+	UpdateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) error
+	ShipTransfer(ctx context.Context, id int, userID *int) (*warehousemodels.StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, id int, userID *int) (*warehousemodels.StockTransfer, error)
+	CancelTransfer(ctx context.Context, id int) (*warehousemodels.StockTransfer, error)
+}
+
+type warehouseService struct {
+	repo repositories.WarehouseRepository
+}
+
+func NewWarehouseService(repo repositories.WarehouseRepository) WarehouseService {
+	return &warehouseService{
+		repo: repo,
+	}
+}
+
+func (s *warehouseService) GetWarehouses(ctx context.Context, includeInactive bool) ([]*warehousemodels.Warehouse, error) {
+	return s.repo.GetWarehouses(ctx, includeInactive)
+}
+
+func (s *warehouseService) GetWarehouseByID(ctx context.Context, id int) (*warehousemodels.Warehouse, error) {
+	if id <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+
+	warehouse, err := s.repo.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse == nil {
+		return nil, ErrWarehouseNotFound
+	}
+
+	return warehouse, nil
+}
+
+func (s *warehouseService) CreateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) (int, error) {
+	if warehouse.WarehouseType == "" {
+		warehouse.WarehouseType = warehousemodels.WarehouseTypeShop
+	}
+	if err := s.validateWarehouse(warehouse); err != nil {
+		return 0, err
+	}
+
+	return s.repo.CreateWarehouse(ctx, warehouse)
+}
+
+func (s *warehouseService) UpdateWarehouse(ctx context.Context, warehouse *warehousemodels.Warehouse) error {
+	existing, err := s.GetWarehouseByID(ctx, warehouse.WarehouseID)
+	if err != nil {
+		return err
+	}
+
+	// There is always a default warehouse, so it only moves by making
+	// another one the default
+	if existing.IsDefault && (!warehouse.IsDefault || !warehouse.IsActive) {
+		return ErrDefaultWarehouse
+	}
+
+	if warehouse.WarehouseType == "" {
+		warehouse.WarehouseType = existing.WarehouseType
+	}
+	if err := s.validateWarehouse(warehouse); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateWarehouse(ctx, warehouse)
+}
+
+func (s *warehouseService) DeleteWarehouse(ctx context.Context, id int) error {
+	existing, err := s.GetWarehouseByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing.IsDefault {
+		return ErrDefaultWarehouse
+	}
+
+	return s.repo.DeleteWarehouse(ctx, id)
+}
+
+func (s *warehouseService) GetWarehouseStock(ctx context.Context, filter *warehousemodels.StockFilter, page *pagination.Page) ([]*warehousemodels.WarehouseStock, error) {
+	if _, err := s.GetWarehouseByID(ctx, filter.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetWarehouseStock(ctx, filter, page)
+}
+
+// GetItemStock returns the stock of an item in every warehouse it has been
+// in. Every item starts with a row in the default warehouse, so none means
+// there is no such item.
+func (s *warehouseService) GetItemStock(ctx context.Context, itemID int) ([]*warehousemodels.WarehouseStock, error) {
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+
+	stock, err := s.repo.GetItemStock(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(stock) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	return stock, nil
+}
+
+func (s *warehouseService) UpdateStockSettings(ctx context.Context, warehouseID, itemID int, settings *warehousemodels.StockSettings) (*warehousemodels.WarehouseStock, error) {
+	if warehouseID <= 0 {
+		return nil, ErrInvalidWarehouseID
+	}
+	if itemID <= 0 {
+		return nil, ErrInvalidItemID
+	}
+	if settings.MinimumStock != nil && *settings.MinimumStock < 0 {
+		return nil, ErrInvalidMinimumStock
+	}
+
+	if err := s.repo.UpdateStockSettings(ctx, warehouseID, itemID, settings); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.GetItemStock(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range stock {
+		if row.WarehouseID == warehouseID {
+			return row, nil
+		}
+	}
+
+	return nil, ErrItemNotFound
+}
+
+// Helper functions
+
+func (s *warehouseService) validateWarehouse(warehouse *warehousemodels.Warehouse) error {
+	warehouse.Code = strings.ToUpper(strings.TrimSpace(warehouse.Code))
+	warehouse.Name = strings.TrimSpace(warehouse.Name)
+
+	if warehouse.Code == "" {
+		return ErrCodeRequired
+	}
+	if warehouse.Name == "" {
+		return ErrNameRequired
+	}
+	switch warehouse.WarehouseType {
+	case warehousemodels.WarehouseTypeShop, warehousemodels.WarehouseTypeWarehouse:
+	default:
+		return ErrInvalidWarehouseType
+	}
+	if warehouse.IsDefault && !warehouse.IsActive {
+		return ErrInactiveDefault
+	}
+	return nil
+}
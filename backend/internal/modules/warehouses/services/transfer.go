@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	warehousemodels "github.com/hsrvms/autoparts/internal/modules/warehouses/models"
+	"github.com/hsrvms/autoparts/pkg/pagination"
+)
+
+func (s *warehouseService) GetTransfers(ctx context.Context, filter *warehousemodels.TransferFilter, page *pagination.Page) ([]*warehousemodels.StockTransfer, error) {
+	if filter != nil && filter.Status != nil && !validStatus(*filter.Status) {
+		return nil, ErrInvalidStatus
+	}
+
+	return s.repo.GetTransfers(ctx, filter, page)
+}
+
+func (s *warehouseService) GetTransferByID(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+
+	transfer, err := s.repo.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+// CreateTransfer records a draft transfer. Nothing moves until it is
+// shipped.
+func (s *warehouseService) CreateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) (int, error) {
+	if err := s.validateTransfer(ctx, transfer); err != nil {
+		return 0, err
+	}
+
+	return s.repo.CreateTransfer(ctx, transfer)
+}
+
+func (s *warehouseService) UpdateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) error {
+	existing, err := s.GetTransferByID(ctx, transfer.TransferID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != warehousemodels.TransferStatusDraft {
+		return ErrTransferNotDraft
+	}
+
+	if strings.TrimSpace(transfer.TransferNumber) == "" {
+		transfer.TransferNumber = existing.TransferNumber
+	}
+	if err := s.validateTransfer(ctx, transfer); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateTransfer(ctx, transfer)
+}
+
+func (s *warehouseService) ShipTransfer(ctx context.Context, id int, userID *int) (*warehousemodels.StockTransfer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+	if err := s.repo.ShipTransfer(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+func (s *warehouseService) ReceiveTransfer(ctx context.Context, id int, userID *int) (*warehousemodels.StockTransfer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+	if err := s.repo.ReceiveTransfer(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+func (s *warehouseService) CancelTransfer(ctx context.Context, id int) (*warehousemodels.StockTransfer, error) {
+	if id <= 0 {
+		return nil, ErrInvalidTransferID
+	}
+	if err := s.repo.CancelTransfer(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// validateTransfer checks the warehouses and lines of a transfer. Both
+// warehouses must be active for stock to be sent between them.
+func (s *warehouseService) validateTransfer(ctx context.Context, transfer *warehousemodels.StockTransfer) error {
+	transfer.TransferNumber = strings.TrimSpace(transfer.TransferNumber)
+
+	if transfer.FromWarehouseID <= 0 || transfer.ToWarehouseID <= 0 {
+		return ErrInvalidWarehouseID
+	}
+	if transfer.FromWarehouseID == transfer.ToWarehouseID {
+		return ErrSameWarehouse
+	}
+
+	if len(transfer.Lines) == 0 {
+		return ErrEmptyTransfer
+	}
+	seen := make(map[int]bool, len(transfer.Lines))
+	for _, line := range transfer.Lines {
+		if line.ItemID <= 0 {
+			return ErrInvalidItemID
+		}
+		if line.Quantity <= 0 {
+			return ErrInvalidQuantity
+		}
+		if seen[line.ItemID] {
+			return ErrDuplicateTransferItem
+		}
+		seen[line.ItemID] = true
+	}
+
+	for _, id := range []int{transfer.FromWarehouseID, transfer.ToWarehouseID} {
+		warehouse, err := s.GetWarehouseByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !warehouse.IsActive {
+			return ErrInactiveWarehouse
+		}
+	}
+
+	return nil
+}
+
+func validStatus(status string) bool {
+	switch status {
+	case warehousemodels.TransferStatusDraft, warehousemodels.TransferStatusInTransit,
+		warehousemodels.TransferStatusReceived, warehousemodels.TransferStatusCancelled:
+		return true
+	}
+	return false
+}
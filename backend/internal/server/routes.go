@@ -3,13 +3,17 @@ package server
 import (
 	"net/http"
 
+	"github.com/hsrvms/autoparts/internal/modules/auth"
 	"github.com/hsrvms/autoparts/internal/modules/categories"
 	"github.com/hsrvms/autoparts/internal/modules/dashboard"
 	"github.com/hsrvms/autoparts/internal/modules/inventory"
+	"github.com/hsrvms/autoparts/internal/modules/locations"
 	"github.com/hsrvms/autoparts/internal/modules/purchases"
 	"github.com/hsrvms/autoparts/internal/modules/sales"
+	"github.com/hsrvms/autoparts/internal/modules/stockmovements"
 	"github.com/hsrvms/autoparts/internal/modules/suppliers"
 	"github.com/hsrvms/autoparts/internal/modules/vehicles"
+	"github.com/hsrvms/autoparts/internal/modules/warehouses"
 	"github.com/labstack/echo/v4"
 )
 
@@ -24,11 +28,19 @@ func (s *Server) initRoutes() {
 		return c.JSON(http.StatusOK, map[string]string{"version": "1.0.0"})
 	})
 
-	dashboard.RegisterRoutes(s.Echo, api, s.DB)
-	categories.RegisterRoutes(api, s.DB)
-	vehicles.RegisterRoutes(api, s.DB)
-	inventory.RegisterRoutes(api, s.DB)
-	suppliers.RegisterRoutes(api, s.DB)
-	purchases.RegisterRoutes(api, s.DB)
-	sales.RegisterRoutes(api, s.DB)
+	requireAuth := auth.RegisterRoutes(api, s.DB, s.Config.Auth)
+
+	// Everything below requires a signed-in user
+	protected := api.Group("", requireAuth)
+
+	dashboard.RegisterRoutes(s.Echo, protected, s.DB)
+	categories.RegisterRoutes(protected, s.DB)
+	vehicles.RegisterRoutes(protected, s.DB)
+	inventory.RegisterRoutes(protected, s.DB, s.Config.Barcode)
+	suppliers.RegisterRoutes(protected, s.DB)
+	purchases.RegisterRoutes(protected, s.DB)
+	sales.RegisterRoutes(protected, s.DB)
+	stockmovements.RegisterRoutes(protected, s.DB)
+	warehouses.RegisterRoutes(protected, s.DB)
+	locations.RegisterRoutes(protected, s.DB)
 }
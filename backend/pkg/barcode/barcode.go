@@ -1,72 +1,166 @@
 package barcode
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
+// Formats of the barcodes we issue
+const (
+	// FormatInternal is an EAN-13 in the GS1 range for restricted
+	// circulation (prefix 20). Any scanner reads it, but it only means
+	// something inside the shop.
+	FormatInternal = "internal"
+	// FormatGS1 is an EAN-13 under our own GS1 company prefix
+	FormatGS1 = "gs1"
+	// FormatLegacy is a code issued before the EAN-13 scheme, either
+	// XXnnnnnnYYZZv or the XX-nnnnnn-YY-v the database used to assign.
+	// The barcode service filled XX with "C" and the first digit of the
+	// category ID, and its item numbers could run past six digits.
+	FormatLegacy = "legacy"
+)
+
+// InternalPrefix starts every internal barcode. It must match the prefix
+// arac.generate_barcode_trigger uses in the database.
+const InternalPrefix = "20"
+
+var (
+	ErrUnknownFormat        = errors.New("barcode format must be internal or gs1")
+	ErrNoCompanyPrefix      = errors.New("no GS1 company prefix is configured")
+	ErrInvalidCompanyPrefix = errors.New("GS1 company prefix must be 6 to 11 digits outside the 20-29 range")
+	ErrSequenceExhausted    = errors.New("barcode sequence has run out of item numbers")
+)
+
+var (
+	ean13Pattern          = regexp.MustCompile(`^\d{13}$`)
+	legacyPattern         = regexp.MustCompile(`^[A-Z][A-Z0-9]\d{11,}$`)
+	legacyDatabasePattern = regexp.MustCompile(`^[^-]{2}-\d{6}-\d{2}-\d$`)
+	companyPrefixPattern  = regexp.MustCompile(`^\d{6,11}$`)
+)
+
 // Generator handles barcode generation
-type Generator struct{}
+type Generator struct {
+	companyPrefix string
+}
 
-// New creates a new barcode generator
-func New() *Generator {
-	return &Generator{}
+// New creates a new barcode generator. companyPrefix is the GS1 company
+// prefix GS1 barcodes are issued under, and may be empty when we have none.
+func New(companyPrefix string) *Generator {
+	return &Generator{companyPrefix: strings.TrimSpace(companyPrefix)}
 }
 
-// Generate creates a new barcode for an item
-// Format: XXnnnnnnYYZZv where:
-// XX: Category prefix (2 letters)
-// nnnnnn: Item ID (6 digits, zero-padded)
-// YY: Start year (last 2 digits)
-// ZZ: End year (last 2 digits)
-// v: Validation digit
-func (g *Generator) Generate(categoryName string, itemID int, yearFrom, yearTo int) (string, error) {
-	// Get category prefix (first 2 letters, uppercase)
-	prefix := strings.ToUpper(categoryName)
-	if len(prefix) < 2 {
-		return "", fmt.Errorf("category name too short")
+// DefaultFormat returns the format barcodes are issued in when none is
+// asked for: GS1 if a company prefix is configured, internal otherwise
+func (g *Generator) DefaultFormat() string {
+	if g.companyPrefix != "" {
+		return FormatGS1
 	}
-	prefix = prefix[:2]
-
-	// Format item ID to 6 digits
-	itemNum := fmt.Sprintf("%06d", itemID)
+	return FormatInternal
+}
 
-	// Get year suffixes (last 2 digits of each year)
-	yearFromSuffix := fmt.Sprintf("%02d", yearFrom%100)
-	yearToSuffix := fmt.Sprintf("%02d", yearTo%100)
+// Generate creates the EAN-13 barcode of a format for the sequence number
+// of an item. The number fills the digits the prefix leaves, so each
+// sequence number gives a different barcode.
+func (g *Generator) Generate(format string, sequence int64) (string, error) {
+	var prefix string
+	switch format {
+	case FormatInternal:
+		prefix = InternalPrefix
+	case FormatGS1:
+		if g.companyPrefix == "" {
+			return "", ErrNoCompanyPrefix
+		}
+		if !validCompanyPrefix(g.companyPrefix) {
+			return "", ErrInvalidCompanyPrefix
+		}
+		prefix = g.companyPrefix
+	default:
+		return "", ErrUnknownFormat
+	}
 
-	// Create base code
-	baseCode := fmt.Sprintf("%s%s%s%s", prefix, itemNum, yearFromSuffix, yearToSuffix)
+	width := 12 - len(prefix)
+	number := strconv.FormatInt(sequence, 10)
+	if sequence < 0 || len(number) > width {
+		return "", ErrSequenceExhausted
+	}
 
-	// Calculate check digit
-	checkDigit := g.calculateCheckDigit(baseCode)
+	base := prefix + fmt.Sprintf("%0*s", width, number)
+	return base + strconv.Itoa(ean13CheckDigit(base)), nil
+}
 
-	// Return complete barcode
-	return fmt.Sprintf("%s%d", baseCode, checkDigit), nil
+// Format returns the format of a barcode we issued, or an empty string if
+// the barcode is not one of ours
+func (g *Generator) Format(barcode string) string {
+	switch {
+	case ValidEAN13(barcode) && strings.HasPrefix(barcode, InternalPrefix):
+		return FormatInternal
+	case ValidEAN13(barcode) && g.companyPrefix != "" && strings.HasPrefix(barcode, g.companyPrefix):
+		return FormatGS1
+	case validLegacy(barcode):
+		return FormatLegacy
+	default:
+		return ""
+	}
 }
 
-// Validate checks if a barcode is valid
+// Validate checks if a barcode is one we issued, in any format
 func (g *Generator) Validate(barcode string) bool {
-	// Check format: 12 characters + 1 check digit
-	if len(barcode) != 13 {
+	return g.Format(barcode) != ""
+}
+
+// ValidEAN13 reports whether a barcode is 13 digits with a correct EAN-13
+// check digit, whoever issued it
+func ValidEAN13(barcode string) bool {
+	if !ean13Pattern.MatchString(barcode) {
 		return false
 	}
+	return ean13CheckDigit(barcode[:12]) == int(barcode[12]-'0')
+}
 
-	// Check format with regex
-	match, _ := regexp.MatchString(`^[A-Z]{2}\d{6}\d{2}\d{2}\d{1}$`, barcode)
-	if !match {
+// validCompanyPrefix reports whether a GS1 company prefix leaves room for
+// item numbers and is not in the range GS1 keeps for restricted circulation
+func validCompanyPrefix(prefix string) bool {
+	return companyPrefixPattern.MatchString(prefix) && prefix[0] != '2'
+}
+
+// validLegacy checks the check digit of a code in either legacy format
+func validLegacy(barcode string) bool {
+	switch {
+	case legacyPattern.MatchString(barcode):
+		last := len(barcode) - 1
+		return legacyCheckDigit(barcode[:last]) == int(barcode[last]-'0')
+	case legacyDatabasePattern.MatchString(barcode):
+		// The database summed the character codes of everything before
+		// the check digit
+		sum := 0
+		for _, r := range barcode[:len(barcode)-2] {
+			sum += int(r)
+		}
+		return sum%10 == int(barcode[len(barcode)-1]-'0')
+	default:
 		return false
 	}
+}
 
-	// Validate check digit
-	baseCode := barcode[:12]
-	checkDigit := int(barcode[12] - '0')
-	return g.calculateCheckDigit(baseCode) == checkDigit
+// ean13CheckDigit calculates the check digit of the first 12 digits of an
+// EAN-13, weighting them 1 and 3 in turn from the left
+func ean13CheckDigit(digits string) int {
+	sum := 0
+	for i, r := range digits {
+		if i%2 == 0 {
+			sum += int(r - '0')
+		} else {
+			sum += int(r-'0') * 3
+		}
+	}
+	return (10 - sum%10) % 10
 }
 
-// calculateCheckDigit calculates the check digit for a barcode
-func (g *Generator) calculateCheckDigit(baseCode string) int {
+// legacyCheckDigit calculates the check digit of an XXnnnnnnYYZZv code
+func legacyCheckDigit(baseCode string) int {
 	sum := 0
 	for i, r := range baseCode {
 		// Alternate between multiplying by 3 and 1
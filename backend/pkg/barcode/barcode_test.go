@@ -0,0 +1,120 @@
+package barcode
+
+import "testing"
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		barcode string
+		want    bool
+	}{
+		// Reference codes with known check digits
+		{"4006381333931", true},
+		{"5901234123457", true},
+		{"9780306406157", true},
+		{"0012345678905", true},
+		{"4006381333932", false},
+		{"5901234123450", false},
+		{"590123412345", false},
+		{"59012341234570", false},
+		{"590123412345X", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidEAN13(tt.barcode); got != tt.want {
+			t.Errorf("ValidEAN13(%q) = %v, want %v", tt.barcode, got, tt.want)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		format   string
+		sequence int64
+		want     string
+		wantErr  error
+	}{
+		{"", FormatInternal, 42, "2000000000428", nil},
+		{"", FormatInternal, 9999999999, "2099999999998", nil},
+		{"", FormatInternal, 10000000000, "", ErrSequenceExhausted},
+		{"", FormatInternal, -1, "", ErrSequenceExhausted},
+		{"869123456", FormatGS1, 7, "8691234560075", nil},
+		{"869123456", FormatGS1, 1000, "", ErrSequenceExhausted},
+		{"", FormatGS1, 7, "", ErrNoCompanyPrefix},
+		{"2012345", FormatGS1, 7, "", ErrInvalidCompanyPrefix},
+		{"12345", FormatGS1, 7, "", ErrInvalidCompanyPrefix},
+		{"869123456", FormatLegacy, 7, "", ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		got, err := New(tt.prefix).Generate(tt.format, tt.sequence)
+		if err != tt.wantErr {
+			t.Errorf("Generate(%q, %d) with prefix %q: error %v, want %v", tt.format, tt.sequence, tt.prefix, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Generate(%q, %d) with prefix %q = %q, want %q", tt.format, tt.sequence, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	generator := New("869123456")
+
+	for _, format := range []string{FormatInternal, FormatGS1} {
+		for _, sequence := range []int64{0, 1, 42, 999} {
+			barcode, err := generator.Generate(format, sequence)
+			if err != nil {
+				t.Fatalf("Generate(%q, %d): %v", format, sequence, err)
+			}
+			if !ValidEAN13(barcode) {
+				t.Errorf("Generate(%q, %d) = %q, not a valid EAN-13", format, sequence, barcode)
+			}
+			if !generator.Validate(barcode) {
+				t.Errorf("Validate(%q) = false for a generated barcode", barcode)
+			}
+			if got := generator.Format(barcode); got != format {
+				t.Errorf("Format(%q) = %q, want %q", barcode, got, format)
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	generator := New("869123456")
+
+	tests := []struct {
+		barcode string
+		want    string
+	}{
+		{"2000000000428", FormatInternal},
+		{"8691234560075", FormatGS1},
+		// Valid EAN-13 issued by somebody else
+		{"4006381333931", ""},
+		// Legacy codes from the barcode service: "C" and the first digit
+		// of the category ID, with item numbers past six digits too
+		{"C100004205108", FormatLegacy},
+		{"C3301200598057", FormatLegacy},
+		{"C100004205109", ""},
+		{"FR00012310209", FormatLegacy},
+		// Legacy codes the database assigned
+		{"FR-000042-24-8", FormatLegacy},
+		{"ÖN-000007-25-0", FormatLegacy},
+		{"FR-000042-24-7", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := generator.Format(tt.barcode); got != tt.want {
+			t.Errorf("Format(%q) = %q, want %q", tt.barcode, got, tt.want)
+		}
+		if got := generator.Validate(tt.barcode); got != (tt.want != "") {
+			t.Errorf("Validate(%q) = %v, want %v", tt.barcode, got, tt.want != "")
+		}
+	}
+
+	// Without a company prefix GS1 codes are not ours
+	if got := New("").Format("8691234560075"); got != "" {
+		t.Errorf("Format without company prefix = %q, want empty", got)
+	}
+}
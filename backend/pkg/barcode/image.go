@@ -0,0 +1,324 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	boombuler "github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Symbologies barcode images can be drawn in
+const (
+	SymbologyCode128    = "code128"
+	SymbologyEAN13      = "ean13"
+	SymbologyQR         = "qr"
+	SymbologyDataMatrix = "datamatrix"
+)
+
+// Formats of barcode images
+const (
+	ImagePNG = "png"
+	ImageSVG = "svg"
+)
+
+// Limits of barcode images
+const (
+	maxImageSize = 4000
+	minDPI       = 72
+	maxDPI       = 2400
+)
+
+var (
+	ErrUnknownSymbology   = errors.New("symbology must be code128, ean13, qr or datamatrix")
+	ErrUnknownImageFormat = errors.New("image format must be png or svg")
+	ErrInvalidImageSize   = fmt.Errorf("image width and height must be between 1 and %d pixels", maxImageSize)
+	ErrInvalidDPI         = fmt.Errorf("DPI must be between %d and %d", minDPI, maxDPI)
+	ErrImageTooSmall      = errors.New("image is too small for the barcode")
+	ErrCannotEncode       = errors.New("barcode cannot be drawn in this symbology")
+)
+
+var ean13Digits = regexp.MustCompile(`^\d{12,13}$`)
+
+// loadFont parses the font of the text under barcodes once
+var loadFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// ImageOptions control how a barcode image is drawn. Zero values take the
+// defaults: a Code128 PNG without text, 300x100 pixels for linear
+// symbologies and 200x200 for QR and DataMatrix.
+type ImageOptions struct {
+	Symbology string
+	Format    string
+	// Width and Height are in pixels, including the text
+	Width  int
+	Height int
+	// DPI is the resolution the image is meant to be printed at. PNG
+	// images record it, and SVG images are sized in millimetres from it.
+	DPI int
+	// Text prints the barcode in plain characters under the symbol
+	Text bool
+}
+
+// ContentType returns the media type of images drawn with the options
+func (o *ImageOptions) ContentType() string {
+	if o.Format == ImageSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// DrawImage draws content as a barcode with the options, which are filled
+// in with their defaults. Any content the symbology can hold is drawn,
+// whether or not we issued it.
+func DrawImage(w io.Writer, content string, options *ImageOptions) error {
+	if err := options.setDefaults(); err != nil {
+		return err
+	}
+	if options.Height <= options.textHeight() {
+		return ErrImageTooSmall
+	}
+
+	code, err := encode(content, options.Symbology)
+	if err != nil {
+		return err
+	}
+
+	if options.Format == ImageSVG {
+		return writeSVG(w, code, content, options)
+	}
+	return writePNG(w, code, content, options)
+}
+
+// setDefaults fills in the options left out and checks the rest
+func (o *ImageOptions) setDefaults() error {
+	if o.Symbology == "" {
+		o.Symbology = SymbologyCode128
+	}
+	if o.Format == "" {
+		o.Format = ImagePNG
+	}
+
+	switch o.Symbology {
+	case SymbologyCode128, SymbologyEAN13:
+		if o.Width == 0 {
+			o.Width = 300
+		}
+		if o.Height == 0 {
+			o.Height = 100
+		}
+	case SymbologyQR, SymbologyDataMatrix:
+		if o.Width == 0 {
+			o.Width = 200
+		}
+		if o.Height == 0 {
+			o.Height = 200
+		}
+	default:
+		return ErrUnknownSymbology
+	}
+
+	if o.Format != ImagePNG && o.Format != ImageSVG {
+		return ErrUnknownImageFormat
+	}
+	if o.Width < 1 || o.Width > maxImageSize || o.Height < 1 || o.Height > maxImageSize {
+		return ErrInvalidImageSize
+	}
+	if o.DPI != 0 && (o.DPI < minDPI || o.DPI > maxDPI) {
+		return ErrInvalidDPI
+	}
+	return nil
+}
+
+// textHeight returns the height of the band under the symbol that holds
+// its text
+func (o *ImageOptions) textHeight() int {
+	if !o.Text {
+		return 0
+	}
+	return max(12, o.Height/5)
+}
+
+func encode(content, symbology string) (boombuler.Barcode, error) {
+	var code boombuler.Barcode
+	var err error
+	switch symbology {
+	case SymbologyCode128:
+		code, err = code128.Encode(content)
+	case SymbologyEAN13:
+		if !ean13Digits.MatchString(content) {
+			return nil, fmt.Errorf("%w: EAN-13 barcodes are 12 or 13 digits", ErrCannotEncode)
+		}
+		code, err = ean.Encode(content)
+	case SymbologyQR:
+		code, err = qr.Encode(content, qr.M, qr.Auto)
+	case SymbologyDataMatrix:
+		code, err = datamatrix.Encode(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCannotEncode, err)
+	}
+	return code, nil
+}
+
+// writePNG draws the barcode with whole pixels per module, centred in the
+// image
+func writePNG(w io.Writer, code boombuler.Barcode, content string, options *ImageOptions) error {
+	textHeight := options.textHeight()
+	scaled, err := boombuler.Scale(code, options.Width, options.Height-textHeight)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImageTooSmall, err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, options.Width, options.Height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(img, scaled.Bounds(), scaled, image.Point{}, draw.Src)
+
+	if textHeight > 0 {
+		if err := drawText(img, content, textHeight); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+	if options.DPI != 0 {
+		data = withResolution(data, options.DPI)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// drawText writes text centred in the band of height at the bottom of an
+// image, smaller when it would not fit the width
+func drawText(img draw.Image, text string, height int) error {
+	regular, err := loadFont()
+	if err != nil {
+		return err
+	}
+
+	bounds := img.Bounds()
+	size := float64(height) * 0.8
+	for {
+		face, err := opentype.NewFace(regular, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return err
+		}
+
+		drawer := &font.Drawer{Dst: img, Src: image.Black, Face: face}
+		width := drawer.MeasureString(text).Round()
+		if width > bounds.Dx() && size > 4 {
+			face.Close()
+			size *= float64(bounds.Dx()) / float64(width+1)
+			continue
+		}
+
+		drawer.Dot = fixed.P((bounds.Dx()-width)/2, bounds.Max.Y-height/5)
+		drawer.DrawString(text)
+		return face.Close()
+	}
+}
+
+// withResolution adds a pHYs chunk recording dpi to a PNG file, right after
+// its header chunk
+func withResolution(data []byte, dpi int) []byte {
+	pixelsPerMetre := uint32(math.Round(float64(dpi) / 0.0254))
+
+	chunk := make([]byte, 21)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], pixelsPerMetre)
+	binary.BigEndian.PutUint32(chunk[12:], pixelsPerMetre)
+	chunk[16] = 1 // the unit is the metre
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	// The signature takes 8 bytes and the header chunk 25
+	const headerEnd = 8 + 25
+	result := make([]byte, 0, len(data)+len(chunk))
+	result = append(result, data[:headerEnd]...)
+	result = append(result, chunk...)
+	return append(result, data[headerEnd:]...)
+}
+
+// writeSVG draws the barcode as rectangles. Linear symbologies fill the
+// width, and square ones keep square modules centred in the image.
+func writeSVG(w io.Writer, code boombuler.Barcode, content string, options *ImageOptions) error {
+	textHeight := options.textHeight()
+	symbolHeight := float64(options.Height - textHeight)
+	bounds := code.Bounds()
+	columns, rows := bounds.Dx(), bounds.Dy()
+
+	moduleWidth, moduleHeight := float64(options.Width)/float64(columns), symbolHeight
+	left, top := 0.0, 0.0
+	if code.Metadata().Dimensions == 2 {
+		moduleWidth = math.Min(moduleWidth, symbolHeight/float64(rows))
+		moduleHeight = moduleWidth
+		left = (float64(options.Width) - moduleWidth*float64(columns)) / 2
+		top = (symbolHeight - moduleHeight*float64(rows)) / 2
+	}
+
+	width, height := fmt.Sprint(options.Width), fmt.Sprint(options.Height)
+	if options.DPI != 0 {
+		width = fmt.Sprintf("%.2fmm", float64(options.Width)/float64(options.DPI)*25.4)
+		height = fmt.Sprintf("%.2fmm", float64(options.Height)/float64(options.DPI)*25.4)
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		width, height, options.Width, options.Height)
+	svg.WriteString(`<rect width="100%" height="100%" fill="#fff"/>` + "\n")
+
+	// Neighbouring dark modules of a row are drawn as one rectangle
+	for y := 0; y < rows; y++ {
+		for x := 0; x < columns; {
+			if code.At(bounds.Min.X+x, bounds.Min.Y+y) != color.Black {
+				x++
+				continue
+			}
+			run := 1
+			for x+run < columns && code.At(bounds.Min.X+x+run, bounds.Min.Y+y) == color.Black {
+				run++
+			}
+			fmt.Fprintf(&svg, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f"/>`+"\n",
+				left+float64(x)*moduleWidth, top+float64(y)*moduleHeight, float64(run)*moduleWidth, moduleHeight)
+			x += run
+		}
+	}
+
+	if textHeight > 0 {
+		// Characters of a sans-serif font average about 0.6 of its size
+		// in width
+		size := math.Min(float64(textHeight)*0.8, float64(options.Width)/(0.6*float64(len([]rune(content)))))
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%d" font-family="sans-serif" font-size="%.1f" text-anchor="middle">%s</text>`+"\n",
+			float64(options.Width)/2, options.Height-textHeight/5, size, html.EscapeString(content))
+	}
+	svg.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, svg.String())
+	return err
+}
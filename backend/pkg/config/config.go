@@ -11,6 +11,8 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Auth     AuthConfig
+	Barcode  BarcodeConfig
 }
 
 // ServerConfig holds all server-related configuration
@@ -23,12 +25,29 @@ type ServerConfig struct {
 
 // DatabaseConfig holds all database-related configuration
 type DatabaseConfig struct {
-	Host     string
-	Port     int
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	DBName      string
+	SSLMode     string
+	AutoMigrate bool
+}
+
+// AuthConfig holds all authentication-related configuration
+type AuthConfig struct {
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	AdminUsername   string
+	AdminPassword   string
+}
+
+// BarcodeConfig holds the configuration of the barcodes we issue
+type BarcodeConfig struct {
+	// GS1CompanyPrefix is the company prefix GS1 assigned us. Without it only
+	// internal barcodes are issued.
+	GS1CompanyPrefix string
 }
 
 // New returns a new Config
@@ -41,12 +60,23 @@ func New() *Config {
 			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "autoparts"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			Host:        getEnv("DB_HOST", "localhost"),
+			Port:        getEnvAsInt("DB_PORT", 5432),
+			User:        getEnv("DB_USER", "postgres"),
+			Password:    getEnv("DB_PASSWORD", "postgres"),
+			DBName:      getEnv("DB_NAME", "autoparts"),
+			SSLMode:     getEnv("DB_SSL_MODE", "disable"),
+			AutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", false),
+		},
+		Auth: AuthConfig{
+			JWTSecret:       getEnv("AUTH_JWT_SECRET", ""),
+			AccessTokenTTL:  getEnvAsDuration("AUTH_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: getEnvAsDuration("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			AdminUsername:   getEnv("AUTH_ADMIN_USERNAME", ""),
+			AdminPassword:   getEnv("AUTH_ADMIN_PASSWORD", ""),
+		},
+		Barcode: BarcodeConfig{
+			GS1CompanyPrefix: getEnv("BARCODE_GS1_COMPANY_PREFIX", ""),
 		},
 	}
 }
@@ -81,6 +111,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value, exists := os.LookupEnv(key); exists {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS arac.stock_movements;
+DROP TABLE IF EXISTS arac.sales;
+DROP TABLE IF EXISTS arac.purchases;
+DROP TABLE IF EXISTS arac.users;
+DROP TABLE IF EXISTS arac.compatibility;
+DROP TABLE IF EXISTS arac.items;
+DROP TABLE IF EXISTS arac.suppliers;
+DROP TABLE IF EXISTS arac.submodels;
+DROP TABLE IF EXISTS arac.models;
+DROP TABLE IF EXISTS arac.makes;
+DROP TABLE IF EXISTS arac.categories;
+
+DROP FUNCTION IF EXISTS arac.generate_barcode_trigger();
+DROP FUNCTION IF EXISTS arac.generate_barcode(INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS arac.update_updated_at_column();
@@ -0,0 +1,283 @@
+-- Base schema the later migrations build on. Stock is kept by the
+-- application through stock_movements, so there are no stock triggers.
+CREATE SCHEMA IF NOT EXISTS arac;
+
+CREATE OR REPLACE FUNCTION arac.update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Categories with a parent/child hierarchy
+CREATE TABLE arac.categories (
+    category_id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    parent_category_id INTEGER REFERENCES arac.categories(category_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_category_name UNIQUE (name)
+);
+
+-- Vehicle makes, models (A3, 418, ...) and submodels (A3 Cabrio, 418d, ...)
+CREATE TABLE arac.makes (
+    make_id SERIAL PRIMARY KEY,
+    make_name VARCHAR(100) NOT NULL,
+    country VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_make_name UNIQUE (make_name)
+);
+
+CREATE TABLE arac.models (
+    model_id SERIAL PRIMARY KEY,
+    make_id INTEGER NOT NULL REFERENCES arac.makes(make_id) ON DELETE CASCADE,
+    model_name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_make_model UNIQUE (make_id, model_name)
+);
+
+CREATE TABLE arac.submodels (
+    submodel_id SERIAL PRIMARY KEY,
+    model_id INTEGER NOT NULL REFERENCES arac.models(model_id) ON DELETE CASCADE,
+    submodel_name VARCHAR(100) NOT NULL,
+    year_from INTEGER NOT NULL,
+    year_to INTEGER,
+    engine_type VARCHAR(100),
+    engine_displacement DECIMAL(3,1),
+    fuel_type VARCHAR(50),
+    transmission_type VARCHAR(50),
+    body_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_submodel UNIQUE (model_id, submodel_name, year_from)
+);
+
+CREATE TABLE arac.suppliers (
+    supplier_id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    contact_person VARCHAR(200),
+    phone VARCHAR(50),
+    email VARCHAR(200),
+    address TEXT,
+    tax_number VARCHAR(100),
+    notes TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_supplier_name UNIQUE (name)
+);
+
+-- Items (auto parts). Every item belongs to a category and a vehicle.
+CREATE TABLE arac.items (
+    item_id SERIAL PRIMARY KEY,
+    part_number VARCHAR(100) NOT NULL,
+    description TEXT,
+    category_id INTEGER NOT NULL REFERENCES arac.categories(category_id) ON DELETE RESTRICT,
+    buy_price DECIMAL(10,2) NOT NULL,
+    sell_price DECIMAL(10,2) NOT NULL,
+    current_stock INTEGER NOT NULL DEFAULT 0,
+    minimum_stock INTEGER NOT NULL DEFAULT 5,
+    barcode VARCHAR(100),
+    supplier_id INTEGER REFERENCES arac.suppliers(supplier_id) ON DELETE SET NULL,
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    weight_kg DECIMAL(10,3),
+    dimensions_cm VARCHAR(50), -- Format: LxWxH
+    warranty_period VARCHAR(50),
+    image_url VARCHAR(255),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    year_from INTEGER,
+    year_to INTEGER,
+    make_id INTEGER NOT NULL REFERENCES arac.makes(make_id) ON DELETE RESTRICT,
+    model_id INTEGER NOT NULL REFERENCES arac.models(model_id) ON DELETE RESTRICT,
+    submodel_id INTEGER NOT NULL REFERENCES arac.submodels(submodel_id) ON DELETE RESTRICT,
+    oem_code VARCHAR(100),
+    CONSTRAINT unique_part_number UNIQUE (part_number),
+    CONSTRAINT unique_barcode UNIQUE (barcode),
+    CONSTRAINT positive_buy_price CHECK (buy_price >= 0),
+    CONSTRAINT positive_sell_price CHECK (sell_price >= 0),
+    CONSTRAINT non_negative_stock CHECK (current_stock >= 0)
+);
+
+-- Which vehicle submodels an item fits
+CREATE TABLE arac.compatibility (
+    compat_id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE CASCADE,
+    submodel_id INTEGER NOT NULL REFERENCES arac.submodels(submodel_id) ON DELETE CASCADE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_item_submodel UNIQUE (item_id, submodel_id)
+);
+
+CREATE TABLE arac.users (
+    user_id SERIAL PRIMARY KEY,
+    username VARCHAR(50) NOT NULL UNIQUE,
+    email VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    full_name VARCHAR(100),
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Purchases, one row per delivered item
+CREATE TABLE arac.purchases (
+    purchase_id SERIAL PRIMARY KEY,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    supplier_id INTEGER NOT NULL REFERENCES arac.suppliers(supplier_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    total_cost DECIMAL(10,2) NOT NULL,
+    invoice_number VARCHAR(100),
+    received_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity CHECK (quantity > 0),
+    CONSTRAINT positive_cost_per_unit CHECK (cost_per_unit >= 0),
+    CONSTRAINT positive_total_cost CHECK (total_cost >= 0)
+);
+
+-- Sales, one row per sold item
+CREATE TABLE arac.sales (
+    sale_id SERIAL PRIMARY KEY,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    price_per_unit DECIMAL(10,2) NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL,
+    transaction_number VARCHAR(100),
+    customer_name VARCHAR(200),
+    customer_phone VARCHAR(50),
+    customer_email VARCHAR(200),
+    sold_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity CHECK (quantity > 0),
+    CONSTRAINT positive_price_per_unit CHECK (price_per_unit >= 0),
+    CONSTRAINT positive_total_price CHECK (total_price >= 0)
+);
+
+CREATE TABLE arac.stock_movements (
+    movement_id SERIAL PRIMARY KEY,
+    movement_type VARCHAR(20) NOT NULL, -- 'in' or 'out'
+    quantity INTEGER NOT NULL,
+    reference_id INTEGER,
+    reference_type VARCHAR(20),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent ON arac.categories(parent_category_id);
+CREATE INDEX idx_models_make ON arac.models(make_id);
+CREATE INDEX idx_submodels_model ON arac.submodels(model_id);
+CREATE INDEX idx_items_category ON arac.items(category_id);
+CREATE INDEX idx_items_supplier ON arac.items(supplier_id);
+CREATE INDEX idx_items_vehicle ON arac.items(make_id, model_id, submodel_id);
+CREATE INDEX idx_compatibility_item ON arac.compatibility(item_id);
+CREATE INDEX idx_compatibility_submodel ON arac.compatibility(submodel_id);
+CREATE INDEX idx_purchases_supplier ON arac.purchases(supplier_id);
+CREATE INDEX idx_purchases_item ON arac.purchases(item_id);
+CREATE INDEX idx_purchases_date ON arac.purchases(date);
+CREATE INDEX idx_sales_item ON arac.sales(item_id);
+CREATE INDEX idx_sales_date ON arac.sales(date);
+
+CREATE TRIGGER update_categories_updated_at
+    BEFORE UPDATE ON arac.categories
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_makes_updated_at
+    BEFORE UPDATE ON arac.makes
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_models_updated_at
+    BEFORE UPDATE ON arac.models
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_submodels_updated_at
+    BEFORE UPDATE ON arac.submodels
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_suppliers_updated_at
+    BEFORE UPDATE ON arac.suppliers
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_items_updated_at
+    BEFORE UPDATE ON arac.items
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON arac.users
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_purchases_updated_at
+    BEFORE UPDATE ON arac.purchases
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_sales_updated_at
+    BEFORE UPDATE ON arac.sales
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TRIGGER update_stock_movements_updated_at
+    BEFORE UPDATE ON arac.stock_movements
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- Items created without a barcode get one from their category and ID
+CREATE OR REPLACE FUNCTION arac.generate_barcode(p_category_id INTEGER, p_item_id INTEGER)
+RETURNS TEXT AS $$
+DECLARE
+    category_prefix TEXT;
+    base_code TEXT;
+    check_digit INTEGER;
+BEGIN
+    SELECT UPPER(LEFT(name, 2)) INTO category_prefix
+    FROM arac.categories
+    WHERE category_id = p_category_id;
+
+    base_code := COALESCE(category_prefix, 'XX') || '-' ||
+        LPAD(p_item_id::TEXT, 6, '0') || '-' ||
+        RIGHT(EXTRACT(YEAR FROM CURRENT_DATE)::TEXT, 2);
+
+    -- Check digit is the sum of the character codes mod 10
+    SELECT SUM(ASCII(c)) % 10 INTO check_digit
+    FROM regexp_split_to_table(base_code, '') c;
+
+    RETURN base_code || '-' || check_digit;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION arac.generate_barcode_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.barcode IS NULL OR NEW.barcode = '' THEN
+        NEW.barcode := arac.generate_barcode(NEW.category_id, NEW.item_id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_generate_barcode
+    BEFORE INSERT ON arac.items
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.generate_barcode_trigger();
@@ -0,0 +1,3 @@
+ALTER TABLE items
+DROP COLUMN IF EXISTS location_floor,
+DROP COLUMN IF EXISTS location_corridor;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS arac.idx_stock_movements_item_created;
+
+ALTER TABLE arac.stock_movements
+DROP COLUMN IF EXISTS item_id,
+DROP COLUMN IF EXISTS balance_after;
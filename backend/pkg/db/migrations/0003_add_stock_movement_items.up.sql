@@ -0,0 +1,6 @@
+ALTER TABLE arac.stock_movements
+ADD COLUMN IF NOT EXISTS item_id INTEGER REFERENCES arac.items(item_id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS balance_after INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_item_created
+ON arac.stock_movements(item_id, created_at, movement_id);
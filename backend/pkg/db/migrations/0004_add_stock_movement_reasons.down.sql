@@ -0,0 +1,2 @@
+ALTER TABLE arac.stock_movements
+DROP COLUMN IF EXISTS reason;
@@ -0,0 +1,3 @@
+ALTER TABLE arac.stock_movements
+ADD COLUMN IF NOT EXISTS reason VARCHAR(20)
+    CHECK (reason IN ('damaged', 'lost', 'found', 'count-correction'));
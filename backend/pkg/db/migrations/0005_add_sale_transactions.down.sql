@@ -0,0 +1,27 @@
+-- Header details go back onto every line. Line discounts are already part
+-- of total_price and are dropped.
+ALTER TABLE arac.sales
+DROP CONSTRAINT IF EXISTS positive_line_discount,
+ADD COLUMN IF NOT EXISTS transaction_number VARCHAR(100),
+ADD COLUMN IF NOT EXISTS customer_name VARCHAR(200),
+ADD COLUMN IF NOT EXISTS customer_phone VARCHAR(50),
+ADD COLUMN IF NOT EXISTS customer_email VARCHAR(200),
+ADD COLUMN IF NOT EXISTS sold_by VARCHAR(100);
+
+UPDATE arac.sales s
+SET transaction_number = t.transaction_number,
+    customer_name = t.customer_name,
+    customer_phone = t.customer_phone,
+    customer_email = t.customer_email,
+    sold_by = t.sold_by
+FROM arac.sale_transactions t
+WHERE s.transaction_id = t.transaction_id;
+
+DROP INDEX IF EXISTS arac.idx_sales_transaction;
+
+ALTER TABLE arac.sales
+DROP COLUMN IF EXISTS transaction_id,
+DROP COLUMN IF EXISTS line_discount;
+
+DROP TABLE IF EXISTS arac.sale_transactions;
+DROP SEQUENCE IF EXISTS arac.sale_transaction_number_seq;
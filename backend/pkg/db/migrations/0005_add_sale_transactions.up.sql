@@ -0,0 +1,55 @@
+-- Sale headers: one receipt holds one or more sale lines
+CREATE SEQUENCE IF NOT EXISTS arac.sale_transaction_number_seq;
+
+CREATE TABLE IF NOT EXISTS arac.sale_transactions (
+    transaction_id SERIAL PRIMARY KEY,
+    transaction_number VARCHAR(100) NOT NULL UNIQUE,
+    date TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    customer_name VARCHAR(200),
+    customer_phone VARCHAR(50),
+    customer_email VARCHAR(200),
+    sold_by VARCHAR(100),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_sale_transactions_updated_at
+    BEFORE UPDATE ON arac.sale_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+ALTER TABLE arac.sales
+ADD COLUMN IF NOT EXISTS transaction_id INTEGER REFERENCES arac.sale_transactions(transaction_id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS line_discount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+-- Existing rows sharing a transaction number become one receipt, rows
+-- without one get a receipt of their own
+INSERT INTO arac.sale_transactions (
+    transaction_number, date, customer_name, customer_phone, customer_email, sold_by
+)
+SELECT DISTINCT ON (number)
+    number, date, customer_name, customer_phone, customer_email, sold_by
+FROM (
+    SELECT COALESCE(NULLIF(transaction_number, ''), 'LEGACY-' || sale_id) AS number, *
+    FROM arac.sales
+) legacy
+ORDER BY number, date
+ON CONFLICT (transaction_number) DO NOTHING;
+
+UPDATE arac.sales s
+SET transaction_id = t.transaction_id
+FROM arac.sale_transactions t
+WHERE t.transaction_number = COALESCE(NULLIF(s.transaction_number, ''), 'LEGACY-' || s.sale_id)
+  AND s.transaction_id IS NULL;
+
+ALTER TABLE arac.sales
+ALTER COLUMN transaction_id SET NOT NULL,
+DROP COLUMN IF EXISTS transaction_number,
+DROP COLUMN IF EXISTS customer_name,
+DROP COLUMN IF EXISTS customer_phone,
+DROP COLUMN IF EXISTS customer_email,
+DROP COLUMN IF EXISTS sold_by,
+ADD CONSTRAINT positive_line_discount CHECK (line_discount >= 0 AND line_discount <= quantity * price_per_unit);
+
+CREATE INDEX IF NOT EXISTS idx_sales_transaction ON arac.sales(transaction_id);
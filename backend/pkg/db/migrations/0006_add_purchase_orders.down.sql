@@ -0,0 +1,31 @@
+-- Every received line becomes a purchase of its own, as purchases were
+-- before orders existed. Quantities still outstanding are dropped.
+ALTER TABLE arac.purchases
+ADD COLUMN IF NOT EXISTS item_id INTEGER REFERENCES arac.items(item_id) ON DELETE RESTRICT,
+ADD COLUMN IF NOT EXISTS quantity INTEGER,
+ADD COLUMN IF NOT EXISTS cost_per_unit DECIMAL(10,2),
+ADD COLUMN IF NOT EXISTS total_cost DECIMAL(10,2);
+
+INSERT INTO arac.purchases (
+    date, supplier_id, invoice_number, received_by, notes, created_at, updated_at,
+    item_id, quantity, cost_per_unit, total_cost
+)
+SELECT
+    p.date, p.supplier_id, p.invoice_number, p.received_by, p.notes, p.created_at, p.updated_at,
+    l.item_id, l.quantity_received, l.cost_per_unit, l.quantity_received * l.cost_per_unit
+FROM arac.purchases p
+JOIN arac.purchase_lines l ON l.purchase_id = p.purchase_id
+WHERE l.quantity_received > 0
+ORDER BY l.line_id;
+
+DELETE FROM arac.purchases WHERE item_id IS NULL;
+
+DROP TABLE IF EXISTS arac.purchase_lines;
+
+ALTER TABLE arac.purchases
+ALTER COLUMN item_id SET NOT NULL,
+ALTER COLUMN quantity SET NOT NULL,
+ALTER COLUMN cost_per_unit SET NOT NULL,
+ALTER COLUMN total_cost SET NOT NULL,
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS expected_date;
@@ -0,0 +1,46 @@
+-- Purchases become purchase order headers with one or more lines
+ALTER TABLE arac.purchases
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'draft'
+    CHECK (status IN ('draft', 'ordered', 'partially-received', 'received', 'cancelled')),
+ADD COLUMN IF NOT EXISTS expected_date TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS arac.purchase_lines (
+    line_id SERIAL PRIMARY KEY,
+    purchase_id INTEGER NOT NULL REFERENCES arac.purchases(purchase_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE RESTRICT,
+    quantity_ordered INTEGER NOT NULL,
+    quantity_received INTEGER NOT NULL DEFAULT 0,
+    cost_per_unit DECIMAL(10,2) NOT NULL,
+    total_cost DECIMAL(10,2) NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_quantity_ordered CHECK (quantity_ordered > 0),
+    CONSTRAINT valid_quantity_received CHECK (quantity_received >= 0 AND quantity_received <= quantity_ordered),
+    CONSTRAINT positive_cost_per_unit CHECK (cost_per_unit >= 0),
+    CONSTRAINT positive_total_cost CHECK (total_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_lines_purchase ON arac.purchase_lines(purchase_id);
+CREATE INDEX IF NOT EXISTS idx_purchase_lines_item ON arac.purchase_lines(item_id);
+
+CREATE TRIGGER update_purchase_lines_updated_at
+    BEFORE UPDATE ON arac.purchase_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- Every existing purchase was received when it was recorded
+INSERT INTO arac.purchase_lines (
+    purchase_id, item_id, quantity_ordered, quantity_received,
+    cost_per_unit, total_cost, created_at, updated_at
+)
+SELECT purchase_id, item_id, quantity, quantity, cost_per_unit, total_cost, created_at, updated_at
+FROM arac.purchases;
+
+UPDATE arac.purchases SET status = 'received';
+
+ALTER TABLE arac.purchases
+DROP COLUMN IF EXISTS item_id,
+DROP COLUMN IF EXISTS quantity,
+DROP COLUMN IF EXISTS cost_per_unit,
+DROP COLUMN IF EXISTS total_cost;
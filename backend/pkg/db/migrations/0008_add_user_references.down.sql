@@ -0,0 +1,16 @@
+-- Names go back into the text columns before the user references are dropped
+UPDATE arac.sale_transactions t
+SET sold_by = COALESCE(u.full_name, u.username)
+FROM arac.users u
+WHERE t.sold_by_user_id = u.user_id;
+
+UPDATE arac.purchases p
+SET received_by = COALESCE(u.full_name, u.username)
+FROM arac.users u
+WHERE p.received_by_user_id = u.user_id;
+
+DROP INDEX IF EXISTS arac.idx_sale_transactions_sold_by;
+DROP INDEX IF EXISTS arac.idx_purchases_received_by;
+
+ALTER TABLE arac.sale_transactions DROP COLUMN IF EXISTS sold_by_user_id;
+ALTER TABLE arac.purchases DROP COLUMN IF EXISTS received_by_user_id;
@@ -0,0 +1,24 @@
+-- Sellers and receivers are recorded as users instead of free text. The old
+-- text columns are kept only for rows that could not be matched to a user.
+ALTER TABLE arac.sale_transactions
+ADD COLUMN IF NOT EXISTS sold_by_user_id INTEGER REFERENCES arac.users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE arac.purchases
+ADD COLUMN IF NOT EXISTS received_by_user_id INTEGER REFERENCES arac.users(user_id) ON DELETE SET NULL;
+
+UPDATE arac.sale_transactions t
+SET sold_by_user_id = u.user_id, sold_by = NULL
+FROM arac.users u
+WHERE t.sold_by_user_id IS NULL
+  AND t.sold_by IS NOT NULL
+  AND (lower(t.sold_by) = lower(u.username) OR lower(t.sold_by) = lower(u.full_name));
+
+UPDATE arac.purchases p
+SET received_by_user_id = u.user_id, received_by = NULL
+FROM arac.users u
+WHERE p.received_by_user_id IS NULL
+  AND p.received_by IS NOT NULL
+  AND (lower(p.received_by) = lower(u.username) OR lower(p.received_by) = lower(u.full_name));
+
+CREATE INDEX IF NOT EXISTS idx_sale_transactions_sold_by ON arac.sale_transactions(sold_by_user_id);
+CREATE INDEX IF NOT EXISTS idx_purchases_received_by ON arac.purchases(received_by_user_id);
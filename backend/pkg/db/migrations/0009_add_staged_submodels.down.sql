@@ -0,0 +1 @@
+DROP TABLE IF EXISTS arac.staged_submodels;
@@ -0,0 +1,29 @@
+-- Submodels read from scraped listings wait here until someone reviews them
+CREATE TABLE IF NOT EXISTS arac.staged_submodels (
+    staged_id SERIAL PRIMARY KEY,
+    source_slug VARCHAR(200) NOT NULL,
+    make_name VARCHAR(100) NOT NULL,
+    model_name VARCHAR(100) NOT NULL,
+    submodel_name VARCHAR(100) NOT NULL,
+    year_from INTEGER,
+    year_to INTEGER,
+    engine_type VARCHAR(100),
+    engine_displacement DECIMAL(3,1),
+    fuel_type VARCHAR(50),
+    transmission_type VARCHAR(50),
+    body_type VARCHAR(100),
+    listing_count INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    submodel_id INTEGER REFERENCES arac.submodels(submodel_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_staged_submodel_slug UNIQUE (source_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staged_submodels_status ON arac.staged_submodels(status);
+
+CREATE TRIGGER update_staged_submodels_updated_at
+    BEFORE UPDATE ON arac.staged_submodels
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS arac.idx_items_oem_code_code;
+DROP INDEX IF EXISTS arac.idx_items_part_number_code;
+
+DROP FUNCTION IF EXISTS arac.normalize_code(TEXT);
+DROP FUNCTION IF EXISTS arac.search_fold(TEXT);
@@ -0,0 +1,22 @@
+-- Ranked, typo-tolerant item search. search_fold and normalize_code must
+-- fold text the same way as the search package does in Go.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Lower-cases text the Turkish way and drops the marks of Turkish letters
+CREATE OR REPLACE FUNCTION arac.search_fold(value TEXT)
+RETURNS TEXT AS $$
+    SELECT translate(
+        lower(translate(coalesce(value, ''), 'İIŞĞÜÖÇÂÎÛ', 'iisguocaiu')),
+        'ışğüöçâîû',
+        'isguocaiu'
+    )
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+-- Reduces a part number or OEM code to its letters and digits in upper case
+CREATE OR REPLACE FUNCTION arac.normalize_code(value TEXT)
+RETURNS TEXT AS $$
+    SELECT upper(regexp_replace(arac.search_fold(value), '[^a-z0-9]', '', 'g'))
+$$ LANGUAGE SQL IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_items_part_number_code ON arac.items (arac.normalize_code(part_number));
+CREATE INDEX IF NOT EXISTS idx_items_oem_code_code ON arac.items (arac.normalize_code(oem_code));
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS arac.item_cross_references;
@@ -0,0 +1,32 @@
+-- Interchange numbers of an item: the OEM number, OE supplier numbers and
+-- aftermarket equivalents. Numbers are matched without spaces and dashes.
+CREATE TABLE IF NOT EXISTS arac.item_cross_references (
+    reference_id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE CASCADE,
+    brand VARCHAR(100) NOT NULL,
+    reference_number VARCHAR(100) NOT NULL,
+    normalized_number VARCHAR(100) GENERATED ALWAYS AS (arac.normalize_code(reference_number)) STORED,
+    reference_type VARCHAR(20) NOT NULL
+        CHECK (reference_type IN ('oem', 'oe-supplier', 'aftermarket')),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT non_empty_reference_number CHECK (arac.normalize_code(reference_number) <> '')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_cross_references_unique
+    ON arac.item_cross_references(item_id, arac.search_fold(brand), normalized_number);
+CREATE INDEX IF NOT EXISTS idx_item_cross_references_number ON arac.item_cross_references(normalized_number);
+
+CREATE TRIGGER update_item_cross_references_updated_at
+    BEFORE UPDATE ON arac.item_cross_references
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- Existing OEM codes become OEM references under the make of the item
+INSERT INTO arac.item_cross_references (item_id, brand, reference_number, reference_type)
+SELECT i.item_id, m.make_name, i.oem_code, 'oem'
+FROM arac.items i
+JOIN arac.makes m ON i.make_id = m.make_id
+WHERE arac.normalize_code(i.oem_code) <> ''
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,8 @@
+DROP VIEW IF EXISTS arac.rolled_up_stock;
+DROP FUNCTION IF EXISTS arac.current_item_id(INTEGER);
+DROP INDEX IF EXISTS arac.idx_items_superseded_by;
+
+ALTER TABLE arac.items
+    DROP CONSTRAINT IF EXISTS not_superseded_by_itself,
+    DROP COLUMN IF EXISTS superseded_on,
+    DROP COLUMN IF EXISTS superseded_by;
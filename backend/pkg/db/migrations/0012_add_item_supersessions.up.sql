@@ -0,0 +1,33 @@
+-- An item can be superseded by the part that replaces it from a given date.
+-- Deleting the successor leaves the old item unlinked.
+ALTER TABLE arac.items
+    ADD COLUMN IF NOT EXISTS superseded_by INTEGER REFERENCES arac.items(item_id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS superseded_on DATE,
+    ADD CONSTRAINT not_superseded_by_itself CHECK (superseded_by <> item_id);
+
+CREATE INDEX IF NOT EXISTS idx_items_superseded_by ON arac.items(superseded_by);
+
+-- Follows the supersessions in effect today from an item to the part that
+-- currently replaces it. An item that is not superseded is its own current
+-- part, and a chain that loops back stops before repeating an item.
+CREATE OR REPLACE FUNCTION arac.current_item_id(p_item_id INTEGER)
+RETURNS INTEGER AS $$
+    WITH RECURSIVE chain(item_id, depth, path) AS (
+        SELECT p_item_id, 0, ARRAY[p_item_id]
+        UNION ALL
+        SELECT i.superseded_by, chain.depth + 1, chain.path || i.superseded_by
+        FROM chain
+        JOIN arac.items i ON i.item_id = chain.item_id
+        WHERE i.superseded_by IS NOT NULL
+            AND i.superseded_on <= CURRENT_DATE
+            AND NOT i.superseded_by = ANY(chain.path)
+    )
+    SELECT item_id FROM chain ORDER BY depth DESC LIMIT 1
+$$ LANGUAGE sql STABLE;
+
+-- Stock of every current part, counting what is still on the shelf under
+-- the numbers it superseded
+CREATE OR REPLACE VIEW arac.rolled_up_stock AS
+SELECT arac.current_item_id(item_id) AS item_id, SUM(current_stock)::INTEGER AS stock
+FROM arac.items
+GROUP BY 1;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS arac.vin_manufacturers;
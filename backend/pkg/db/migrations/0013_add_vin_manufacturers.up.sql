@@ -0,0 +1,83 @@
+-- World manufacturer identifiers, the first three characters of a VIN, and
+-- the make they belong to. The list is maintained locally.
+CREATE TABLE IF NOT EXISTS arac.vin_manufacturers (
+    wmi VARCHAR(3) PRIMARY KEY,
+    make_id INTEGER NOT NULL REFERENCES arac.makes(make_id) ON DELETE CASCADE,
+    manufacturer VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_wmi CHECK (wmi ~ '^[A-HJ-NPR-Z0-9]{3}$')
+);
+
+CREATE INDEX IF NOT EXISTS idx_vin_manufacturers_make ON arac.vin_manufacturers(make_id);
+
+CREATE TRIGGER update_vin_manufacturers_updated_at
+    BEFORE UPDATE ON arac.vin_manufacturers
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- Common identifiers for makes already in the catalog, matched by name
+INSERT INTO arac.vin_manufacturers (wmi, make_id, manufacturer)
+SELECT DISTINCT ON (w.wmi) w.wmi, m.make_id, w.manufacturer
+FROM (VALUES
+    ('WVW', 'volkswagen', 'Volkswagen'),
+    ('WV1', 'volkswagen', 'Volkswagen Commercial Vehicles'),
+    ('WV2', 'volkswagen', 'Volkswagen Commercial Vehicles'),
+    ('WAU', 'audi', 'Audi'),
+    ('WUA', 'audi', 'Audi Sport'),
+    ('WBA', 'bmw', 'BMW'),
+    ('WBS', 'bmw', 'BMW M'),
+    ('WMW', 'mini', 'MINI'),
+    ('WDB', 'mercedes-benz', 'Mercedes-Benz'),
+    ('WDB', 'mercedes', 'Mercedes-Benz'),
+    ('WDD', 'mercedes-benz', 'Mercedes-Benz'),
+    ('WDD', 'mercedes', 'Mercedes-Benz'),
+    ('W1K', 'mercedes-benz', 'Mercedes-Benz'),
+    ('W1K', 'mercedes', 'Mercedes-Benz'),
+    ('WP0', 'porsche', 'Porsche'),
+    ('WF0', 'ford', 'Ford Germany'),
+    ('NM0', 'ford', 'Ford Otosan'),
+    ('1FA', 'ford', 'Ford USA'),
+    ('W0L', 'opel', 'Opel'),
+    ('W0V', 'opel', 'Opel'),
+    ('VF1', 'renault', 'Renault'),
+    ('VF3', 'peugeot', 'Peugeot'),
+    ('VR3', 'peugeot', 'Peugeot'),
+    ('VF7', 'citroen', 'Citroen'),
+    ('VR7', 'citroen', 'Citroen'),
+    ('UU1', 'dacia', 'Dacia'),
+    ('ZFA', 'fiat', 'Fiat'),
+    ('NM4', 'fiat', 'Tofas'),
+    ('ZAR', 'alfa romeo', 'Alfa Romeo'),
+    ('TMB', 'skoda', 'Skoda'),
+    ('VSS', 'seat', 'SEAT'),
+    ('NMT', 'toyota', 'Toyota Turkey'),
+    ('JTD', 'toyota', 'Toyota'),
+    ('SB1', 'toyota', 'Toyota UK'),
+    ('VNK', 'toyota', 'Toyota France'),
+    ('JHM', 'honda', 'Honda'),
+    ('NLA', 'honda', 'Honda Turkey'),
+    ('NLH', 'hyundai', 'Hyundai Assan'),
+    ('KMH', 'hyundai', 'Hyundai'),
+    ('TMA', 'hyundai', 'Hyundai Czech'),
+    ('KNA', 'kia', 'Kia'),
+    ('U5Y', 'kia', 'Kia Slovakia'),
+    ('JN1', 'nissan', 'Nissan'),
+    ('SJN', 'nissan', 'Nissan UK'),
+    ('VSK', 'nissan', 'Nissan Spain'),
+    ('JMZ', 'mazda', 'Mazda'),
+    ('JSA', 'suzuki', 'Suzuki'),
+    ('TSM', 'suzuki', 'Suzuki Hungary'),
+    ('JMB', 'mitsubishi', 'Mitsubishi'),
+    ('YV1', 'volvo', 'Volvo'),
+    ('SAL', 'land rover', 'Land Rover'),
+    ('SAJ', 'jaguar', 'Jaguar'),
+    ('ZAM', 'maserati', 'Maserati'),
+    ('5YJ', 'tesla', 'Tesla'),
+    ('LRW', 'tesla', 'Tesla China'),
+    ('JF1', 'subaru', 'Subaru'),
+    ('1C4', 'jeep', 'Jeep')
+) AS w(wmi, make_name, manufacturer)
+JOIN arac.makes m ON arac.search_fold(m.make_name) = w.make_name
+ORDER BY w.wmi, m.make_id
+ON CONFLICT (wmi) DO NOTHING;
@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION arac.generate_barcode_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.barcode IS NULL OR NEW.barcode = '' THEN
+        NEW.barcode := arac.generate_barcode(NEW.category_id, NEW.item_id);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS arac.ean13_check_digit(TEXT);
+DROP SEQUENCE IF EXISTS arac.item_barcode_seq;
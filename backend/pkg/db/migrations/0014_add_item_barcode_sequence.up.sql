@@ -0,0 +1,34 @@
+-- Item numbers for the barcodes we issue. The API and the insert trigger
+-- share it, so the same number is never given out twice.
+CREATE SEQUENCE IF NOT EXISTS arac.item_barcode_seq;
+
+-- Check digit of the first 12 digits of an EAN-13
+CREATE OR REPLACE FUNCTION arac.ean13_check_digit(digits TEXT)
+RETURNS INTEGER AS $$
+    SELECT (10 - SUM(substr(digits, i, 1)::INTEGER * CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END)::INTEGER % 10) % 10
+    FROM generate_series(1, 12) i;
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Items created without a barcode get an internal EAN-13 (prefix 20) with
+-- the next item number no other item's barcode already uses
+CREATE OR REPLACE FUNCTION arac.generate_barcode_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    base_code TEXT;
+    candidate TEXT;
+BEGIN
+    IF NEW.barcode IS NULL OR NEW.barcode = '' THEN
+        LOOP
+            base_code := '20' || LPAD(nextval('arac.item_barcode_seq')::TEXT, 10, '0');
+            IF length(base_code) > 12 THEN
+                RAISE EXCEPTION 'barcode sequence has run out of item numbers';
+            END IF;
+
+            candidate := base_code || arac.ean13_check_digit(base_code);
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM arac.items WHERE barcode = candidate);
+        END LOOP;
+        NEW.barcode := candidate;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
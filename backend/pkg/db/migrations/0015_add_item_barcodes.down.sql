@@ -0,0 +1,25 @@
+CREATE OR REPLACE FUNCTION arac.generate_barcode_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    base_code TEXT;
+    candidate TEXT;
+BEGIN
+    IF NEW.barcode IS NULL OR NEW.barcode = '' THEN
+        LOOP
+            base_code := '20' || LPAD(nextval('arac.item_barcode_seq')::TEXT, 10, '0');
+            IF length(base_code) > 12 THEN
+                RAISE EXCEPTION 'barcode sequence has run out of item numbers';
+            END IF;
+
+            candidate := base_code || arac.ean13_check_digit(base_code);
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM arac.items WHERE barcode = candidate);
+        END LOOP;
+        NEW.barcode := candidate;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_sync_item_barcode ON arac.items;
+DROP FUNCTION IF EXISTS arac.sync_item_barcode();
+DROP TABLE IF EXISTS arac.item_barcodes;
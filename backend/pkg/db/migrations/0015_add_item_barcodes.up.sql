@@ -0,0 +1,81 @@
+-- Every barcode an item can be scanned by: its own label, manufacturer EANs
+-- of the brands it is bought in and supplier codes. A barcode printed on a
+-- multi-pack stands for pack_quantity units.
+CREATE TABLE IF NOT EXISTS arac.item_barcodes (
+    barcode_id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE CASCADE,
+    barcode VARCHAR(100) NOT NULL,
+    barcode_type VARCHAR(20) NOT NULL DEFAULT 'manufacturer',
+    pack_quantity INTEGER NOT NULL DEFAULT 1,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_item_barcode UNIQUE (barcode),
+    CONSTRAINT valid_barcode_type CHECK (barcode_type IN ('internal', 'manufacturer', 'supplier')),
+    CONSTRAINT positive_pack_quantity CHECK (pack_quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_barcodes_item ON arac.item_barcodes(item_id);
+
+CREATE TRIGGER update_item_barcodes_updated_at
+    BEFORE UPDATE ON arac.item_barcodes
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+INSERT INTO arac.item_barcodes (item_id, barcode, barcode_type)
+SELECT item_id, barcode, 'internal'
+FROM arac.items
+WHERE barcode IS NOT NULL AND barcode <> ''
+ON CONFLICT (barcode) DO NOTHING;
+
+-- An item's own barcode is kept among its barcodes, so one table answers
+-- every scan and no barcode can belong to two items
+CREATE OR REPLACE FUNCTION arac.sync_item_barcode()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.barcode IS DISTINCT FROM NEW.barcode THEN
+        DELETE FROM arac.item_barcodes WHERE item_id = OLD.item_id AND barcode = OLD.barcode;
+    END IF;
+
+    IF NEW.barcode IS NOT NULL AND NEW.barcode <> '' THEN
+        IF EXISTS (SELECT 1 FROM arac.item_barcodes WHERE barcode = NEW.barcode AND item_id <> NEW.item_id) THEN
+            RAISE EXCEPTION 'barcode % already belongs to another item', NEW.barcode
+                USING ERRCODE = 'unique_violation';
+        END IF;
+
+        INSERT INTO arac.item_barcodes (item_id, barcode, barcode_type)
+        VALUES (NEW.item_id, NEW.barcode, 'internal')
+        ON CONFLICT (barcode) DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_sync_item_barcode
+    AFTER INSERT OR UPDATE OF barcode ON arac.items
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.sync_item_barcode();
+
+-- Generated barcodes must not clash with any barcode, not only the items'
+-- own ones
+CREATE OR REPLACE FUNCTION arac.generate_barcode_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    base_code TEXT;
+    candidate TEXT;
+BEGIN
+    IF NEW.barcode IS NULL OR NEW.barcode = '' THEN
+        LOOP
+            base_code := '20' || LPAD(nextval('arac.item_barcode_seq')::TEXT, 10, '0');
+            IF length(base_code) > 12 THEN
+                RAISE EXCEPTION 'barcode sequence has run out of item numbers';
+            END IF;
+
+            candidate := base_code || arac.ean13_check_digit(base_code);
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM arac.item_barcodes WHERE barcode = candidate);
+        END LOOP;
+        NEW.barcode := candidate;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS arac.stock_transfer_lines;
+DROP TABLE IF EXISTS arac.stock_transfers;
+DROP SEQUENCE IF EXISTS arac.stock_transfer_number_seq;
+
+DROP INDEX IF EXISTS arac.idx_purchases_warehouse;
+DROP INDEX IF EXISTS arac.idx_sale_transactions_warehouse;
+DROP INDEX IF EXISTS arac.idx_stock_movements_warehouse;
+
+ALTER TABLE arac.purchases DROP COLUMN IF EXISTS warehouse_id;
+ALTER TABLE arac.sale_transactions DROP COLUMN IF EXISTS warehouse_id;
+ALTER TABLE arac.stock_movements DROP COLUMN IF EXISTS warehouse_id;
+
+DROP VIEW IF EXISTS arac.rolled_up_warehouse_stock;
+
+DROP TRIGGER IF EXISTS trg_stock_new_item ON arac.items;
+DROP FUNCTION IF EXISTS arac.stock_new_item();
+
+DROP TABLE IF EXISTS arac.warehouse_stock;
+DROP TABLE IF EXISTS arac.warehouses;
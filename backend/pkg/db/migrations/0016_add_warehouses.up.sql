@@ -0,0 +1,165 @@
+-- Shops and warehouses that hold stock. Exactly one is the default, which
+-- takes the stock of anything that does not name a warehouse.
+CREATE TABLE IF NOT EXISTS arac.warehouses (
+    warehouse_id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    warehouse_type VARCHAR(20) NOT NULL DEFAULT 'shop',
+    address TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT unique_warehouse_code UNIQUE (code),
+    CONSTRAINT valid_warehouse_type CHECK (warehouse_type IN ('shop', 'warehouse'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_warehouses_one_default ON arac.warehouses(is_default) WHERE is_default;
+
+CREATE TRIGGER update_warehouses_updated_at
+    BEFORE UPDATE ON arac.warehouses
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+INSERT INTO arac.warehouses (code, name, warehouse_type, is_default)
+VALUES ('MAIN', 'Main shop', 'shop', true);
+
+-- Stock of an item in one warehouse, with where it sits there. A minimum
+-- left empty falls back to the item's. items.current_stock is kept as the
+-- sum over all warehouses.
+CREATE TABLE IF NOT EXISTS arac.warehouse_stock (
+    warehouse_id INTEGER NOT NULL REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    minimum_stock INTEGER,
+    location_floor VARCHAR(50),
+    location_corridor VARCHAR(50),
+    location_aisle VARCHAR(50),
+    location_shelf VARCHAR(50),
+    location_bin VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (warehouse_id, item_id),
+    CONSTRAINT non_negative_warehouse_stock CHECK (quantity >= 0),
+    CONSTRAINT non_negative_warehouse_minimum CHECK (minimum_stock >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_item ON arac.warehouse_stock(item_id);
+
+CREATE TRIGGER update_warehouse_stock_updated_at
+    BEFORE UPDATE ON arac.warehouse_stock
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- Everything in stock so far is in the default warehouse
+INSERT INTO arac.warehouse_stock (
+    warehouse_id, item_id, quantity,
+    location_floor, location_corridor, location_aisle, location_shelf, location_bin
+)
+SELECT w.warehouse_id, i.item_id, i.current_stock,
+    i.location_floor, i.location_corridor, i.location_aisle, i.location_shelf, i.location_bin
+FROM arac.items i
+CROSS JOIN arac.warehouses w
+WHERE w.is_default;
+
+-- The opening stock of a new item goes into the default warehouse
+CREATE OR REPLACE FUNCTION arac.stock_new_item()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO arac.warehouse_stock (
+        warehouse_id, item_id, quantity,
+        location_floor, location_corridor, location_aisle, location_shelf, location_bin
+    )
+    SELECT warehouse_id, NEW.item_id, NEW.current_stock,
+        NEW.location_floor, NEW.location_corridor, NEW.location_aisle, NEW.location_shelf, NEW.location_bin
+    FROM arac.warehouses
+    WHERE is_default;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_stock_new_item
+    AFTER INSERT ON arac.items
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.stock_new_item();
+
+-- Stock of each warehouse counted under the item that supersedes it, as
+-- arac.rolled_up_stock does over all warehouses
+CREATE OR REPLACE VIEW arac.rolled_up_warehouse_stock AS
+SELECT warehouse_id, arac.current_item_id(item_id) AS item_id, SUM(quantity)::INTEGER AS stock
+FROM arac.warehouse_stock
+GROUP BY 1, 2;
+
+-- Every movement, sale and purchase happens in a warehouse
+ALTER TABLE arac.stock_movements
+ADD COLUMN IF NOT EXISTS warehouse_id INTEGER REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT;
+
+ALTER TABLE arac.sale_transactions
+ADD COLUMN IF NOT EXISTS warehouse_id INTEGER REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT;
+
+ALTER TABLE arac.purchases
+ADD COLUMN IF NOT EXISTS warehouse_id INTEGER REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT;
+
+UPDATE arac.stock_movements SET warehouse_id = (SELECT warehouse_id FROM arac.warehouses WHERE is_default);
+UPDATE arac.sale_transactions SET warehouse_id = (SELECT warehouse_id FROM arac.warehouses WHERE is_default);
+UPDATE arac.purchases SET warehouse_id = (SELECT warehouse_id FROM arac.warehouses WHERE is_default);
+
+ALTER TABLE arac.stock_movements ALTER COLUMN warehouse_id SET NOT NULL;
+ALTER TABLE arac.sale_transactions ALTER COLUMN warehouse_id SET NOT NULL;
+ALTER TABLE arac.purchases ALTER COLUMN warehouse_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse ON arac.stock_movements(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_sale_transactions_warehouse ON arac.sale_transactions(warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_purchases_warehouse ON arac.purchases(warehouse_id);
+
+-- Transfers move stock between warehouses. Shipping takes it out of the
+-- source and receiving puts it into the destination; in between it is in
+-- transit and on neither shelf.
+CREATE SEQUENCE IF NOT EXISTS arac.stock_transfer_number_seq;
+
+CREATE TABLE IF NOT EXISTS arac.stock_transfers (
+    transfer_id SERIAL PRIMARY KEY,
+    transfer_number VARCHAR(100) NOT NULL UNIQUE,
+    from_warehouse_id INTEGER NOT NULL REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT,
+    to_warehouse_id INTEGER NOT NULL REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'in-transit', 'received', 'cancelled')),
+    created_by_user_id INTEGER REFERENCES arac.users(user_id) ON DELETE SET NULL,
+    shipped_by_user_id INTEGER REFERENCES arac.users(user_id) ON DELETE SET NULL,
+    received_by_user_id INTEGER REFERENCES arac.users(user_id) ON DELETE SET NULL,
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    received_at TIMESTAMP WITH TIME ZONE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT different_warehouses CHECK (from_warehouse_id <> to_warehouse_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_from ON arac.stock_transfers(from_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_to ON arac.stock_transfers(to_warehouse_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_status ON arac.stock_transfers(status);
+
+CREATE TRIGGER update_stock_transfers_updated_at
+    BEFORE UPDATE ON arac.stock_transfers
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS arac.stock_transfer_lines (
+    line_id SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL REFERENCES arac.stock_transfers(transfer_id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES arac.items(item_id) ON DELETE RESTRICT,
+    quantity INTEGER NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_transfer_quantity CHECK (quantity > 0),
+    CONSTRAINT unique_transfer_item UNIQUE (transfer_id, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_lines_item ON arac.stock_transfer_lines(item_id);
+
+CREATE TRIGGER update_stock_transfer_lines_updated_at
+    BEFORE UPDATE ON arac.stock_transfer_lines
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
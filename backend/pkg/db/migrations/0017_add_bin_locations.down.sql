@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS arac.idx_warehouse_stock_bin;
+ALTER TABLE arac.warehouse_stock DROP COLUMN IF EXISTS bin_id;
+
+DROP VIEW IF EXISTS arac.bin_location_paths;
+DROP TABLE IF EXISTS arac.bin_locations;
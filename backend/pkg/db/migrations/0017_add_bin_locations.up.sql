@@ -0,0 +1,116 @@
+-- Storage locations of a warehouse, from floors down through corridors,
+-- aisles and shelves to the bins items are kept in. Capacity is in units
+-- and left empty when a location has no limit. Locations under the same
+-- parent are walked in sort_order, then by code.
+CREATE TABLE IF NOT EXISTS arac.bin_locations (
+    location_id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES arac.warehouses(warehouse_id) ON DELETE RESTRICT,
+    parent_id INTEGER REFERENCES arac.bin_locations(location_id) ON DELETE RESTRICT,
+    level VARCHAR(20) NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    capacity INTEGER,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_location_level CHECK (level IN ('floor', 'corridor', 'aisle', 'shelf', 'bin')),
+    CONSTRAINT floors_have_no_parent CHECK ((level = 'floor') = (parent_id IS NULL)),
+    CONSTRAINT non_negative_capacity CHECK (capacity >= 0),
+    CONSTRAINT non_negative_sort_order CHECK (sort_order >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bin_locations_code
+    ON arac.bin_locations(warehouse_id, COALESCE(parent_id, 0), code);
+CREATE INDEX IF NOT EXISTS idx_bin_locations_parent ON arac.bin_locations(parent_id);
+
+CREATE TRIGGER update_bin_locations_updated_at
+    BEFORE UPDATE ON arac.bin_locations
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.update_updated_at_column();
+
+-- The path of every location from its floor down. walk_key sorts
+-- locations in the order they are walked past.
+CREATE OR REPLACE VIEW arac.bin_location_paths AS
+WITH RECURSIVE paths AS (
+    SELECT location_id, warehouse_id, level,
+        ARRAY[location_id] AS ids,
+        ARRAY[code::TEXT] AS codes,
+        ARRAY[lpad(sort_order::TEXT, 6, '0') || code] AS walk_key
+    FROM arac.bin_locations
+    WHERE parent_id IS NULL
+    UNION ALL
+    SELECT l.location_id, l.warehouse_id, l.level,
+        p.ids || l.location_id,
+        p.codes || l.code::TEXT,
+        p.walk_key || (lpad(l.sort_order::TEXT, 6, '0') || l.code)
+    FROM arac.bin_locations l
+    JOIN paths p ON p.location_id = l.parent_id
+)
+SELECT location_id, warehouse_id, level, ids, codes, walk_key,
+    array_to_string(codes, '-') AS label
+FROM paths;
+
+-- The bin an item is kept in at a warehouse
+ALTER TABLE arac.warehouse_stock
+ADD COLUMN IF NOT EXISTS bin_id INTEGER REFERENCES arac.bin_locations(location_id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_bin ON arac.warehouse_stock(bin_id);
+
+-- Turn the free-text locations that name all five levels into bins, one
+-- level at a time so each finds its parent
+INSERT INTO arac.bin_locations (warehouse_id, level, code)
+SELECT DISTINCT warehouse_id, 'floor', TRIM(location_floor)
+FROM arac.warehouse_stock
+WHERE NULLIF(TRIM(location_floor), '') IS NOT NULL
+    AND NULLIF(TRIM(location_corridor), '') IS NOT NULL
+    AND NULLIF(TRIM(location_aisle), '') IS NOT NULL
+    AND NULLIF(TRIM(location_shelf), '') IS NOT NULL
+    AND NULLIF(TRIM(location_bin), '') IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO arac.bin_locations (warehouse_id, parent_id, level, code)
+SELECT DISTINCT ws.warehouse_id, p.location_id, 'corridor', TRIM(ws.location_corridor)
+FROM arac.warehouse_stock ws
+JOIN arac.bin_location_paths p ON p.warehouse_id = ws.warehouse_id
+    AND p.codes = ARRAY[TRIM(ws.location_floor)]
+WHERE NULLIF(TRIM(ws.location_corridor), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_aisle), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_shelf), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_bin), '') IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO arac.bin_locations (warehouse_id, parent_id, level, code)
+SELECT DISTINCT ws.warehouse_id, p.location_id, 'aisle', TRIM(ws.location_aisle)
+FROM arac.warehouse_stock ws
+JOIN arac.bin_location_paths p ON p.warehouse_id = ws.warehouse_id
+    AND p.codes = ARRAY[TRIM(ws.location_floor), TRIM(ws.location_corridor)]
+WHERE NULLIF(TRIM(ws.location_aisle), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_shelf), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_bin), '') IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO arac.bin_locations (warehouse_id, parent_id, level, code)
+SELECT DISTINCT ws.warehouse_id, p.location_id, 'shelf', TRIM(ws.location_shelf)
+FROM arac.warehouse_stock ws
+JOIN arac.bin_location_paths p ON p.warehouse_id = ws.warehouse_id
+    AND p.codes = ARRAY[TRIM(ws.location_floor), TRIM(ws.location_corridor), TRIM(ws.location_aisle)]
+WHERE NULLIF(TRIM(ws.location_shelf), '') IS NOT NULL
+    AND NULLIF(TRIM(ws.location_bin), '') IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO arac.bin_locations (warehouse_id, parent_id, level, code)
+SELECT DISTINCT ws.warehouse_id, p.location_id, 'bin', TRIM(ws.location_bin)
+FROM arac.warehouse_stock ws
+JOIN arac.bin_location_paths p ON p.warehouse_id = ws.warehouse_id
+    AND p.codes = ARRAY[TRIM(ws.location_floor), TRIM(ws.location_corridor), TRIM(ws.location_aisle), TRIM(ws.location_shelf)]
+WHERE NULLIF(TRIM(ws.location_bin), '') IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE arac.warehouse_stock ws
+SET bin_id = p.location_id
+FROM arac.bin_location_paths p
+WHERE p.warehouse_id = ws.warehouse_id
+    AND p.level = 'bin'
+    AND p.codes = ARRAY[TRIM(ws.location_floor), TRIM(ws.location_corridor), TRIM(ws.location_aisle),
+        TRIM(ws.location_shelf), TRIM(ws.location_bin)];
@@ -0,0 +1,11 @@
+-- Body styles go back to being submodels without years
+INSERT INTO arac.submodels (model_id, submodel_name, year_from, body_type)
+SELECT b.model_id, b.body_style, 0, b.body_style
+FROM arac.model_body_styles b
+WHERE NOT EXISTS (
+    SELECT 1 FROM arac.submodels s
+    WHERE s.model_id = b.model_id AND LOWER(s.body_type) = LOWER(b.body_style)
+)
+ON CONFLICT ON CONSTRAINT unique_submodel DO NOTHING;
+
+DROP TABLE IF EXISTS arac.model_body_styles;
@@ -0,0 +1,28 @@
+-- Body styles a model is built with, as listed in the vehicle catalog.
+-- They say nothing about years or engines, so they are not submodels.
+CREATE TABLE IF NOT EXISTS arac.model_body_styles (
+    body_style_id SERIAL PRIMARY KEY,
+    model_id INTEGER NOT NULL REFERENCES arac.models(model_id) ON DELETE CASCADE,
+    body_style VARCHAR(100) NOT NULL,
+    vehicle_type VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_model_body_styles_name
+    ON arac.model_body_styles(model_id, LOWER(body_style));
+
+-- Catalog seeding used to add every body style as a submodel with a
+-- year_from of 0. Move them here and drop the ones nothing refers to.
+INSERT INTO arac.model_body_styles (model_id, body_style)
+SELECT DISTINCT ON (model_id, LOWER(COALESCE(body_type, submodel_name)))
+    model_id, COALESCE(body_type, submodel_name)
+FROM arac.submodels
+WHERE year_from = 0
+ORDER BY model_id, LOWER(COALESCE(body_type, submodel_name)), submodel_id
+ON CONFLICT DO NOTHING;
+
+DELETE FROM arac.submodels s
+WHERE s.year_from = 0
+    AND NOT EXISTS (SELECT 1 FROM arac.items i WHERE i.submodel_id = s.submodel_id)
+    AND NOT EXISTS (SELECT 1 FROM arac.compatibility c WHERE c.submodel_id = s.submodel_id)
+    AND NOT EXISTS (SELECT 1 FROM arac.staged_submodels st WHERE st.submodel_id = s.submodel_id);
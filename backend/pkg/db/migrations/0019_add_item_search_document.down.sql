@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS arac.idx_items_oem_code_code_trgm;
+DROP INDEX IF EXISTS arac.idx_items_part_number_code_trgm;
+DROP INDEX IF EXISTS arac.idx_items_search_vector;
+DROP INDEX IF EXISTS arac.idx_items_search_document_trgm;
+
+DROP TRIGGER IF EXISTS trg_refresh_submodel_search_documents ON arac.submodels;
+DROP TRIGGER IF EXISTS trg_refresh_model_search_documents ON arac.models;
+DROP TRIGGER IF EXISTS trg_refresh_make_search_documents ON arac.makes;
+DROP TRIGGER IF EXISTS trg_refresh_category_search_documents ON arac.categories;
+DROP FUNCTION IF EXISTS arac.refresh_item_search_documents();
+
+DROP TRIGGER IF EXISTS trg_set_item_search_document ON arac.items;
+DROP FUNCTION IF EXISTS arac.set_item_search_document();
+
+ALTER TABLE arac.items DROP COLUMN IF EXISTS search_document;
+
+DROP FUNCTION IF EXISTS arac.item_search_document(TEXT, TEXT, TEXT, INTEGER, INTEGER, INTEGER, INTEGER);
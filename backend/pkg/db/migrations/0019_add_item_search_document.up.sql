@@ -0,0 +1,97 @@
+-- Item search looks in a stored document, so its trigram and full-text
+-- indexes can find the candidates before they are scored. The document is
+-- the item's part number, OEM code and description and the names of its
+-- category and vehicle, folded by search_fold.
+CREATE OR REPLACE FUNCTION arac.item_search_document(
+    item_part_number TEXT,
+    item_oem_code TEXT,
+    item_description TEXT,
+    item_category_id INTEGER,
+    item_make_id INTEGER,
+    item_model_id INTEGER,
+    item_submodel_id INTEGER
+)
+RETURNS TEXT AS $$
+    SELECT arac.search_fold(concat_ws(' ', item_part_number, item_oem_code, item_description,
+        (SELECT name FROM arac.categories WHERE category_id = item_category_id),
+        (SELECT make_name FROM arac.makes WHERE make_id = item_make_id),
+        (SELECT model_name FROM arac.models WHERE model_id = item_model_id),
+        (SELECT submodel_name FROM arac.submodels WHERE submodel_id = item_submodel_id)))
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE arac.items
+ADD COLUMN IF NOT EXISTS search_document TEXT NOT NULL DEFAULT '';
+
+-- The document is always built here, also when search_document itself is
+-- written, so setting it to anything rebuilds it
+CREATE OR REPLACE FUNCTION arac.set_item_search_document()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_document := arac.item_search_document(
+        NEW.part_number, NEW.oem_code, NEW.description,
+        NEW.category_id, NEW.make_id, NEW.model_id, NEW.submodel_id
+    );
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_set_item_search_document
+    BEFORE INSERT OR UPDATE OF part_number, oem_code, description, category_id,
+        make_id, model_id, submodel_id, search_document ON arac.items
+    FOR EACH ROW
+    EXECUTE FUNCTION arac.set_item_search_document();
+
+-- Renaming a category or vehicle rebuilds the documents of its items
+CREATE OR REPLACE FUNCTION arac.refresh_item_search_documents()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'categories' THEN
+        UPDATE arac.items SET search_document = '' WHERE category_id = NEW.category_id;
+    ELSIF TG_TABLE_NAME = 'makes' THEN
+        UPDATE arac.items SET search_document = '' WHERE make_id = NEW.make_id;
+    ELSIF TG_TABLE_NAME = 'models' THEN
+        UPDATE arac.items SET search_document = '' WHERE model_id = NEW.model_id;
+    ELSE
+        UPDATE arac.items SET search_document = '' WHERE submodel_id = NEW.submodel_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_refresh_category_search_documents
+    AFTER UPDATE OF name ON arac.categories
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION arac.refresh_item_search_documents();
+
+CREATE TRIGGER trg_refresh_make_search_documents
+    AFTER UPDATE OF make_name ON arac.makes
+    FOR EACH ROW
+    WHEN (OLD.make_name IS DISTINCT FROM NEW.make_name)
+    EXECUTE FUNCTION arac.refresh_item_search_documents();
+
+CREATE TRIGGER trg_refresh_model_search_documents
+    AFTER UPDATE OF model_name ON arac.models
+    FOR EACH ROW
+    WHEN (OLD.model_name IS DISTINCT FROM NEW.model_name)
+    EXECUTE FUNCTION arac.refresh_item_search_documents();
+
+CREATE TRIGGER trg_refresh_submodel_search_documents
+    AFTER UPDATE OF submodel_name ON arac.submodels
+    FOR EACH ROW
+    WHEN (OLD.submodel_name IS DISTINCT FROM NEW.submodel_name)
+    EXECUTE FUNCTION arac.refresh_item_search_documents();
+
+UPDATE arac.items SET search_document = '';
+
+-- Typos are found through word similarity (<%) and words through the
+-- full-text vector. Codes are matched by prefix or, from three characters,
+-- anywhere in them, which the trigram indexes on the codes serve as well.
+CREATE INDEX IF NOT EXISTS idx_items_search_document_trgm
+    ON arac.items USING GIN (search_document gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_items_search_vector
+    ON arac.items USING GIN (to_tsvector('simple', search_document));
+CREATE INDEX IF NOT EXISTS idx_items_part_number_code_trgm
+    ON arac.items USING GIN (arac.normalize_code(part_number) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_items_oem_code_code_trgm
+    ON arac.items USING GIN (arac.normalize_code(oem_code) gin_trgm_ops);
@@ -0,0 +1,14 @@
+CREATE OR REPLACE FUNCTION arac.stock_new_item()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO arac.warehouse_stock (
+        warehouse_id, item_id, quantity,
+        location_floor, location_corridor, location_aisle, location_shelf, location_bin
+    )
+    SELECT warehouse_id, NEW.item_id, NEW.current_stock,
+        NEW.location_floor, NEW.location_corridor, NEW.location_aisle, NEW.location_shelf, NEW.location_bin
+    FROM arac.warehouses
+    WHERE is_default;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+-- An item's own location is deprecated; where it is kept is set through
+-- its bin or stock in each warehouse. New items no longer copy it into the
+-- stock of the default warehouse.
+CREATE OR REPLACE FUNCTION arac.stock_new_item()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO arac.warehouse_stock (warehouse_id, item_id, quantity)
+    SELECT warehouse_id, NEW.item_id, NEW.current_stock
+    FROM arac.warehouses
+    WHERE is_default;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
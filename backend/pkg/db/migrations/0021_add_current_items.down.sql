@@ -0,0 +1,11 @@
+CREATE OR REPLACE VIEW arac.rolled_up_warehouse_stock AS
+SELECT warehouse_id, arac.current_item_id(item_id) AS item_id, SUM(quantity)::INTEGER AS stock
+FROM arac.warehouse_stock
+GROUP BY 1, 2;
+
+CREATE OR REPLACE VIEW arac.rolled_up_stock AS
+SELECT arac.current_item_id(item_id) AS item_id, SUM(current_stock)::INTEGER AS stock
+FROM arac.items
+GROUP BY 1;
+
+DROP VIEW IF EXISTS arac.current_items;
@@ -0,0 +1,31 @@
+-- The part that replaces each item today, resolved for every item in one
+-- pass over the supersession chains instead of once per item with
+-- arac.current_item_id. A chain that loops back stops before repeating an
+-- item, as the function does.
+CREATE OR REPLACE VIEW arac.current_items AS
+WITH RECURSIVE chain(item_id, current_item_id, path) AS (
+    SELECT item_id, item_id, ARRAY[item_id]
+    FROM arac.items
+    UNION ALL
+    SELECT chain.item_id, i.superseded_by, chain.path || i.superseded_by
+    FROM chain
+    JOIN arac.items i ON i.item_id = chain.current_item_id
+    WHERE i.superseded_by IS NOT NULL
+        AND i.superseded_on <= CURRENT_DATE
+        AND NOT i.superseded_by = ANY(chain.path)
+)
+SELECT DISTINCT ON (item_id) item_id, current_item_id
+FROM chain
+ORDER BY item_id, cardinality(path) DESC;
+
+CREATE OR REPLACE VIEW arac.rolled_up_stock AS
+SELECT c.current_item_id AS item_id, SUM(i.current_stock)::INTEGER AS stock
+FROM arac.items i
+JOIN arac.current_items c ON c.item_id = i.item_id
+GROUP BY 1;
+
+CREATE OR REPLACE VIEW arac.rolled_up_warehouse_stock AS
+SELECT ws.warehouse_id, c.current_item_id AS item_id, SUM(ws.quantity)::INTEGER AS stock
+FROM arac.warehouse_stock ws
+JOIN arac.current_items c ON c.item_id = ws.item_id
+GROUP BY 1, 2;
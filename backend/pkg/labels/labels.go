@@ -0,0 +1,114 @@
+package labels
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/boombuler/barcode/code128"
+)
+
+// Names of the label templates
+const (
+	// TemplateA4 is an A4 sheet of 3 by 8 labels of 70x37mm
+	TemplateA4 = "a4-3x8"
+	// TemplateRoll58 is a 58mm wide roll of 58x40mm labels
+	TemplateRoll58 = "roll-58mm"
+)
+
+var ErrNoLabels = errors.New("no labels to print")
+
+// Label is what is printed on one shelf label
+type Label struct {
+	Barcode     string
+	PartNumber  string
+	Description string
+	Price       string
+	Location    string
+	// Copies is how many of the label to print
+	Copies int
+}
+
+// Template describes where labels sit on a page. Sizes are in millimetres.
+type Template struct {
+	Name        string
+	PageWidth   float64
+	PageHeight  float64
+	Columns     int
+	Rows        int
+	LabelWidth  float64
+	LabelHeight float64
+	MarginLeft  float64
+	MarginTop   float64
+	// ColumnGap and RowGap are the space between neighbouring labels
+	ColumnGap float64
+	RowGap    float64
+}
+
+var templates = map[string]*Template{
+	TemplateA4: {
+		Name:        TemplateA4,
+		PageWidth:   210,
+		PageHeight:  297,
+		Columns:     3,
+		Rows:        8,
+		LabelWidth:  70,
+		LabelHeight: 37,
+		MarginTop:   0.5,
+	},
+	TemplateRoll58: {
+		Name:        TemplateRoll58,
+		PageWidth:   58,
+		PageHeight:  40,
+		Columns:     1,
+		Rows:        1,
+		LabelWidth:  58,
+		LabelHeight: 40,
+	},
+}
+
+// FindTemplate returns the template with a name, or nil if there is none
+func FindTemplate(name string) *Template {
+	return templates[name]
+}
+
+// perPage returns how many labels fit on a page
+func (t *Template) perPage() int {
+	return t.Columns * t.Rows
+}
+
+// position returns the top left corner of the label at index i of a page
+func (t *Template) position(i int) (x, y float64) {
+	column, row := i%t.Columns, i/t.Columns
+	x = t.MarginLeft + float64(column)*(t.LabelWidth+t.ColumnGap)
+	y = t.MarginTop + float64(row)*(t.LabelHeight+t.RowGap)
+	return x, y
+}
+
+// Layout of a label, in millimetres
+const (
+	padding        = 2.0
+	partNumberSize = 3.4
+	textSize       = 2.6
+	// barcodeTop is where the barcode starts, below the part number and
+	// description
+	barcodeTop = 10.5
+	// barcodeBottom is the space left under the barcode for its text,
+	// the price and the location
+	barcodeBottom = 11.0
+)
+
+// code128Bars encodes a barcode as Code128 and returns whether each
+// module, from left to right, is a bar
+func code128Bars(content string) ([]bool, error) {
+	code, err := code128.Encode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := code.Bounds()
+	bars := make([]bool, bounds.Dx())
+	for x := range bars {
+		bars[x] = code.At(bounds.Min.X+x, bounds.Min.Y) == color.Black
+	}
+	return bars, nil
+}
@@ -0,0 +1,119 @@
+package labels
+
+import (
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Font sizes on a PDF label, in points
+const (
+	partNumberFont  = 10
+	descriptionFont = 7
+	barcodeTextFont = 6
+	priceFont       = 10
+	locationFont    = 8
+)
+
+// maxModuleWidth is the widest a Code128 module is drawn, in millimetres
+const maxModuleWidth = 0.5
+
+// quietZone is the number of blank modules a scanner needs on each side of
+// a barcode
+const quietZone = 10
+
+// pdfLetters replaces the Turkish letters the built-in PDF fonts lack
+var pdfLetters = strings.NewReplacer("ğ", "g", "Ğ", "G", "ı", "i", "İ", "I", "ş", "s", "Ş", "S")
+
+// WritePDF renders labels onto the pages of a template, filling each page
+// row by row before starting the next
+func WritePDF(w io.Writer, template *Template, labels []Label) error {
+	if len(labels) == 0 {
+		return ErrNoLabels
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: template.PageWidth, Ht: template.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	text := func(s string) string {
+		return translate(pdfLetters.Replace(s))
+	}
+
+	printed := 0
+	for _, label := range labels {
+		bars, err := code128Bars(label.Barcode)
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < label.Copies; i++ {
+			if printed%template.perPage() == 0 {
+				pdf.AddPage()
+			}
+			x, y := template.position(printed % template.perPage())
+			drawPDFLabel(pdf, text, x, y, template, &label, bars)
+			printed++
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// drawPDFLabel draws one label with its top left corner at x, y
+func drawPDFLabel(pdf *fpdf.Fpdf, text func(string) string, x, y float64, template *Template, label *Label, bars []bool) {
+	width, height := template.LabelWidth, template.LabelHeight
+	textWidth := width - 2*padding
+
+	pdf.SetFont("Helvetica", "B", partNumberFont)
+	pdf.Text(x+padding, y+padding+3.5, fitPDFText(pdf, text(label.PartNumber), textWidth))
+
+	pdf.SetFont("Helvetica", "", descriptionFont)
+	pdf.Text(x+padding, y+padding+7, fitPDFText(pdf, text(label.Description), textWidth))
+
+	// The barcode is centred and as wide as the label allows with its
+	// quiet zones
+	module := width / float64(len(bars)+2*quietZone)
+	if module > maxModuleWidth {
+		module = maxModuleWidth
+	}
+	barsLeft := x + (width-module*float64(len(bars)))/2
+	barsTop := y + barcodeTop
+	barsHeight := height - barcodeTop - barcodeBottom
+	for i, bar := range bars {
+		if bar {
+			pdf.Rect(barsLeft+float64(i)*module, barsTop, module, barsHeight, "F")
+		}
+	}
+
+	pdf.SetFont("Helvetica", "", barcodeTextFont)
+	pdf.Text(x+(width-pdf.GetStringWidth(label.Barcode))/2, barsTop+barsHeight+2.5, label.Barcode)
+
+	baseline := y + height - padding - 0.5
+	pdf.SetFont("Helvetica", "B", priceFont)
+	price := text(label.Price)
+	pdf.Text(x+padding, baseline, price)
+	priceWidth := pdf.GetStringWidth(price)
+
+	pdf.SetFont("Helvetica", "", locationFont)
+	location := fitPDFText(pdf, text(label.Location), textWidth-priceWidth-padding)
+	pdf.Text(x+width-padding-pdf.GetStringWidth(location), baseline, location)
+}
+
+// fitPDFText shortens text in the current font until it fits width
+func fitPDFText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	if text == "" {
+		return ""
+	}
+	return text + "..."
+}
@@ -0,0 +1,98 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultDPI is the resolution of most thermal label printers
+const DefaultDPI = 203
+
+var ErrInvalidDPI = errors.New("printer DPI must be 203, 300 or 600")
+
+// Heights of the text on a ZPL label, in millimetres
+const (
+	zplPartNumberHeight = 3.4
+	zplTextHeight       = 2.6
+	zplPriceHeight      = 3.4
+	zplLocationHeight   = 2.8
+)
+
+// zplEscapes escapes the characters ZPL treats as commands in a field that
+// has ^FH, which makes _ start a hex escape
+var zplEscapes = strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+
+// WriteZPL writes labels as ZPL for a thermal printer of the given
+// resolution loaded with labels of the template's size. Each label is sent
+// once and printed Copies times.
+func WriteZPL(w io.Writer, template *Template, labels []Label, dpi int) error {
+	if len(labels) == 0 {
+		return ErrNoLabels
+	}
+	switch dpi {
+	case 203, 300, 600:
+	default:
+		return ErrInvalidDPI
+	}
+
+	dots := func(mm float64) int {
+		return int(math.Round(mm * float64(dpi) / 25.4))
+	}
+	width, height := dots(template.LabelWidth), dots(template.LabelHeight)
+	left := dots(padding)
+	textWidth := width - 2*left
+
+	var zpl strings.Builder
+	for _, label := range labels {
+		bars, err := code128Bars(label.Barcode)
+		if err != nil {
+			return err
+		}
+
+		// Modules are whole dots, as wide as the label allows with the
+		// quiet zones
+		module := width / (len(bars) + 2*quietZone)
+		if widest := dots(maxModuleWidth); module > widest {
+			module = widest
+		}
+		if module < 1 {
+			module = 1
+		}
+		barsTop := dots(barcodeTop)
+		barsHeight := height - barsTop - dots(barcodeBottom)
+
+		fmt.Fprintf(&zpl, "^XA\n^CI28\n^PW%d\n^LL%d\n", width, height)
+		zplField(&zpl, left, left, textWidth, dots(zplPartNumberHeight), "L", label.PartNumber)
+		zplField(&zpl, left, left+dots(4.5), textWidth, dots(zplTextHeight), "L", label.Description)
+		fmt.Fprintf(&zpl, "^FO%d,%d^BY%d^BCN,%d,Y,N,N,A^FH^FD%s^FS\n",
+			(width-module*len(bars))/2, barsTop, module, barsHeight, zplEscapes.Replace(label.Barcode))
+
+		priceHeight := dots(zplPriceHeight)
+		zplField(&zpl, left, height-left-priceHeight, textWidth, priceHeight, "L", label.Price)
+		locationHeight := dots(zplLocationHeight)
+		zplField(&zpl, left+textWidth/2, height-left-locationHeight, textWidth-textWidth/2, locationHeight, "R", label.Location)
+
+		fmt.Fprintf(&zpl, "^PQ%d\n^XZ\n", label.Copies)
+	}
+
+	_, err := io.WriteString(w, zpl.String())
+	return err
+}
+
+// zplField writes one line of text in the printer's scalable font, with its
+// top left corner at x, y. Text too long for width is cut short, as the
+// printer would otherwise print the overflow over it.
+func zplField(zpl *strings.Builder, x, y, width, height int, justify, text string) {
+	// Characters of the scalable font average a little over half their
+	// height in width
+	fits := width * 9 / (height * 5)
+	if runes := []rune(text); len(runes) > fits {
+		text = string(runes[:fits-3]) + "..."
+	}
+
+	fmt.Fprintf(zpl, "^FO%d,%d^A0N,%d,%d^FB%d,1,0,%s^FH^FD%s^FS\n",
+		x, y, height, height, width, justify, zplEscapes.Replace(text))
+}
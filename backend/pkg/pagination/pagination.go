@@ -0,0 +1,246 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+
+	// HeaderTotalCount carries the number of rows matching the filter
+	HeaderTotalCount = "X-Total-Count"
+	// HeaderNextCursor carries the cursor of the next page, if there is one
+	HeaderNextCursor = "X-Next-Cursor"
+)
+
+var (
+	ErrInvalidLimit     = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	ErrInvalidOffset    = errors.New("offset cannot be negative")
+	ErrInvalidSort      = errors.New("invalid sort field")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrCursorWithOffset = errors.New("cursor and offset cannot be used together")
+)
+
+// Field is a column a list can be sorted by. The column must never be NULL,
+// so wrap nullable columns in COALESCE.
+type Field struct {
+	Column string
+	// Type is the Postgres type cursor values are cast to
+	Type string
+}
+
+// Sorting is the whitelist of fields a list can be sorted by
+type Sorting struct {
+	Fields map[string]Field
+	// Default is the sort used when none is requested, e.g. "-date"
+	Default string
+	// IDColumn breaks ties so every row has a stable position
+	IDColumn string
+	// DefaultLimit is the page size when the client asks for no page at
+	// all. Lists that returned every row before they were paginated leave
+	// it at 0, so clients that do not page still get every row.
+	DefaultLimit int
+}
+
+// Page is a request for one page of a list. Repositories fill in Total and
+// NextCursor once the page has been read.
+type Page struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+	After  *Cursor
+
+	Total      int
+	NextCursor string
+}
+
+// Cursor points just past the last row of a page
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// Envelope wraps a page when the client asks for envelope=true
+type Envelope struct {
+	Data       interface{} `json:"data"`
+	Total      int         `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// FromRequest reads limit, offset, sort and cursor from the query string. A
+// Limit of 0 asks for every row.
+func FromRequest(c echo.Context, sorting *Sorting) (*Page, error) {
+	page := &Page{Limit: sorting.DefaultLimit}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxLimit {
+			return nil, ErrInvalidLimit
+		}
+		page.Limit = n
+	}
+
+	if offset := c.QueryParam("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidOffset
+		}
+		page.Offset = n
+	}
+
+	sort := c.QueryParam("sort")
+	if sort == "" {
+		sort = sorting.Default
+	}
+	page.Sort = strings.TrimPrefix(sort, "-")
+	page.Desc = strings.HasPrefix(sort, "-")
+	if _, ok := sorting.Fields[page.Sort]; !ok {
+		return nil, ErrInvalidSort
+	}
+
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		if page.Offset > 0 {
+			return nil, ErrCursorWithOffset
+		}
+		after, err := decodeCursor(cursor)
+		if err != nil || after.Sort != page.sortKey() {
+			return nil, ErrInvalidCursor
+		}
+		page.After = after
+	}
+
+	// Paging through a list without saying how far goes by the default
+	if page.Limit == 0 && (page.Offset > 0 || page.After != nil) {
+		page.Limit = DefaultLimit
+	}
+
+	return page, nil
+}
+
+// CountQuery turns a filtered select into one counting its rows
+func CountQuery(query string) string {
+	return "SELECT COUNT(*) FROM (" + query + ") counted"
+}
+
+// Apply adds the cursor condition, ORDER BY and LIMIT for a page to a query
+// that ends in a WHERE clause. A nil page sorts by the default and returns
+// every row.
+func (s *Sorting) Apply(query string, params []interface{}, page *Page) (string, []interface{}) {
+	if page == nil {
+		page = &Page{
+			Sort: strings.TrimPrefix(s.Default, "-"),
+			Desc: strings.HasPrefix(s.Default, "-"),
+		}
+	}
+
+	field := s.Fields[page.Sort]
+	direction, comparison := "ASC", ">"
+	if page.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		query += fmt.Sprintf(" AND (%s, %s) %s ($%d::text::%s, $%d)",
+			field.Column, s.IDColumn, comparison, len(params)+1, field.Type, len(params)+2)
+		params = append(params, page.After.Value, page.After.ID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, %s %s", field.Column, direction, s.IDColumn, direction)
+
+	if page.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(params)+1, len(params)+2)
+		params = append(params, page.Limit, page.Offset)
+	}
+
+	return query, params
+}
+
+// SetNextCursor records where the next page starts, given the number of
+// rows read and the sort value and ID of the last one. A short page is the
+// last page, so it gets no cursor.
+func (p *Page) SetNextCursor(rows int, value string, id int) {
+	if p.Limit == 0 || rows < p.Limit {
+		p.NextCursor = ""
+		return
+	}
+	p.NextCursor = encodeCursor(&Cursor{Sort: p.sortKey(), Value: value, ID: id})
+}
+
+// Respond writes a page of rows. The body stays a plain array with the
+// totals in headers, unless the client asks for envelope=true.
+func Respond(c echo.Context, page *Page, rows interface{}) error {
+	if envelope, _ := strconv.ParseBool(c.QueryParam("envelope")); envelope {
+		return c.JSON(http.StatusOK, &Envelope{
+			Data:       rows,
+			Total:      page.Total,
+			Limit:      page.Limit,
+			Offset:     page.Offset,
+			NextCursor: page.NextCursor,
+		})
+	}
+
+	header := c.Response().Header()
+	header.Set(HeaderTotalCount, strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		header.Set(HeaderNextCursor, page.NextCursor)
+	}
+	return c.JSON(http.StatusOK, rows)
+}
+
+// Time formats a timestamp sort value without losing precision
+func Time(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// Float formats a numeric sort value
+func Float(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// String formats a nullable text sort value, treating NULL as an empty string
+func String(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Helper functions
+func (p *Page) sortKey() string {
+	if p.Desc {
+		return "-" + p.Sort
+	}
+	return p.Sort
+}
+
+func encodeCursor(cursor *Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
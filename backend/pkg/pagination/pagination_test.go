@@ -0,0 +1,77 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFromRequestLimit(t *testing.T) {
+	unpaged := &Sorting{
+		Fields:   map[string]Field{"id": {Column: "id", Type: "integer"}},
+		Default:  "id",
+		IDColumn: "id",
+	}
+	paged := &Sorting{
+		Fields:       unpaged.Fields,
+		Default:      "id",
+		IDColumn:     "id",
+		DefaultLimit: DefaultLimit,
+	}
+	cursor := encodeCursor(&Cursor{Sort: "id", Value: "7", ID: 7})
+
+	tests := []struct {
+		name    string
+		sorting *Sorting
+		query   string
+		want    int
+		wantErr error
+	}{
+		{"no paging returns every row", unpaged, "", 0, nil},
+		{"sort alone returns every row", unpaged, "sort=-id", 0, nil},
+		{"limit", unpaged, "limit=20", 20, nil},
+		{"offset without limit", unpaged, "offset=100", DefaultLimit, nil},
+		{"cursor without limit", unpaged, "cursor=" + cursor, DefaultLimit, nil},
+		{"list with a default limit", paged, "", DefaultLimit, nil},
+		{"limit over the maximum", unpaged, "limit=501", 0, ErrInvalidLimit},
+		{"limit of zero", unpaged, "limit=0", 0, ErrInvalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/?"+tt.query, nil)
+			c := echo.New().NewContext(request, httptest.NewRecorder())
+
+			page, err := FromRequest(c, tt.sorting)
+			if err != tt.wantErr {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && page.Limit != tt.want {
+				t.Errorf("Limit = %d, want %d", page.Limit, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyWithoutLimit(t *testing.T) {
+	sorting := &Sorting{
+		Fields:   map[string]Field{"id": {Column: "id", Type: "integer"}},
+		Default:  "id",
+		IDColumn: "id",
+	}
+
+	query, params := sorting.Apply("SELECT id FROM t WHERE 1=1", nil, &Page{Sort: "id"})
+	if want := "SELECT id FROM t WHERE 1=1 ORDER BY id ASC, id ASC"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if len(params) != 0 {
+		t.Errorf("params = %v, want none", params)
+	}
+
+	page := &Page{Sort: "id"}
+	page.SetNextCursor(1000, "1000", 1000)
+	if page.NextCursor != "" {
+		t.Errorf("NextCursor = %q for a list of every row, want none", page.NextCursor)
+	}
+}
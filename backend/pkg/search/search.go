@@ -0,0 +1,49 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// turkishLetters drops the marks of Turkish letters once text is in lower
+// case, so "ı" and "i", or "ş" and "s", are the same when searching
+var turkishLetters = strings.NewReplacer(
+	"ı", "i", "ş", "s", "ğ", "g", "ü", "u", "ö", "o", "ç", "c",
+	"â", "a", "î", "i", "û", "u",
+)
+
+// Fold lower-cases text the Turkish way and drops the marks of Turkish
+// letters, so "BALATA", "Balata" and "balata" all become "balata". It
+// matches arac.search_fold in the database.
+func Fold(text string) string {
+	return turkishLetters.Replace(cases.Lower(language.Turkish).String(text))
+}
+
+// NormalizeCode reduces a part number or OEM code to its letters and digits
+// in upper case, so "1K0 615 301" and "1k0-615-301" are both "1K0615301".
+// It matches arac.normalize_code in the database.
+func NormalizeCode(code string) string {
+	var normalized strings.Builder
+	for _, r := range Fold(code) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			normalized.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return normalized.String()
+}
+
+// PrefixQuery turns search text into a to_tsquery expression that matches
+// documents containing every word, or a word starting with it. It returns
+// an empty string when the text has no words.
+func PrefixQuery(text string) string {
+	words := strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9')
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
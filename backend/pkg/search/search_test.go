@@ -0,0 +1,65 @@
+package search
+
+import "testing"
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"balata", "balata"},
+		{"Balata", "balata"},
+		{"BALATA", "balata"},
+		// Dotless and dotted capital I both fold to a plain i
+		{"FILTRE", "filtre"},
+		{"FİLTRE", "filtre"},
+		{"fıltre", "filtre"},
+		{"Işık", "isik"},
+		{"ŞANZIMAN YAĞI", "sanziman yagi"},
+		{"Ön Çamurluk Görüş", "on camurluk gorus"},
+	}
+
+	for _, tt := range tests {
+		if got := Fold(tt.text); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"1K0615301", "1K0615301"},
+		{"1K0 615 301", "1K0615301"},
+		{"1k0-615-301", "1K0615301"},
+		{" 1K0.615.301 ", "1K0615301"},
+		{"ıi-İI", "IIII"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCode(tt.code); got != tt.want {
+			t.Errorf("NormalizeCode(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"balata", "balata:*"},
+		{"ÖN BALATA", "on:* & balata:*"},
+		{"1K0-615", "1k0:* & 615:*"},
+		{" - ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := PrefixQuery(tt.text); got != tt.want {
+			t.Errorf("PrefixQuery(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}